@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+type Request struct {
+	ID   int
+	Body []byte
+}
+
+func newRequest(id int) *Request {
+	return &Request{ID: id}
+}
+
+func main() {
+	var reqs []*Request
+	for i := 0; i < 3; i++ {
+		reqs = append(reqs, newRequest(i))
+	}
+	big := make([]byte, 2<<20)
+	fmt.Println(len(reqs), len(big))
+}
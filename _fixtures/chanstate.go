@@ -0,0 +1,53 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+type item struct {
+	name string
+	n    int
+}
+
+func send(ch chan<- item, it item) {
+	ch <- it
+}
+
+func selectOn(out chan item, in chan int) {
+	select {
+	case out <- item{"select", 3}:
+	case <-in:
+	}
+}
+
+func main() {
+	buffered := make(chan int, 4)
+	buffered <- 1
+	buffered <- 2
+	buffered <- 3
+	<-buffered
+	buffered <- 4
+	buffered <- 5 // the buffer wraps around, the queue is 2, 3, 4, 5
+
+	closed := make(chan int, 2)
+	closed <- 1
+	close(closed)
+
+	unbuffered := make(chan item)
+	other := make(chan int)
+	go send(unbuffered, item{"first", 1})
+	time.Sleep(50 * time.Millisecond)
+	go send(unbuffered, item{"second", 2})
+	time.Sleep(50 * time.Millisecond)
+	go selectOn(unbuffered, other)
+	time.Sleep(50 * time.Millisecond)
+
+	var nilchan chan int
+
+	runtime.Breakpoint()
+	<-buffered
+	<-closed
+	<-unbuffered
+	_ = nilchan
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+type ctxKey string
+
+type myCtx struct {
+	context.Context
+	name string
+}
+
+func main() {
+	cctx, cancel := context.WithCancelCause(context.Background())
+	tctx, tcancel := context.WithTimeout(cctx, time.Hour)
+	defer tcancel()
+	vctx := context.WithValue(tctx, ctxKey("user"), "alice")
+	vctx = context.WithValue(vctx, 42, []int{1, 2, 3})
+	wctx := context.WithoutCancel(vctx)
+	my := myCtx{wctx, "custom"}
+	var nilctx context.Context
+	cancel(errors.New("shutting down"))
+	runtime.Breakpoint()
+	fmt.Println(my, nilctx)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var counter int
+
+func writer(done chan bool) {
+	counter = 1
+	<-done
+}
+
+func main() {
+	done := make(chan bool)
+	go writer(done)
+	time.Sleep(100 * time.Millisecond)
+	counter = 2
+	done <- true
+	fmt.Println(counter)
+}
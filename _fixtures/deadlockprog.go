@@ -0,0 +1,60 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// lockOrder acquires first and then second, after all goroutines
+// participating in the barrier have acquired their first mutex.
+func lockOrder(first, second *sync.Mutex, barrier *sync.WaitGroup) {
+	first.Lock()
+	barrier.Done()
+	barrier.Wait()
+	second.Lock()
+}
+
+// relay forwards one value from in to out.
+func relay(in <-chan int, out chan<- int) {
+	out <- <-in
+}
+
+func leak(ch chan int) {
+	<-ch
+}
+
+func nilchan() {
+	var ch chan int
+	ch <- 1
+}
+
+func waiter(ch chan int) {
+	<-ch
+}
+
+func startDeadlocks() {
+	a, b := new(sync.Mutex), new(sync.Mutex)
+	barrier := new(sync.WaitGroup)
+	barrier.Add(2)
+	go lockOrder(a, b, barrier)
+	go lockOrder(b, a, barrier)
+
+	c1, c2 := make(chan int), make(chan int)
+	go relay(c1, c2)
+	go relay(c2, c1)
+
+	go leak(make(chan int))
+	go nilchan()
+}
+
+func main() {
+	startDeadlocks()
+
+	ok := make(chan int)
+	go waiter(ok)
+
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	ok <- 1
+}
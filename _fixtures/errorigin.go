@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Name + " not found"
+}
+
+func lookup(name string) (int, error) {
+	if name == "missing" {
+		return 0, &NotFoundError{Name: name}
+	}
+	return len(name), nil
+}
+
+func load(names []string) error {
+	for _, name := range names {
+		if _, err := lookup(name); err != nil {
+			return fmt.Errorf("load: %w", err)
+		}
+	}
+	return nil
+}
+
+func main() {
+	_, err := lookup("missing")
+	runtime.Breakpoint()
+	fmt.Println(err)
+	err = load([]string{"a", "b", "missing"})
+	fmt.Println(err)
+}
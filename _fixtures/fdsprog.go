@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"time"
+)
+
+func acceptor(ln net.Listener) {
+	conn, err := ln.Accept()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func reader(conn net.Conn) {
+	buf := make([]byte, 1)
+	conn.Read(buf)
+}
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		panic(err)
+	}
+	go reader(server)
+	go acceptor(ln)
+
+	f, err := os.Open("/dev/null")
+	if err != nil {
+		panic(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	fmt.Println(client, f)
+}
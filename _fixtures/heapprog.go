@@ -0,0 +1,54 @@
+package main
+
+import "runtime"
+
+type small struct {
+	next *small
+	n    int
+}
+
+type medium struct {
+	p   *int
+	buf [100]int
+}
+
+type large struct {
+	p   *int
+	buf [10000]int
+}
+
+type node struct {
+	next *node
+	n    int
+}
+
+type holder struct {
+	name   string
+	target *node
+}
+
+var smalls []*small
+
+var list *node
+
+func main() {
+	for i := 0; i < 1000; i++ {
+		smalls = append(smalls, &small{n: i})
+	}
+	mediums := make([]*medium, 0, 100)
+	for i := 0; i < 100; i++ {
+		mediums = append(mediums, &medium{})
+	}
+	var larges [3]*large
+	for i := range larges {
+		larges[i] = &large{}
+	}
+	for i := 0; i < 3; i++ {
+		list = &node{next: list, n: i}
+	}
+	h := &holder{name: "holder", target: list.next.next}
+	runtime.Breakpoint()
+	runtime.KeepAlive(mediums)
+	runtime.KeepAlive(larges)
+	runtime.KeepAlive(h)
+}
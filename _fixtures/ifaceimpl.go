@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+)
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+}
+
+type Circle struct {
+	R float64
+}
+
+func (c *Circle) Area() float64      { return 3 * c.R * c.R }
+func (c *Circle) Perimeter() float64 { return 6 * c.R }
+
+type Square struct {
+	S float64
+}
+
+func (s Square) Area() float64      { return s.S * s.S }
+func (s Square) Perimeter() float64 { return 4 * s.S }
+func (s *Square) Scale(k float64)   { s.S *= k }
+
+// Named implements Shape through the methods promoted from Square.
+type Named struct {
+	Square
+	Name string
+}
+
+// Line is not a Shape, it has no Area method.
+type Line struct {
+	L float64
+}
+
+func (l Line) Perimeter() float64 { return l.L }
+
+// Grid has methods named like the methods of Shape, with different
+// signatures, and is never converted to Shape.
+type Grid struct {
+	W, H int
+}
+
+func (g Grid) Area() int      { return g.W * g.H }
+func (g Grid) Perimeter() int { return 2 * (g.W + g.H) }
+
+// grower can only be implemented by types of this package, bytes.Buffer
+// has a grow method but does not implement it.
+type grower interface {
+	grow(n int) int
+}
+
+type counter struct {
+	n int
+}
+
+func (c *counter) grow(n int) int { c.n += n; return c.n }
+
+func main() {
+	sq := Square{2}
+	sq.Scale(1)
+	shapes := []Shape{&Circle{1}, sq}
+	n := Named{Square{3}, "n"}
+	shapes = append(shapes, n)
+	l := Line{4}
+	g := Grid{2, 3}
+	var gr grower = &counter{}
+	var buf bytes.Buffer
+	buf.WriteString("buffer")
+	runtime.Breakpoint()
+	for _, s := range shapes {
+		fmt.Println(s.Area(), s.Perimeter())
+	}
+	fmt.Println(l.Perimeter(), n.Name, g.Area(), g.Perimeter(), gr.grow(1), buf.String())
+}
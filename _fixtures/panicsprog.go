@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func f() {
+	defer func() {
+		r := recover()
+		runtime.Breakpoint()
+		fmt.Println("recovered", r)
+	}()
+	defer func() {
+		panic(fmt.Errorf("second"))
+	}()
+	panic("first")
+}
+
+func main() {
+	f()
+}
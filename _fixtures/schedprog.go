@@ -0,0 +1,32 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+func worker(wg *sync.WaitGroup) {
+	wg.Done()
+}
+
+func locked(started chan<- struct{}) {
+	runtime.LockOSThread()
+	started <- struct{}{}
+	select {}
+}
+
+func main() {
+	runtime.GOMAXPROCS(1)
+
+	started := make(chan struct{})
+	go locked(started)
+	<-started
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go worker(&wg)
+	}
+	runtime.Breakpoint()
+	wg.Wait()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+func sleeper() {
+	time.Sleep(time.Hour)
+}
+
+func tick(ticker *time.Ticker) {
+	for range ticker.C {
+	}
+}
+
+func afterFuncCallback() {
+	fmt.Println("timer fired")
+}
+
+func main() {
+	go sleeper()
+	ticker := time.NewTicker(time.Minute)
+	go tick(ticker)
+	time.AfterFunc(2*time.Hour, afterFuncCallback)
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	ticker.Stop()
+}
@@ -19,10 +19,13 @@ import (
 
 	"github.com/go-delve/delve/cmd/dlv/cmds/helphelpers"
 	"github.com/go-delve/delve/pkg/config"
+	"github.com/go-delve/delve/pkg/elfwriter"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/pprof"
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/native"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/pkg/version"
 	"github.com/go-delve/delve/service"
@@ -104,6 +107,17 @@ var (
 	attachWaitFor         string
 	attachWaitForInterval float64
 	attachWaitForDuration float64
+
+	samplePid        int
+	sampleDuration   time.Duration
+	sampleRate       string
+	sampleOutput     string
+	sampleStackDepth int
+
+	gcoreOutput string
+
+	coreSysroot string
+	coreReport  string
 )
 
 const dlvCommandLongDesc = `Delve is a source level debugger for Go programs.
@@ -381,7 +395,22 @@ The core command will open the specified core file and the associated
 executable and let you examine the state of the process when the
 core dump was taken.
 
-Currently supports linux/amd64 and linux/arm64 core files, windows/amd64 minidumps and core files generated by Delve's 'dump' command.`,
+Currently supports linux/amd64 and linux/arm64 core files, windows/amd64 minidumps and core files generated by Delve's 'dump' command.
+Core files compressed with gzip are decompressed to a temporary file.
+
+The shared libraries used by the process are loaded from the paths they had
+when the core dump was taken, prefixed with the directory specified by
+--sysroot. If a library is not found there it is looked up, by file name, in
+the directories listed by the solib-search-path configuration option. When
+the core file records the build ID of a library, libraries with a different
+build ID are not loaded and a warning is printed.
+
+If --report=json is specified, instead of starting a debug session, a
+summary of the crash is written to standard output as a JSON object. The
+summary contains the panic value or fatal error message, the signal that
+terminated the process, the crashing goroutine and its stacktrace, all
+goroutines grouped by user location, the Go version and the list of
+packages of the executable.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
 				return errors.New("you must provide a core file and an executable")
@@ -400,8 +429,94 @@ Currently supports linux/amd64 and linux/arm64 core files, windows/amd64 minidum
 	core := false
 	coreCommand.Flags().BoolVarP(&core, "core", "c", false, "")
 	coreCommand.Flags().MarkHidden("core")
+	coreCommand.Flags().StringVar(&coreSysroot, "sysroot", "", "Directory containing copies of the shared libraries used by the process, at the same paths they had when the core dump was taken.")
+	must(coreCommand.MarkFlagDirname("sysroot"))
+	coreCommand.Flags().StringVar(&coreReport, "report", "", "Print a crash report in the specified format (only 'json' is supported) and exit.")
+	must(coreCommand.RegisterFlagCompletionFunc("report", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	}))
 	rootCommand.AddCommand(coreCommand)
 
+	// 'sample' subcommand.
+	sampleCommand := &cobra.Command{
+		Use:   "sample",
+		Short: "Collect a goroutine profile by periodically stopping a running process.",
+		Long: `Collect a goroutine profile by periodically stopping a running process.
+
+The sample command attaches to the process specified by --pid and, for the
+duration specified by --duration, repeatedly lets it run for an interval
+determined by --rate and then stops it to collect the stacktraces of all of
+its goroutines. Goroutines that are not running on a thread (i.e. are waiting
+on a channel, a lock, a syscall, etc) are also sampled, so the resulting
+profile describes where time was spent in wall-clock terms, including
+off-CPU time.
+
+The profile is written in the format used by 'go tool pprof'. Each sample is
+labeled with the pprof labels of its goroutine and with a 'goroutine.state'
+label describing whether the goroutine was running or, if not, why it was
+waiting.
+
+The process does not need to import net/http/pprof, however it will be
+slowed down while it is being sampled: every sample stops the process for
+the time needed to read the stacktraces of all goroutines.
+
+After sampling is finished Delve detaches from the process and lets it run.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Exit(sampleCmd(cmd, args, conf))
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+	sampleCommand.Flags().IntVarP(&samplePid, "pid", "p", 0, "Pid to attach to.")
+	must(sampleCommand.RegisterFlagCompletionFunc("pid", cobra.NoFileCompletions))
+	sampleCommand.Flags().DurationVarP(&sampleDuration, "duration", "d", 30*time.Second, "Total duration of the sampling.")
+	must(sampleCommand.RegisterFlagCompletionFunc("duration", cobra.NoFileCompletions))
+	sampleCommand.Flags().StringVarP(&sampleRate, "rate", "", "100hz", "Sampling rate, in samples per second (for example '100hz').")
+	must(sampleCommand.RegisterFlagCompletionFunc("rate", cobra.NoFileCompletions))
+	sampleCommand.Flags().IntVarP(&sampleStackDepth, "stack", "s", 64, "Maximum depth of the collected stacktraces.")
+	must(sampleCommand.RegisterFlagCompletionFunc("stack", cobra.NoFileCompletions))
+	sampleCommand.Flags().StringVarP(&sampleOutput, "output", "o", "profile.pb.gz", "Output path for the profile.")
+	must(sampleCommand.MarkFlagFilename("output"))
+	rootCommand.AddCommand(sampleCommand)
+
+	// 'gcore' subcommand.
+	gcoreCommand := &cobra.Command{
+		Use:   "gcore <pid>",
+		Short: "Write a core dump of a running process.",
+		Long: `Write a core dump of a running process.
+
+The gcore command attaches to the specified process, writes a core dump of
+it in the same format used by the 'dump' command and detaches, letting the
+process continue running. The resulting file can be examined with 'dlv core'.
+If the name of the output file ends in '.gz' the core dump is compressed with
+gzip.
+
+On linux/amd64 the process is only stopped for the time it takes to fork it,
+the core dump is then written from the copy, which shares the memory of the
+process through copy-on-write and is never resumed. The copy is killed once
+the core dump is written, it is not a child of the process, which does not
+receive a SIGCHLD signal for it.
+
+On other platforms the contents of the memory of the process are copied to
+a temporary file before anything is written to the output file and Delve
+detaches as soon as the copy is complete, this needs as much free space in
+the temporary directory as the size of the core dump. The process is also
+stopped while Delve reads the debug information of its executable, which
+happens before the copy.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must provide a PID")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Exit(gcoreCmd(cmd, args, conf))
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+	}
+	gcoreCommand.Flags().StringVarP(&gcoreOutput, "output", "o", "", "Output path for the core dump (default core.<pid>).")
+	must(gcoreCommand.MarkFlagFilename("output"))
+	rootCommand.AddCommand(gcoreCommand)
+
 	// 'version' subcommand.
 	var versionVerbose = false
 	versionCommand := &cobra.Command{
@@ -893,6 +1008,309 @@ func traceCmd(cmd *cobra.Command, args []string, conf *config.Config) int {
 	return status
 }
 
+func sampleCmd(cmd *cobra.Command, args []string, conf *config.Config) int {
+	if err := logflags.Setup(logFlag, logOutput, logDest); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer logflags.Close()
+	if loadConfErr != nil {
+		logflags.DebuggerLogger().Errorf("%v", loadConfErr)
+	}
+
+	if samplePid <= 0 {
+		fmt.Fprintln(os.Stderr, "you must specify a pid with --pid")
+		return 1
+	}
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "too many arguments")
+		return 1
+	}
+	interval, err := parseSampleRate(sampleRate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if sampleDuration <= 0 {
+		fmt.Fprintln(os.Stderr, "duration must be positive")
+		return 1
+	}
+
+	fh, err := os.Create(sampleOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer fh.Close()
+
+	// Make a local in-memory connection that client and server use to communicate
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+
+	server := rpccommon.NewServer(&service.Config{
+		Listener:   listener,
+		APIVersion: 2,
+		Debugger: debugger.Config{
+			AttachPid:            samplePid,
+			WorkingDir:           ".",
+			Backend:              backend,
+			CheckGoVersion:       checkGoVersion,
+			DebugInfoDirectories: conf.DebugInfoDirectories,
+		},
+	})
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	client := rpc2.NewClientFromConn(clientConn)
+	defer client.Detach(false)
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT)
+	defer signal.Stop(ch)
+
+	prof := pprof.New(pprof.ValueType{Type: "wall", Unit: "nanoseconds"}, interval.Nanoseconds(), pprof.ValueType{Type: "samples", Unit: "count"}, pprof.ValueType{Type: "wall", Unit: "nanoseconds"})
+	prof.Time = time.Now()
+
+	var running time.Duration
+	nsamples := 0
+
+sampleLoop:
+	for running < sampleDuration {
+		resumed := time.Now()
+		stateCh := client.Continue()
+		interrupted := false
+		select {
+		case <-time.After(interval):
+		case <-ch:
+			interrupted = true
+		}
+		var state *api.DebuggerState
+		for state == nil {
+			// A Halt request sent before the target has actually resumed could
+			// get lost, keep retrying until the target stops.
+			if _, err := client.Halt(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				break sampleLoop
+			}
+			select {
+			case state = <-stateCh:
+			case <-time.After(interval):
+			}
+		}
+		elapsed := time.Since(resumed)
+		running += elapsed
+		if state.Exited {
+			fmt.Fprintf(os.Stderr, "Process %d has exited with status %d\n", samplePid, state.ExitStatus)
+			break
+		}
+		if state.Err != nil {
+			fmt.Fprintln(os.Stderr, state.Err)
+			break
+		}
+
+		gs, err := client.ListGoroutineStacks(sampleStackDepth, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			break
+		}
+		for _, g := range gs {
+			if len(g.Stack) == 0 {
+				continue
+			}
+			prof.Add(api.PprofFrames(g.Stack), []int64{1, elapsed.Nanoseconds()}, g.PprofLabels())
+		}
+		nsamples++
+
+		if interrupted {
+			break
+		}
+	}
+
+	prof.Duration = time.Since(prof.Time)
+	if err := prof.Write(fh); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d samples written to %s\n", nsamples, sampleOutput)
+	return 0
+}
+
+func gcoreCmd(cmd *cobra.Command, args []string, conf *config.Config) int {
+	if err := logflags.Setup(logFlag, logOutput, logDest); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer logflags.Close()
+	if loadConfErr != nil {
+		logflags.DebuggerLogger().Errorf("%v", loadConfErr)
+	}
+
+	pid, err := strconv.Atoi(args[0])
+	if err != nil || pid <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid pid: %s\n", args[0])
+		return 1
+	}
+	if gcoreOutput == "" {
+		gcoreOutput = fmt.Sprintf("core.%d", pid)
+	}
+
+	fh, err := os.Create(gcoreOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var out elfwriter.WriteCloserSeeker = fh
+	if strings.HasSuffix(gcoreOutput, ".gz") {
+		out, err = elfwriter.NewGzipWriter(fh)
+		if err != nil {
+			fh.Close()
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	// When possible the core dump is written from a copy of the process, so
+	// that the process is only stopped for the time it takes to make the
+	// copy, otherwise the memory of the process is copied by Dump and Delve
+	// detaches once that's done.
+	snapshot := native.SnapshotSupported && (backend == "default" || backend == "native")
+
+	var (
+		start   = time.Now()
+		stopped time.Duration
+	)
+	cfg := &debugger.Config{
+		AttachPid:            pid,
+		WorkingDir:           ".",
+		Backend:              backend,
+		CheckGoVersion:       checkGoVersion,
+		DebugInfoDirectories: conf.DebugInfoDirectories,
+	}
+	if snapshot {
+		cfg.AttachSnapshot = func() { stopped = time.Since(start) }
+	}
+	d, err := debugger.New(cfg, nil)
+	if err != nil {
+		out.Close()
+		os.Remove(gcoreOutput)
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	grp, unlock := d.LockTargetGroup()
+	defer unlock()
+
+	var (
+		detached  bool
+		detachErr error
+	)
+	detach := func() {
+		if detached {
+			return
+		}
+		detached = true
+		if !snapshot {
+			stopped = time.Since(start)
+		}
+		// Detaching from a snapshot kills it.
+		detachErr = grp.Detach(false)
+	}
+
+	if !grp.CanDump {
+		detach()
+		out.Close()
+		os.Remove(gcoreOutput)
+		fmt.Fprintln(os.Stderr, debugger.ErrCoreDumpNotSupported)
+		return 1
+	}
+
+	state := &proc.DumpState{Dumping: true}
+	opts := proc.DumpOptions{}
+	if !snapshot {
+		state.SnapshotDone = detach
+		opts.Flags = proc.DumpSnapshot
+	}
+	grp.Selected.Dump(out, opts, state)
+	detach()
+
+	if detachErr != nil {
+		fmt.Fprintf(os.Stderr, "could not detach from process %d: %v\n", pid, detachErr)
+	}
+	if state.Err != nil {
+		os.Remove(gcoreOutput)
+		fmt.Fprintln(os.Stderr, state.Err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Core dump written to %s, process %d was stopped for %v\n", gcoreOutput, pid, stopped.Round(time.Millisecond))
+	if detachErr != nil {
+		return 1
+	}
+	return 0
+}
+
+// coreReportStackDepth is the depth of the stacktrace of the crashing
+// goroutine in crash reports.
+const coreReportStackDepth = 50
+
+func coreReportCmd(args []string, conf *config.Config) int {
+	if coreReport != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported report format %q\n", coreReport)
+		return 1
+	}
+	if err := logflags.Setup(logFlag, logOutput, logDest); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer logflags.Close()
+	if loadConfErr != nil {
+		logflags.DebuggerLogger().Errorf("%v", loadConfErr)
+	}
+
+	d, err := debugger.New(&debugger.Config{
+		WorkingDir:           ".",
+		Backend:              backend,
+		CoreFile:             args[1],
+		Sysroot:              coreSysroot,
+		SolibSearchPath:      conf.SolibSearchPath,
+		CheckGoVersion:       checkGoVersion,
+		DebugInfoDirectories: conf.DebugInfoDirectories,
+	}, []string{args[0]})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer d.Detach(false)
+
+	r, err := d.CrashReport(coreReportStackDepth)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseSampleRate parses a sampling rate, expressed as a number of samples
+// per second optionally followed by 'hz', and returns the corresponding
+// sampling interval.
+func parseSampleRate(rate string) (time.Duration, error) {
+	n, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(rate), "hz"), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid sampling rate %q", rate)
+	}
+	interval := time.Duration(float64(time.Second) / n)
+	if interval < time.Millisecond {
+		return 0, fmt.Errorf("sampling rate %q is too high", rate)
+	}
+	return interval, nil
+}
+
 func isBreakpointExistsErr(err error) bool {
 	return strings.Contains(err.Error(), "Breakpoint exists")
 }
@@ -949,6 +1367,9 @@ func attachCmd(_ *cobra.Command, args []string) {
 }
 
 func coreCmd(_ *cobra.Command, args []string) {
+	if coreReport != "" {
+		os.Exit(coreReportCmd(args, conf))
+	}
 	os.Exit(execute(0, []string{args[0]}, conf, args[1], debugger.ExecutingOther, args, buildFlags))
 }
 
@@ -1132,6 +1553,8 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 				WorkingDir:            workingDir,
 				Backend:               backend,
 				CoreFile:              coreFile,
+				Sysroot:               coreSysroot,
+				SolibSearchPath:       conf.SolibSearchPath,
 				Foreground:            headless && tty == "",
 				Packages:              dlvArgs,
 				BuildFlags:            buildFlags,
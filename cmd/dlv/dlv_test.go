@@ -3,6 +3,7 @@ package main_test
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -21,8 +22,11 @@ import (
 	"time"
 
 	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/core"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/pkg/terminal"
+	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/dap"
 	"github.com/go-delve/delve/service/dap/daptest"
 	"github.com/go-delve/delve/service/rpc2"
@@ -1130,6 +1134,182 @@ func TestTracePidNoKill(t *testing.T) {
 	}
 }
 
+func TestSample(t *testing.T) {
+	if runtime.GOOS == "linux" {
+		bs, _ := os.ReadFile("/proc/sys/kernel/yama/ptrace_scope")
+		if bs == nil || strings.TrimSpace(string(bs)) != "0" {
+			t.Logf("can not run TestSample: %v\n", bs)
+			return
+		}
+	}
+
+	dlvbin := protest.GetDlvBinary(t)
+
+	fix := protest.BuildFixture(t, "loopprog", 0)
+	targetCmd := exec.Command(fix.Path)
+	assertNoError(targetCmd.Start(), t, "execute loopprog")
+	defer targetCmd.Process.Kill()
+
+	targetCmdDone := make(chan struct{})
+	go func() {
+		targetCmd.Process.Wait()
+		close(targetCmdDone)
+	}()
+
+	outfile := filepath.Join(t.TempDir(), "out.pb.gz")
+	cmd := exec.Command(dlvbin, "sample", "-p", strconv.Itoa(targetCmd.Process.Pid), "--duration", "500ms", "--rate", "20hz", "-o", outfile)
+	output, err := cmd.CombinedOutput()
+	t.Logf("output %q", output)
+	assertNoError(err, t, "dlv sample")
+
+	if !bytes.Contains(output, []byte("samples written to")) {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	buf := slurpFile(t, outfile)
+	if len(buf) < 2 || buf[0] != 0x1f || buf[1] != 0x8b {
+		t.Fatalf("output is not a gzip file: %x", buf)
+	}
+
+	select {
+	case <-targetCmdDone:
+		t.Fatalf("expected process running after detach")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestGcore(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("not supported")
+	}
+	bs, _ := os.ReadFile("/proc/sys/kernel/yama/ptrace_scope")
+	if bs == nil || strings.TrimSpace(string(bs)) != "0" {
+		t.Logf("can not run TestGcore: %v\n", bs)
+		return
+	}
+
+	dlvbin := protest.GetDlvBinary(t)
+
+	fix := protest.BuildFixture(t, "loopprog", 0)
+	targetCmd := exec.Command(fix.Path)
+	assertNoError(targetCmd.Start(), t, "execute loopprog")
+	defer targetCmd.Process.Kill()
+
+	targetCmdDone := make(chan struct{})
+	go func() {
+		targetCmd.Process.Wait()
+		close(targetCmdDone)
+	}()
+
+	outfile := filepath.Join(t.TempDir(), "loopprog.core")
+	cmd := exec.Command(dlvbin, "gcore", strconv.Itoa(targetCmd.Process.Pid), "-o", outfile)
+	output, err := cmd.CombinedOutput()
+	t.Logf("output %q", output)
+	assertNoError(err, t, "dlv gcore")
+
+	if !bytes.Contains(output, []byte("Core dump written to")) {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	select {
+	case <-targetCmdDone:
+		t.Fatalf("expected process running after detach")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// The copy of the process made by gcore must not be left as a zombie
+	// child of the process.
+	stats, _ := filepath.Glob("/proc/[0-9]*/stat")
+	for _, stat := range stats {
+		buf, err := os.ReadFile(stat)
+		if err != nil {
+			continue
+		}
+		i := bytes.LastIndexByte(buf, ')')
+		if i < 0 {
+			continue
+		}
+		var state byte
+		var ppid int
+		if _, err := fmt.Sscanf(string(buf[i+1:]), " %c %d", &state, &ppid); err == nil && ppid == targetCmd.Process.Pid {
+			t.Errorf("process has a child after gcore: %s", buf)
+		}
+	}
+
+	grp, err := core.OpenCore(outfile, fix.Path, nil)
+	assertNoError(err, t, "OpenCore")
+	defer grp.Detach(false)
+	if pid := grp.Selected.Pid(); pid != targetCmd.Process.Pid {
+		t.Errorf("wrong pid in the core dump: %d (expected %d)", pid, targetCmd.Process.Pid)
+	}
+	if n := len(grp.Selected.ThreadList()); n < 2 {
+		t.Errorf("expected the threads of the process in the core dump, got %d threads", n)
+	}
+	gs, _, err := proc.GoroutinesInfo(grp.Selected, 0, 0)
+	assertNoError(err, t, "GoroutinesInfo")
+	found := false
+	for _, g := range gs {
+		frames, _ := proc.GoroutineStacktrace(grp.Selected, g, 10, 0)
+		for _, frame := range frames {
+			if frame.Current.Fn != nil && frame.Current.Fn.Name == "main.loop" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("main.loop not found in the stacktraces of the core dump")
+	}
+}
+
+func TestCoreReport(t *testing.T) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("not supported")
+	}
+	dlvbin := protest.GetDlvBinary(t)
+
+	fix := protest.BuildFixture(t, "panic", 0)
+	tempDir := t.TempDir()
+	exec.Command("bash", "-c", fmt.Sprintf("cd %s && ulimit -c unlimited && GOTRACEBACK=crash %s", tempDir, fix.Path)).Run()
+	cores, _ := filepath.Glob(filepath.Join(tempDir, "core*"))
+	if len(cores) != 1 {
+		t.Skipf("core file was not produced: %v", cores)
+	}
+
+	cmd := exec.Command(dlvbin, "core", fix.Path, cores[0], "--report=json")
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	assertNoError(err, t, "dlv core --report=json")
+
+	var r api.CrashReport
+	if err := json.Unmarshal(output, &r); err != nil {
+		t.Fatalf("could not unmarshal report: %v\n%s", err, output)
+	}
+	if r.Kind != "panic" || r.Message != "BOOM!" {
+		t.Errorf("wrong crash %q %q", r.Kind, r.Message)
+	}
+	if r.Signal == nil || r.Signal.Name != "SIGABRT" {
+		t.Errorf("wrong signal %#v", r.Signal)
+	}
+	if r.Goroutine == nil || r.Goroutine.UserCurrentLoc.Function == nil || r.Goroutine.UserCurrentLoc.Function.Name() != "main.main" {
+		t.Errorf("wrong goroutine %#v", r.Goroutine)
+	}
+	if len(r.Stacktrace) == 0 {
+		t.Errorf("no stacktrace")
+	}
+	found := false
+	for _, group := range r.GoroutineGroups {
+		if strings.HasSuffix(group.Location, "in main.main") && group.Count == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("main.main not found in goroutine groups %v", r.GoroutineGroups)
+	}
+	if r.GoVersion == "" || len(r.BuildInfo) == 0 {
+		t.Errorf("missing build information %q %v", r.GoVersion, r.BuildInfo)
+	}
+}
+
 func TestTraceBreakpointExists(t *testing.T) {
 	t.Parallel()
 	dlvbin := protest.GetDlvBinary(t)
@@ -49,6 +49,11 @@ type Config struct {
 	// MaxVariableRecurse is output evaluation depth of nested struct members, array and
 	// slice items and dereference pointers
 	MaxVariableRecurse *int `yaml:"max-variable-recurse,omitempty"`
+	// SizeofMaxDepth and SizeofMaxObjects are the default limits used by
+	// 'sizeof -deep' for the number of pointers followed and the number of
+	// heap objects visited.
+	SizeofMaxDepth   *int `yaml:"sizeof-max-depth,omitempty"`
+	SizeofMaxObjects *int `yaml:"sizeof-max-objects,omitempty"`
 	// DisassembleFlavor allow user to specify output syntax flavor of assembly, one of
 	// this list "intel"(default), "gnu", "go"
 	DisassembleFlavor *string `yaml:"disassemble-flavor,omitempty"`
@@ -96,6 +101,11 @@ type Config struct {
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
 
+	// SolibSearchPath is the list of directories where Delve looks for the
+	// shared libraries used by a core file, by file name, when they can not
+	// be found under the sysroot.
+	SolibSearchPath []string `yaml:"solib-search-path,omitempty"`
+
 	// Position controls how the current position in the program is displayed.
 	// There are three possible values:
 	//  - source: always show the current position in the program's source
@@ -303,6 +313,10 @@ substitute-path:
 # Output evaluation.
 # max-variable-recurse: 1
 
+# Maximum number of pointers followed and of heap objects visited by 'sizeof -deep', 0 means no limit.
+# sizeof-max-depth: 32
+# sizeof-max-objects: 100000
+
 # Uncomment the following line to make the whatis command also print the DWARF location expression of its argument.
 # show-location-expr: true
 
@@ -311,6 +325,10 @@ substitute-path:
 
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
+
+# List of directories to search, by file name, for the shared libraries used
+# by a core file when they are not found under the sysroot (see 'dlv core --sysroot').
+# solib-search-path: []
 `)
 	return err
 }
@@ -0,0 +1,121 @@
+package elfwriter
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// gzipHeaderSize is the size of the part at the start of the file that
+// Writer patches after writing the rest of the file (the ELF file header).
+const gzipHeaderSize = 64
+
+// GzipWriter is a WriteCloserSeeker that compresses the file written by a
+// Writer with gzip.
+//
+// Writer seeks back to patch the ELF file header once the rest of the file
+// has been written, which is not possible with a gzip stream. GzipWriter
+// keeps the file header in memory and writes it, when it is closed, as a
+// separate gzip member at the start of the output. This member is written
+// without compression so that its size does not depend on its contents
+// and the space for it can be reserved in advance. The rest of the file
+// follows as a second gzip member, gzip readers concatenate the two.
+type GzipWriter struct {
+	out       WriteCloserSeeker
+	hdr       [gzipHeaderSize]byte
+	hdrMember int64 // size of the gzip member containing hdr
+	gz        *gzip.Writer
+	pos, size int64
+}
+
+// NewGzipWriter returns a GzipWriter writing to out, which must be empty.
+func NewGzipWriter(out WriteCloserSeeker) (*GzipWriter, error) {
+	w := &GzipWriter{out: out}
+	n, err := w.writeHeaderMember()
+	if err != nil {
+		return nil, err
+	}
+	w.hdrMember = n
+	w.gz, _ = gzip.NewWriterLevel(out, gzip.BestSpeed)
+	return w, nil
+}
+
+// writeHeaderMember writes hdr as an uncompressed gzip member at the
+// current position of out and returns its size.
+func (w *GzipWriter) writeHeaderMember() (int64, error) {
+	start, err := w.out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	gz, _ := gzip.NewWriterLevel(w.out, gzip.NoCompression)
+	gz.Write(w.hdr[:])
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+	end, err := w.out.Seek(0, io.SeekCurrent)
+	return end - start, err
+}
+
+func (w *GzipWriter) Write(buf []byte) (int, error) {
+	written := 0
+	for len(buf) > 0 {
+		if w.pos < gzipHeaderSize {
+			n := copy(w.hdr[w.pos:], buf)
+			w.pos += int64(n)
+			w.size = max(w.size, w.pos)
+			written += n
+			buf = buf[n:]
+			continue
+		}
+		if w.pos != w.size {
+			return written, errors.New("compressed files can only be modified at the start or appended to")
+		}
+		n, err := w.gz.Write(buf)
+		w.pos += int64(n)
+		w.size = w.pos
+		written += n
+		if err != nil {
+			return written, err
+		}
+		buf = buf[n:]
+	}
+	return written, nil
+}
+
+func (w *GzipWriter) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += w.pos
+	case io.SeekEnd:
+		offset += w.size
+	default:
+		return w.pos, errors.New("invalid whence")
+	}
+	if offset < 0 || offset > w.size || (offset >= gzipHeaderSize && offset != w.size) {
+		return w.pos, fmt.Errorf("can not seek to %#x in a compressed file", offset)
+	}
+	w.pos = offset
+	return w.pos, nil
+}
+
+// Close finishes compressing the file, writes the file header and closes
+// the underlying file.
+func (w *GzipWriter) Close() error {
+	err := w.gz.Close()
+	if err == nil {
+		_, err = w.out.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		var n int64
+		n, err = w.writeHeaderMember()
+		if err == nil && n != w.hdrMember {
+			err = errors.New("internal error, size of the compressed file header changed")
+		}
+	}
+	if err2 := w.out.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,372 @@
+// Package pprof writes profiles in the format read by 'go tool pprof'
+// (profile.proto, see github.com/google/pprof/proto/profile.proto).
+//
+// Only the subset of the format needed to describe stack samples collected
+// from a target process is implemented.
+package pprof
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValueType describes the semantics and measurement units of a value.
+type ValueType struct {
+	Type string
+	Unit string
+}
+
+// Frame is a single frame of a stack trace, frames are listed starting
+// from the innermost one.
+type Frame struct {
+	PC       uint64
+	Function string
+	File     string
+	Line     int
+	// Inlined is true if this frame is an inlined call, the next frame is
+	// the caller it was inlined into and must have the same PC.
+	Inlined bool
+}
+
+// Profile accumulates samples and encodes them as a profile.proto message.
+// Samples with identical stacks and labels are merged by adding their
+// values together.
+type Profile struct {
+	SampleTypes []ValueType
+	PeriodType  ValueType
+	Period      int64
+	Time        time.Time
+	Duration    time.Duration
+	Comments    []string
+
+	strings   []string
+	stringIdx map[string]int64
+	functions []function
+	funcIdx   map[function]uint64
+	locations []location
+	locIdx    map[string]uint64
+	samples   []*sample
+	sampleIdx map[string]*sample
+}
+
+type function struct {
+	name, file int64
+}
+
+type line struct {
+	fnID uint64
+	line int64
+}
+
+type location struct {
+	addr  uint64
+	lines []line
+}
+
+type label struct {
+	key, str int64
+}
+
+type sample struct {
+	locs   []uint64
+	values []int64
+	labels []label
+}
+
+// New returns a new profile with the given sample types.
+func New(periodType ValueType, period int64, sampleTypes ...ValueType) *Profile {
+	p := &Profile{
+		SampleTypes: sampleTypes,
+		PeriodType:  periodType,
+		Period:      period,
+		stringIdx:   map[string]int64{},
+		funcIdx:     map[function]uint64{},
+		locIdx:      map[string]uint64{},
+		sampleIdx:   map[string]*sample{},
+	}
+	p.str("") // string_table[0] must always be the empty string
+	return p
+}
+
+// Add adds a sample with the given stack, values and labels to the
+// profile. The number of values must match the number of sample types.
+func (p *Profile) Add(frames []Frame, values []int64, labels map[string]string) {
+	var locs []uint64
+	for i := 0; i < len(frames); {
+		j := i
+		for j < len(frames)-1 && frames[j].Inlined && frames[j+1].PC == frames[j].PC {
+			j++
+		}
+		locs = append(locs, p.location(frames[i:j+1]))
+		i = j + 1
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lbls := make([]label, len(keys))
+	for i, k := range keys {
+		lbls[i] = label{p.str(k), p.str(labels[k])}
+	}
+
+	var buf strings.Builder
+	for _, loc := range locs {
+		fmt.Fprintf(&buf, "%d,", loc)
+	}
+	buf.WriteByte(';')
+	for _, lbl := range lbls {
+		fmt.Fprintf(&buf, "%d=%d,", lbl.key, lbl.str)
+	}
+	key := buf.String()
+
+	if s := p.sampleIdx[key]; s != nil {
+		for i := range values {
+			s.values[i] += values[i]
+		}
+		return
+	}
+	s := &sample{locs: locs, values: append([]int64(nil), values...), labels: lbls}
+	p.samples = append(p.samples, s)
+	p.sampleIdx[key] = s
+}
+
+// NumSamples returns the number of distinct samples in the profile.
+func (p *Profile) NumSamples() int {
+	return len(p.samples)
+}
+
+func (p *Profile) str(s string) int64 {
+	if i, ok := p.stringIdx[s]; ok {
+		return i
+	}
+	i := int64(len(p.strings))
+	p.strings = append(p.strings, s)
+	p.stringIdx[s] = i
+	return i
+}
+
+func (p *Profile) function(name, file string) uint64 {
+	fn := function{p.str(name), p.str(file)}
+	if id, ok := p.funcIdx[fn]; ok {
+		return id
+	}
+	p.functions = append(p.functions, fn)
+	id := uint64(len(p.functions))
+	p.funcIdx[fn] = id
+	return id
+}
+
+// location returns the ID of the location for frames, all frames must
+// have the same PC and all frames except the last one must be inlined.
+func (p *Profile) location(frames []Frame) uint64 {
+	var key strings.Builder
+	for _, frame := range frames {
+		fmt.Fprintf(&key, "%#x %s %s:%d\n", frame.PC, frame.Function, frame.File, frame.Line)
+	}
+	if id, ok := p.locIdx[key.String()]; ok {
+		return id
+	}
+	loc := location{addr: frames[0].PC}
+	for _, frame := range frames {
+		name := frame.Function
+		if name == "" {
+			name = "???"
+		}
+		loc.lines = append(loc.lines, line{fnID: p.function(name, frame.File), line: int64(frame.Line)})
+	}
+	p.locations = append(p.locations, loc)
+	id := uint64(len(p.locations))
+	p.locIdx[key.String()] = id
+	return id
+}
+
+// Field numbers of profile.proto
+const (
+	// Profile
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+	profileComment       = 13
+
+	// ValueType
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	// Sample
+	sampleLocationID = 1
+	sampleValue      = 2
+	sampleLabel      = 3
+
+	// Label
+	labelKey = 1
+	labelStr = 2
+
+	// Location
+	locationID      = 1
+	locationAddress = 3
+	locationLine    = 4
+
+	// Line
+	lineFunctionID = 1
+	lineLine       = 2
+
+	// Function
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+// Write writes the profile to w, gzip compressed.
+func (p *Profile) Write(w io.Writer) error {
+	var b protobuf
+
+	for _, typ := range p.SampleTypes {
+		b.message(profileSampleType, p.valueType(typ))
+	}
+	for _, s := range p.samples {
+		var sb protobuf
+		sb.packedUint64(sampleLocationID, s.locs)
+		sb.packedInt64(sampleValue, s.values)
+		for _, lbl := range s.labels {
+			var lb protobuf
+			lb.int64(labelKey, lbl.key)
+			lb.int64(labelStr, lbl.str)
+			sb.message(sampleLabel, lb.buf)
+		}
+		b.message(profileSample, sb.buf)
+	}
+	for i, loc := range p.locations {
+		var lb protobuf
+		lb.uint64(locationID, uint64(i+1))
+		lb.uint64(locationAddress, loc.addr)
+		for _, ln := range loc.lines {
+			var llb protobuf
+			llb.uint64(lineFunctionID, ln.fnID)
+			llb.int64(lineLine, ln.line)
+			lb.message(locationLine, llb.buf)
+		}
+		b.message(profileLocation, lb.buf)
+	}
+	for i, fn := range p.functions {
+		var fb protobuf
+		fb.uint64(functionID, uint64(i+1))
+		fb.int64(functionName, fn.name)
+		fb.int64(functionSystemName, fn.name)
+		fb.int64(functionFilename, fn.file)
+		b.message(profileFunction, fb.buf)
+	}
+
+	comments := make([]int64, len(p.Comments))
+	for i := range p.Comments {
+		comments[i] = p.str(p.Comments[i])
+	}
+	periodType := p.valueType(p.PeriodType)
+
+	// the string table must be emitted after everything else has been interned
+	for _, s := range p.strings {
+		b.string(profileStringTable, s)
+	}
+	if !p.Time.IsZero() {
+		b.int64(profileTimeNanos, p.Time.UnixNano())
+	}
+	if p.Duration != 0 {
+		b.int64(profileDurationNanos, p.Duration.Nanoseconds())
+	}
+	b.message(profilePeriodType, periodType)
+	b.int64(profilePeriod, p.Period)
+	b.packedInt64(profileComment, comments)
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.buf); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (p *Profile) valueType(typ ValueType) []byte {
+	var b protobuf
+	b.int64(valueTypeType, p.str(typ.Type))
+	b.int64(valueTypeUnit, p.str(typ.Unit))
+	return b.buf
+}
+
+// protobuf is a minimal protocol buffers encoder.
+type protobuf struct {
+	buf []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protobuf) tag(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *protobuf) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.tag(field, wireVarint)
+	b.varint(x)
+}
+
+func (b *protobuf) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *protobuf) string(field int, s string) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(s)))
+	b.buf = append(b.buf, s...)
+}
+
+func (b *protobuf) message(field int, msg []byte) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(msg)))
+	b.buf = append(b.buf, msg...)
+}
+
+func (b *protobuf) packedUint64(field int, xs []uint64) {
+	if len(xs) == 0 {
+		return
+	}
+	var pb protobuf
+	for _, x := range xs {
+		pb.varint(x)
+	}
+	b.message(field, pb.buf)
+}
+
+func (b *protobuf) packedInt64(field int, xs []int64) {
+	if len(xs) == 0 {
+		return
+	}
+	var pb protobuf
+	for _, x := range xs {
+		pb.varint(uint64(x))
+	}
+	b.message(field, pb.buf)
+}
@@ -0,0 +1,102 @@
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"testing"
+)
+
+// decode returns the top level fields of a protobuf message, grouped by
+// field number. Varint fields are returned as their value, length
+// delimited fields as their content.
+func decode(t *testing.T, buf []byte) map[int][]any {
+	r := map[int][]any{}
+	varint := func() uint64 {
+		var x uint64
+		for shift := 0; ; shift += 7 {
+			if len(buf) == 0 {
+				t.Fatal("truncated message")
+			}
+			b := buf[0]
+			buf = buf[1:]
+			x |= uint64(b&0x7f) << shift
+			if b < 0x80 {
+				return x
+			}
+		}
+	}
+	for len(buf) > 0 {
+		tag := varint()
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case wireVarint:
+			r[field] = append(r[field], varint())
+		case wireBytes:
+			n := varint()
+			r[field] = append(r[field], buf[:n])
+			buf = buf[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return r
+}
+
+func TestProfileWrite(t *testing.T) {
+	p := New(ValueType{"goroutine", "count"}, 1, ValueType{"goroutine", "count"})
+	stack := []Frame{
+		{PC: 0x1000, Function: "main.inlined", File: "main.go", Line: 3, Inlined: true},
+		{PC: 0x1000, Function: "main.f", File: "main.go", Line: 10},
+		{PC: 0x2000, Function: "main.main", File: "main.go", Line: 20},
+	}
+	p.Add(stack, []int64{1}, map[string]string{"k": "v"})
+	p.Add(stack, []int64{1}, map[string]string{"k": "v"})
+	p.Add(stack[1:], []int64{1}, nil)
+
+	if p.NumSamples() != 2 {
+		t.Fatalf("expected 2 samples got %d", p.NumSamples())
+	}
+
+	var out bytes.Buffer
+	if err := p.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := decode(t, buf)
+	if n := len(msg[profileLocation]); n != 3 {
+		t.Errorf("expected 3 locations got %d", n)
+	}
+	if n := len(msg[profileFunction]); n != 3 {
+		t.Errorf("expected 3 functions got %d", n)
+	}
+	var strs []string
+	for _, s := range msg[profileStringTable] {
+		strs = append(strs, string(s.([]byte)))
+	}
+	if strs[0] != "" {
+		t.Errorf("first entry of the string table is not empty: %q", strs[0])
+	}
+	for _, s := range []string{"goroutine", "count", "main.inlined", "main.f", "main.main", "main.go", "k", "v"} {
+		if !slices.Contains(strs, s) {
+			t.Errorf("string %q missing from string table %q", s, strs)
+		}
+	}
+
+	sample := decode(t, msg[profileSample][0].([]byte))
+	if v := sample[sampleValue][0].([]byte); !bytes.Equal(v, []byte{2}) {
+		t.Errorf("wrong value for merged sample %v", v)
+	}
+	if n := len(sample[sampleLabel]); n != 1 {
+		t.Errorf("expected 1 label got %d", n)
+	}
+}
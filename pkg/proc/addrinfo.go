@@ -0,0 +1,125 @@
+package proc
+
+// describeMaxStackDepth is the maximum number of frames searched by
+// DescribeAddress for a stack address.
+const describeMaxStackDepth = 1000
+
+// AddrInfo describes what an address points to, see DescribeAddress.
+type AddrInfo struct {
+	Addr uint64
+	// Mapping is the memory mapping containing Addr, nil if it is unknown.
+	Mapping *MemoryMapEntry
+	// Image is the path of the executable or shared library containing
+	// Addr, for text and data addresses.
+	Image string
+
+	// Function is the function containing Addr, FunctionOffset the offset
+	// of Addr from its entry point and File and Line the source position of
+	// the instruction at Addr.
+	Function       *Function
+	FunctionOffset uint64
+	File           string
+	Line           int
+
+	// Variable is the package variable containing Addr and VariableOffset
+	// the offset of Addr from its start.
+	Variable       string
+	VariableOffset uint64
+
+	// HeapSpanBase and HeapSpanLimit are the bounds of the heap span
+	// containing Addr, HeapElemSize is the size of the objects in the span.
+	HeapSpanBase, HeapSpanLimit uint64
+	HeapElemSize                uint64
+	// HeapObject is the address of the allocated heap object containing
+	// Addr, HeapObjectSize its size and HeapType its type.
+	HeapObject     uint64
+	HeapObjectSize uint64
+	HeapType       string
+
+	// Goroutine is the goroutine whose stack contains Addr, Frame is the
+	// index of the stack frame containing Addr or -1 if Addr isn't inside
+	// an active frame.
+	Goroutine *G
+	Frame     int
+	// FrameFunction is the function of the stack frame containing Addr.
+	FrameFunction *Function
+}
+
+// DescribeAddress returns a description of addr: the function and source
+// line for text addresses, the package variable for data addresses, the
+// heap span and object for heap addresses and the goroutine and stack
+// frame for stack addresses.
+func DescribeAddress(t *Target, addr uint64) (*AddrInfo, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	r := &AddrInfo{Addr: addr, Frame: -1}
+
+	if memmap, err := t.proc.MemoryMap(); err == nil {
+		for i := range memmap {
+			if addr >= memmap[i].Addr && addr < memmap[i].Addr+memmap[i].Size {
+				r.Mapping = &memmap[i]
+				break
+			}
+		}
+	}
+
+	if fn := bi.PCToFunc(addr); fn != nil {
+		r.Function = fn
+		r.FunctionOffset = addr - fn.Entry
+		r.File, r.Line, _ = bi.PCToLine(addr)
+		if image := bi.funcToImage(fn); image != nil {
+			r.Image = image.Path
+		}
+		return r, nil
+	}
+
+	if name, off := bi.addrSymbol(addr); name != "" {
+		r.Variable, r.VariableOffset = name, off
+		for _, pkgvar := range bi.packageVars {
+			if pkgvar.name == name && pkgvar.addr == addr-off {
+				r.Image = pkgvar.cu.image.Path
+				break
+			}
+		}
+		return r, nil
+	}
+
+	if h, err := t.loadHeap(false); err == nil {
+		if s, idx, ok := h.findSpan(addr); ok {
+			r.HeapSpanBase, r.HeapSpanLimit, r.HeapElemSize = s.base, s.limit, s.elemsize
+			if s.alloc[idx] {
+				h, _ = t.loadHeap(true)
+				r.HeapObject = s.objectAddr(idx)
+				r.HeapObjectSize = s.elemsize - s.header
+				r.HeapType = h.typeName(s, idx)
+			}
+			return r, nil
+		}
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return r, nil
+	}
+	for _, g := range gs {
+		if addr < g.stack.lo || addr >= g.stack.hi {
+			continue
+		}
+		r.Goroutine = g
+		frames, err := GoroutineStacktrace(t, g, describeMaxStackDepth, 0)
+		if err != nil {
+			break
+		}
+		for i := range frames {
+			if addr >= frames[i].Regs.SP() && addr < uint64(frames[i].Regs.CFA) {
+				r.Frame = i
+				r.FrameFunction = frames[i].Current.Fn
+				break
+			}
+		}
+		break
+	}
+	return r, nil
+}
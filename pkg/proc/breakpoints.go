@@ -12,6 +12,8 @@ import (
 	"go/token"
 	"reflect"
 	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/go-delve/delve/pkg/astutil"
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
@@ -34,9 +36,14 @@ const (
 	// example: calls to runtime.Breakpoint)
 	HardcodedBreakpoint = "hardcoded-breakpoint"
 
+	// DataRaceBreakpoint is the name given to the breakpoint triggered when
+	// the race detector reports a data race, in programs built with -race.
+	DataRaceBreakpoint = "data-race"
+
 	unrecoveredPanicID    = -1
 	fatalThrowID          = -2
 	hardcodedBreakpointID = -3
+	dataRaceID            = -4
 
 	NoLogicalID = -1000 // Logical breakpoint ID for breakpoints internal breakpoints.
 )
@@ -491,6 +498,21 @@ func evalBreakpointCondition(tgt *Target, thread Thread, cond ast.Expr) (bool, e
 	return constant.BoolVal(v.Value), nil
 }
 
+// uncountHit undoes the increment of the hit counts of lbp made by
+// checkCond when the callback of one of its breaklets decides that th
+// should not stop.
+func uncountHit(lbp *LogicalBreakpoint, th Thread) {
+	if lbp == nil {
+		return
+	}
+	if g, err := GetG(th); err == nil && lbp.HitCount[g.ID] > 0 {
+		lbp.HitCount[g.ID]--
+	}
+	if lbp.TotalHitCount > 0 {
+		lbp.TotalHitCount--
+	}
+}
+
 // NoBreakpointError is returned when trying to
 // clear a breakpoint that does not exist.
 type NoBreakpointError struct {
@@ -1119,6 +1141,219 @@ type SetBreakpoint struct {
 	Expr         func(*Target) []uint64
 	ExprString   string
 	PidAddrs     []PidAddr
+
+	// AllocType and AllocSize, if set, make this an allocation breakpoint:
+	// the breakpoint is set on runtime.mallocgc and only triggers when an
+	// object of type AllocType, or whose size satisfies AllocSize (for
+	// example ">1MB"), is allocated. See breakletCond.
+	// A physical breakpoint has at most one user breaklet, an allocation
+	// breakpoint can not be enabled if another user breakpoint is set on
+	// one of its locations.
+	AllocType string
+	AllocSize string
+
+	// ReturnsError, if set, is a regular expression, the breakpoint is set
+	// on the return instructions of the matching functions that return an
+	// error and only triggers when they return a non-nil error. If
+	// ErrorType is also set the error must have that dynamic type, and
+	// functions returning a value of type ErrorType are also included.
+	ReturnsError string
+	ErrorType    string
+
+	// AllocAddr, if set, makes this a breakpoint on the return instructions
+	// of runtime.mallocgc that only triggers when the object being returned
+	// is at AllocAddr. Executing backwards it stops where the object at
+	// AllocAddr was allocated.
+	AllocAddr uint64
+}
+
+// allocBreakpointFunction is the function allocation breakpoints are set
+// on.
+const allocBreakpointFunction = "runtime.mallocgc"
+
+// allocBreakpointLocations returns the addresses where allocation
+// breakpoints are set: runtime.mallocgc and its size specialized versions
+// (runtime.mallocgcSmallNoScanSC2, etc.), which have the same arguments
+// and are called directly by the compiler for small allocations.
+func allocBreakpointLocations(t *Target) ([]uint64, error) {
+	addrs, err := FindFunctionLocation(t, allocBreakpointFunction, 0)
+	if err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.Entry == 0 || !isSizeSpecializedMalloc(fn.Name) {
+			continue
+		}
+		pc, err := FirstPCAfterPrologue(t, fn, false)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, pc)
+	}
+	return addrs, nil
+}
+
+// allocAddrLocations returns the addresses where breakpoints with
+// AllocAddr set are set: the return instructions of runtime.mallocgc and
+// its size specialized versions.
+func allocAddrLocations(t *Target) ([]uint64, error) {
+	bi := t.BinInfo()
+	if !bi.regabi || len(bi.Arch.argumentRegs) == 0 {
+		return nil, fmt.Errorf("can not read the result of %s on %s without the register ABI", allocBreakpointFunction, bi.Arch.Name)
+	}
+	var addrs []uint64
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.Entry == 0 || (fn.Name != allocBreakpointFunction && !isSizeSpecializedMalloc(fn.Name)) {
+			continue
+		}
+		rets, err := returnInstructions(t, fn)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, rets...)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("could not find %s", allocBreakpointFunction)
+	}
+	return addrs, nil
+}
+
+// allocAddrCallback returns the callback of the breaklets of a breakpoint
+// with AllocAddr set, which only stops if runtime.mallocgc is returning
+// lbp.Set.AllocAddr.
+func allocAddrCallback(lbp *LogicalBreakpoint) func(Thread, *Target) (bool, error) {
+	return func(th Thread, t *Target) (bool, error) {
+		addr, err := mallocgcResult(t, th)
+		if err == nil && addr == lbp.Set.AllocAddr {
+			return true, nil
+		}
+		uncountHit(lbp, th)
+		if err != nil {
+			return false, fmt.Errorf("could not read the return value of %s: %v", allocBreakpointFunction, err)
+		}
+		return false, nil
+	}
+}
+
+// mallocgcResult returns the value being returned by runtime.mallocgc, th
+// must be stopped on one of its return instructions. The runtime is
+// optimized and the location of the result is usually not described by
+// DWARF at that point, instead it is read from the first result register
+// of the register ABI.
+func mallocgcResult(t *Target, th Thread) (uint64, error) {
+	regs, err := th.Registers()
+	if err != nil {
+		return 0, err
+	}
+	bi := t.BinInfo()
+	dregs := bi.Arch.RegistersToDwarfRegisters(0, regs)
+	return dregs.Uint64Val(uint64(bi.Arch.argumentRegs[0])), nil
+}
+
+func isSizeSpecializedMalloc(name string) bool {
+	if !strings.HasPrefix(name, allocBreakpointFunction) {
+		return false
+	}
+	trimmed := strings.TrimRightFunc(name, unicode.IsDigit)
+	return trimmed != name && strings.HasSuffix(trimmed, "SC")
+}
+
+// breakletCond returns the condition of the user breaklets of lbp on
+// target t. For allocation breakpoints this is the condition of lbp and
+// the condition on the arguments of runtime.mallocgc, which depends on the
+// target because it compares against the address of a runtime type.
+func (t *Target) breakletCond(lbp *LogicalBreakpoint) (ast.Expr, error) {
+	cond := lbp.cond
+	if lbp.Set.AllocSize != "" {
+		op, n, err := parseAllocSize(lbp.Set.AllocSize)
+		if err != nil {
+			return nil, err
+		}
+		cond = andCond(&ast.BinaryExpr{X: ast.NewIdent("size"), Op: op, Y: &ast.BasicLit{Kind: token.INT, Value: strconv.FormatUint(n, 10)}}, cond)
+	}
+	if lbp.Set.AllocType != "" {
+		typeAddr, err := allocRuntimeType(t, lbp.Set.AllocType)
+		if err != nil {
+			return nil, err
+		}
+		typ := &ast.CallExpr{Fun: ast.NewIdent("uintptr"), Args: []ast.Expr{ast.NewIdent("typ")}}
+		cond = andCond(astutil.Eql(typ, &ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%#x", typeAddr)}), cond)
+	}
+	return cond, nil
+}
+
+// andCond returns 'x && y', or x if y is nil.
+func andCond(x, y ast.Expr) ast.Expr {
+	if y == nil {
+		return x
+	}
+	return astutil.And(x, y)
+}
+
+// allocRuntimeType returns the address of the runtime type passed to
+// runtime.mallocgc when allocating an object of type typename. A pointer
+// type is taken to mean the type it points to, so that both T and *T can
+// be used to break on new(T) and &T{}.
+func allocRuntimeType(t *Target, typename string) (uint64, error) {
+	expr, err := parser.ParseExpr(typename)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse type %q: %v", typename, err)
+	}
+	if star, isstar := expr.(*ast.StarExpr); isstar {
+		expr = star.X
+	}
+	bi := t.BinInfo()
+	typ, err := bi.findTypeExpr(expr)
+	if err != nil {
+		return 0, fmt.Errorf("could not find type %s: %v", typename, err)
+	}
+	typeAddr, _, found, err := dwarfToRuntimeType(bi, t.Memory(), typ)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("could not find runtime type of %s", typename)
+	}
+	return typeAddr, nil
+}
+
+// parseAllocSize parses the size condition of an allocation breakpoint,
+// an optional comparison operator (">=" if omitted) followed by a size,
+// with an optional unit: B, KB, MB or GB (powers of 1024).
+func parseAllocSize(s string) (token.Token, uint64, error) {
+	s = strings.TrimSpace(s)
+	op := token.GEQ
+	for _, x := range []struct {
+		prefix string
+		op     token.Token
+	}{{">=", token.GEQ}, {"<=", token.LEQ}, {"==", token.EQL}, {"!=", token.NEQ}, {">", token.GTR}, {"<", token.LSS}} {
+		if strings.HasPrefix(s, x.prefix) {
+			op = x.op
+			s = strings.TrimSpace(s[len(x.prefix):])
+			break
+		}
+	}
+	num := strings.TrimRightFunc(s, unicode.IsLetter)
+	unit := strings.ToUpper(s[len(num):])
+	n, err := strconv.ParseUint(strings.TrimSpace(num), 0, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid allocation size %q", s)
+	}
+	switch unit {
+	case "", "B":
+	case "K", "KB", "KIB":
+		n <<= 10
+	case "M", "MB", "MIB":
+		n <<= 20
+	case "G", "GB", "GIB":
+		n <<= 30
+	default:
+		return 0, 0, fmt.Errorf("invalid allocation size unit %q", unit)
+	}
+	return op, n, nil
 }
 
 type PidAddr struct {
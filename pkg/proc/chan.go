@@ -0,0 +1,217 @@
+package proc
+
+import (
+	"errors"
+	"go/constant"
+	"go/parser"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// ChanState describes the buffer and the wait queues of a channel.
+type ChanState struct {
+	Addr   uint64 // address of the runtime.hchan structure, 0 for nil channels
+	Len    int64
+	Cap    int64
+	Closed bool
+	// Queue contains the buffered elements of the channel in the order in
+	// which they will be received.
+	Queue *Variable
+	// Recvq and Sendq are the goroutines waiting to receive from and send to
+	// the channel.
+	Recvq []ChanWaiter
+	Sendq []ChanWaiter
+}
+
+// ChanWaiter is a goroutine waiting on a channel.
+type ChanWaiter struct {
+	G *G
+	// Value is the value a sender is trying to send, nil for receivers.
+	Value *Variable
+	// Select is true if the goroutine is blocked in a select statement,
+	// SelectCases lists the other channels it is waiting on.
+	Select      bool
+	SelectCases []ChanSelectCase
+}
+
+// ChanSelectCase is a case of the select statement a goroutine is blocked
+// in.
+type ChanSelectCase struct {
+	Chan uint64 // address of the runtime.hchan structure
+	Send bool
+}
+
+// ChanState returns the state of the channel expr evaluates to, buffered
+// elements and values being sent are loaded using cfg.
+func (scope *EvalScope) ChanState(expr string, cfg LoadConfig) (*ChanState, error) {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	v, err := scope.evalAST(t)
+	if err != nil {
+		return nil, err
+	}
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	chanType, ok := v.RealType.(*godwarf.ChanType)
+	if !ok {
+		return nil, errors.New("expression is not a channel")
+	}
+
+	// +rtype -field hchan.qcount uint
+	// +rtype -field hchan.dataqsiz uint
+	// +rtype -field hchan.buf unsafe.Pointer
+	// +rtype -field hchan.closed uint32
+	// +rtype -field hchan.recvx uint
+	// +rtype -field hchan.recvq waitq
+	// +rtype -field hchan.sendq waitq
+	hchan := v.clone()
+	hchan.RealType = godwarf.ResolveTypedef(&(chanType.TypedefType))
+	hchan = hchan.maybeDereference()
+	if hchan.Unreadable != nil {
+		return nil, hchan.Unreadable
+	}
+	cs := &ChanState{Addr: hchan.Addr}
+	if hchan.Addr == 0 {
+		return cs, nil
+	}
+
+	readUintField := func(name string) (uint64, error) {
+		f, err := hchan.structMember(name)
+		if err != nil {
+			return 0, err
+		}
+		return readUintRaw(f.mem, f.Addr, f.RealType.Size())
+	}
+	var qcount, dataqsiz, buf, closed, recvx uint64
+	for _, field := range []struct {
+		name string
+		dst  *uint64
+	}{{"qcount", &qcount}, {"dataqsiz", &dataqsiz}, {"buf", &buf}, {"closed", &closed}, {"recvx", &recvx}} {
+		*field.dst, err = readUintField(field.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cs.Len, cs.Cap, cs.Closed = int64(qcount), int64(dataqsiz), closed != 0
+
+	elemType := chanType.ElemType
+	cs.Queue = newVariable("", 0, fakeArrayType(qcount, elemType), scope.BinInfo, hchan.mem)
+	cs.Queue.Flags |= VariableFakeAddress
+	cs.Queue.loaded = true
+	stride := uint64(alignAddr(elemType.Common().ByteSize, elemType.Align()))
+	for i := uint64(0); i < qcount && i < dataqsiz && i < uint64(cfg.MaxArrayValues); i++ {
+		idx := (recvx + i) % dataqsiz
+		e := newVariable("", buf+idx*stride, elemType, scope.BinInfo, hchan.mem)
+		e.loadValue(cfg)
+		cs.Queue.Children = append(cs.Queue.Children, *e)
+	}
+
+	for _, q := range []struct {
+		name    string
+		send    bool
+		waiters *[]ChanWaiter
+	}{{"recvq", false, &cs.Recvq}, {"sendq", true, &cs.Sendq}} {
+		err := walkChanWaitq(hchan, q.name, func(sg *Variable) (bool, error) {
+			w, err := chanWaiter(sg, elemType, q.send, cfg)
+			if err != nil {
+				return false, err
+			}
+			*q.waiters = append(*q.waiters, w)
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+// walkChanWaitq calls fn for each sudog in the wait queue qname ("recvq"
+// or "sendq") of channel v, until fn returns false.
+func walkChanWaitq(v *Variable, qname string, fn func(sg *Variable) (bool, error)) error {
+	qvar := structMemberMulti(v, qname, "first")
+	if qvar == nil {
+		return nil
+	}
+	for qvar = qvar.maybeDereference(); qvar.Addr != 0; {
+		cont, err := fn(qvar)
+		if err != nil || !cont {
+			return err
+		}
+		nextVar, err := qvar.structMember("next")
+		if err != nil {
+			return err
+		}
+		qvar = nextVar.maybeDereference()
+	}
+	return nil
+}
+
+// chanWaiter returns a description of the goroutine waiting on sudog sg.
+func chanWaiter(sg *Variable, elemType godwarf.Type, send bool, cfg LoadConfig) (ChanWaiter, error) {
+	// +rtype -field sudog.g *g
+	// +rtype -field sudog.isSelect bool
+	gvar, err := sg.structMember("g")
+	if err != nil {
+		return ChanWaiter{}, err
+	}
+	g, err := gvar.parseG()
+	if err != nil {
+		return ChanWaiter{}, err
+	}
+	w := ChanWaiter{G: g}
+
+	if send {
+		if elem, err := sudogPtrField(sg, "elem"); err == nil && elem != 0 {
+			w.Value = newVariable("", elem, elemType, sg.bi, sg.mem)
+			w.Value.loadValue(cfg)
+		}
+	}
+
+	if isSelect := sg.loadFieldNamed("isSelect"); isSelect == nil || isSelect.Value == nil || !constant.BoolVal(isSelect.Value) {
+		return w, nil
+	}
+	w.Select = true
+
+	// The other cases of the select statement are the other sudogs in the
+	// g.waiting list of the goroutine.
+	hchanType, err := sg.bi.findType("runtime.hchan")
+	if err != nil {
+		return w, nil
+	}
+	waiting, err := g.variable.structMember("waiting")
+	if err != nil {
+		return w, nil
+	}
+	for other, i := waiting.maybeDereference(), 0; other.Addr != 0 && other.Unreadable == nil && i < maxSelectCases; i++ {
+		if other.Addr != sg.Addr {
+			if c, err := sudogPtrField(other, "c"); err == nil && c != 0 {
+				w.SelectCases = append(w.SelectCases, ChanSelectCase{Chan: c, Send: sudogInWaitq(newVariable("", c, hchanType, sg.bi, sg.mem), "sendq", other.Addr)})
+			}
+		}
+		next, err := other.structMember("waitlink")
+		if err != nil {
+			break
+		}
+		other = next.maybeDereference()
+	}
+	return w, nil
+}
+
+// maxSelectCases is the maximum number of cases of a select statement,
+// see the selectgo function in the runtime.
+const maxSelectCases = 1 << 16
+
+// sudogInWaitq returns true if the sudog at address sgaddr is in the wait
+// queue qname of hchan.
+func sudogInWaitq(hchan *Variable, qname string, sgaddr uint64) bool {
+	found := false
+	walkChanWaitq(hchan, qname, func(sg *Variable) (bool, error) {
+		found = sg.Addr == sgaddr
+		return !found, nil
+	})
+	return found
+}
@@ -1,12 +1,17 @@
 package core
 
 import (
+	"bufio"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/elfwriter"
+	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/internal/ebpf"
 )
@@ -156,14 +161,19 @@ func (r *offsetReaderAt) ReadMemory(buf []byte, addr uint64) (n int, err error)
 
 // process represents a core file.
 type process struct {
-	mem     proc.MemoryReader
-	Threads map[int]*thread
-	pid     int
+	mem       proc.MemoryReader
+	memoryMap []proc.MemoryMapEntry
+	Threads   map[int]*thread
+	pid       int
 
 	entryPoint uint64
 
 	bi          *proc.BinaryInfo
 	breakpoints proc.BreakpointMap
+
+	tmpCorePath string // temporary file containing the decompressed core file
+
+	libraries map[string]*coreLibrary // shared libraries mapped in the core file
 }
 
 // thread represents a thread in the core file being debugged.
@@ -171,6 +181,7 @@ type thread struct {
 	osThread
 	p      *process
 	common proc.CommonThread
+	signal *proc.SignalInfo // signal received by the thread, if known
 }
 
 type osThread interface {
@@ -193,7 +204,7 @@ var (
 	ErrChangeRegisterCore = errors.New("can not change register values of core process")
 )
 
-type openFn func(string, string) (*process, proc.Thread, error)
+type openFn func(string, string, SharedLibraryPaths) (*process, proc.Thread, error)
 
 var openFns = []openFn{readLinuxOrPlatformIndependentCore, readAMD64Minidump}
 
@@ -204,23 +215,40 @@ var ErrUnrecognizedFormat = errors.New("unrecognized core format")
 // OpenCore will open the core file and return a *proc.TargetGroup.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
+// Core files compressed with gzip are decompressed to a temporary file.
 func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.TargetGroup, error) {
+	return OpenCoreWithLibraries(corePath, exePath, debugInfoDirs, SharedLibraryPaths{})
+}
+
+// OpenCoreWithLibraries is like OpenCore but looks up the shared libraries
+// mapped in the core file using libPaths.
+func OpenCoreWithLibraries(corePath, exePath string, debugInfoDirs []string, libPaths SharedLibraryPaths) (*proc.TargetGroup, error) {
+	tmpCorePath, err := decompressCore(corePath)
+	if err != nil {
+		return nil, err
+	}
+	if tmpCorePath != "" {
+		corePath = tmpCorePath
+	}
+
 	var p *process
 	var currentThread proc.Thread
-	var err error
 	for _, openFn := range openFns {
-		p, currentThread, err = openFn(corePath, exePath)
+		p, currentThread, err = openFn(corePath, exePath, libPaths)
 		if err != ErrUnrecognizedFormat {
 			break
 		}
 	}
+	if err == nil && currentThread == nil {
+		err = ErrNoThreads
+	}
 	if err != nil {
+		if tmpCorePath != "" {
+			os.Remove(tmpCorePath)
+		}
 		return nil, err
 	}
-
-	if currentThread == nil {
-		return nil, ErrNoThreads
-	}
+	p.tmpCorePath = tmpCorePath
 
 	grp, addTarget := proc.NewGroup(p, proc.NewTargetGroupConfig{
 		DebugInfoDirs:       debugInfoDirs,
@@ -228,7 +256,56 @@ func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.TargetGro
 		CanDump:             false,
 	})
 	_, err = addTarget(p, p.pid, currentThread, exePath, proc.StopAttached, "")
-	return grp, err
+	if err != nil {
+		return grp, err
+	}
+
+	libs := make([]*coreLibrary, 0, len(p.libraries))
+	for _, lib := range p.libraries {
+		libs = append(libs, lib)
+	}
+	sort.Slice(libs, func(i, j int) bool { return libs[i].staticBase < libs[j].staticBase })
+	for _, lib := range libs {
+		if err := p.bi.AddImage(lib.path, lib.staticBase); err != nil {
+			logflags.DebuggerLogger().Debugf("could not load shared library %s: %v", lib.path, err)
+		}
+	}
+	return grp, nil
+}
+
+// decompressCore decompresses corePath to a temporary file, if it is
+// compressed with gzip, and returns the path of the temporary file.
+// Returns an empty string if corePath is not compressed.
+func decompressCore(corePath string) (string, error) {
+	fh, err := os.Open(corePath)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(fh, magic); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return "", nil
+	}
+	if _, err := fh.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(fh))
+	if err != nil {
+		return "", fmt.Errorf("could not decompress %s: %v", corePath, err)
+	}
+	out, err := os.CreateTemp("", "dlv-core-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, gz)
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("could not decompress %s: %v", corePath, err)
+	}
+	return out.Name(), nil
 }
 
 // BinInfo will return the binary info.
@@ -319,6 +396,12 @@ func (t *thread) ProcessMemory() proc.MemoryReadWriter {
 	return t.p
 }
 
+// SignalInfo returns the signal received by the thread, if it is recorded
+// in the core file.
+func (t *thread) SignalInfo() *proc.SignalInfo {
+	return t.signal
+}
+
 // Breakpoint returns the current breakpoint this thread is stopped at.
 // For core files this always returns an empty BreakpointState struct, as
 // there are no breakpoints when debugging core files.
@@ -431,6 +514,12 @@ func (p *process) Detach(int, bool) error {
 }
 
 func (p *process) Close() error {
+	for _, lib := range p.libraries {
+		lib.file.Close()
+	}
+	if p.tmpCorePath != "" {
+		os.Remove(p.tmpCorePath)
+	}
 	return nil
 }
 
@@ -460,8 +549,12 @@ func (p *process) FindThread(threadID int) (proc.Thread, bool) {
 	return t, ok
 }
 
+// MemoryMap returns the memory mappings saved in the core file.
 func (p *process) MemoryMap() ([]proc.MemoryMapEntry, error) {
-	return nil, proc.ErrMemoryMapNotSupported
+	if p.memoryMap == nil {
+		return nil, proc.ErrMemoryMapNotSupported
+	}
+	return p.memoryMap, nil
 }
 
 func (p *process) DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (threadsDone bool, out []elfwriter.Note, err error) {
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"go/constant"
@@ -199,6 +200,25 @@ func TestSplicedReader(t *testing.T) {
 }
 
 func withCoreFile(t *testing.T, name, args string) *proc.TargetGroup {
+	fix, corePath := makeCoreFile(t, name, args)
+	if corePath == "" {
+		return nil
+	}
+	p, err := OpenCore(corePath, fix.Path, []string{})
+	if err != nil {
+		t.Errorf("OpenCore(%q) failed: %v", corePath, err)
+		pat, err := os.ReadFile("/proc/sys/kernel/core_pattern")
+		t.Errorf("read core_pattern: %q, %v", pat, err)
+		apport, err := os.ReadFile("/var/log/apport.log")
+		t.Errorf("read apport log: %q, %v", apport, err)
+		t.Fatalf("previous errors")
+	}
+	return p
+}
+
+// makeCoreFile builds the fixture name and runs it with args, returns the
+// fixture and the path of the core file it generated.
+func makeCoreFile(t *testing.T, name, args string) (test.Fixture, string) {
 	// This is all very fragile and won't work on hosts with non-default core patterns.
 	// Might be better to check in the binary and core?
 	tempDir := t.TempDir()
@@ -218,22 +238,11 @@ func withCoreFile(t *testing.T, name, args string) *proc.TargetGroup {
 		err := exec.Command("coredumpctl", "--output="+cores[0], "dump", fix.Path).Run()
 		if err != nil {
 			t.Skipf("core file was not produced, could not run test, coredumpctl error: %v", err)
-			return nil
+			return fix, ""
 		}
 		test.AddPathToRemove(cores[0])
 	}
-	corePath := cores[0]
-
-	p, err := OpenCore(corePath, fix.Path, []string{})
-	if err != nil {
-		t.Errorf("OpenCore(%q) failed: %v", corePath, err)
-		pat, err := os.ReadFile("/proc/sys/kernel/core_pattern")
-		t.Errorf("read core_pattern: %q, %v", pat, err)
-		apport, err := os.ReadFile("/var/log/apport.log")
-		t.Errorf("read apport log: %q, %v", apport, err)
-		t.Fatalf("previous errors")
-	}
-	return p
+	return fix, cores[0]
 }
 
 func logRegisters(t *testing.T, regs proc.Registers, arch *proc.Arch) {
@@ -447,6 +456,158 @@ mainSearch:
 	t.Logf("s = %#v\n", v2)
 }
 
+func TestCoreHeapHistogram(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+
+	// heapprog is killed by the SIGTRAP of runtime.Breakpoint.
+	grp := withCoreFile(t, "heapprog", "")
+	stats, err := proc.HeapHistogram(grp.Selected)
+	assertNoError(err, t, "HeapHistogram")
+	counts := make(map[string]int64)
+	for _, st := range stats {
+		counts[st.Type] = st.Count
+	}
+	for typ, n := range map[string]int64{"main.small": 1000, "main.medium": 100, "main.large": 3} {
+		if counts[typ] != n {
+			t.Errorf("wrong count for %s: got %d expected %d", typ, counts[typ], n)
+		}
+	}
+}
+
+func TestCoreHeapReferences(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+
+	grp := withCoreFile(t, "heapprog", "")
+	p := grp.Selected
+	scope, err := proc.ThreadScope(p, p.CurrentThread())
+	assertNoError(err, t, "ThreadScope")
+	refs, err := scope.HeapReferences("main.list.next.next", 0)
+	assertNoError(err, t, "HeapReferences")
+	found := false
+	for _, chain := range refs.Chains {
+		if chain[0].Name == "main.list" && len(chain) == 3 && chain[2].Path == ".next" {
+			found = true
+		}
+	}
+	if refs.Type != "main.node" || !found {
+		t.Errorf("wrong references: %#v", refs)
+	}
+}
+
+func TestCoreFindMemory(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+
+	grp := withCoreFile(t, "heapprog", "")
+	p := grp.Selected
+	matches, _, err := proc.FindMemory(p, 0, 0, []byte("holder"), 0)
+	assertNoError(err, t, "FindMemory")
+	found := false
+	for _, m := range matches {
+		if m.Mapping != nil && m.Mapping.Filename != "" && !m.Mapping.Write {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("string not found in the executable: %#v", matches)
+	}
+}
+
+func TestCoreMemoryMappings(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+
+	grp := withCoreFile(t, "heapprog", "")
+	p := grp.Selected
+	maps, err := proc.MemoryMappings(p)
+	assertNoError(err, t, "MemoryMappings")
+	kinds := map[proc.MemoryMappingKind]bool{}
+	for _, m := range maps {
+		kinds[m.Kind] = true
+		if m.Kind == proc.MappingStack && m.GoroutineID == 0 {
+			t.Errorf("stack without goroutine: %#v", m)
+		}
+	}
+	for _, kind := range []proc.MemoryMappingKind{proc.MappingText, proc.MappingData, proc.MappingHeap, proc.MappingStack} {
+		if !kinds[kind] {
+			t.Errorf("no %q mapping found in %#v", kind, maps)
+		}
+	}
+}
+
+func TestCoreWriteMemory(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+
+	grp := withCoreFile(t, "heapprog", "")
+	p := grp.Selected
+	scope, err := proc.ThreadScope(p, p.CurrentThread())
+	assertNoError(err, t, "ThreadScope")
+	v, err := scope.EvalExpression("uintptr(&main.list.n)", proc.LoadConfig{})
+	assertNoError(err, t, "EvalExpression")
+	addr, _ := constant.Uint64Val(v.Value)
+	if err := p.WriteMemory(addr, []byte{1}); err != proc.ErrWriteRecorded {
+		t.Errorf("expected ErrWriteRecorded, got %v", err)
+	}
+}
+
+func TestCoreFindCrash(t *testing.T) {
+	mustSupportCore(t)
+
+	findCrash := func(t *testing.T, fixture string) *proc.Crash {
+		grp := withCoreFile(t, fixture, "")
+		c, err := proc.FindCrash(grp.Selected)
+		if err != nil {
+			t.Fatalf("FindCrash: %v", err)
+		}
+		t.Logf("%s: kind %v message %q signal %#v gosignal %#v", fixture, c.Kind, c.Message, c.Signal, c.GoSignal)
+		if c.Signal == nil {
+			t.Errorf("signal not found")
+		}
+		return c
+	}
+
+	t.Run("panic", func(t *testing.T) {
+		c := findCrash(t, "panic")
+		if c.Kind != proc.CrashPanic {
+			t.Fatalf("wrong crash kind %v", c.Kind)
+		}
+		if c.G == nil || c.G.UserCurrent().Fn == nil || c.G.UserCurrent().Fn.Name != "main.main" {
+			t.Errorf("wrong crashing goroutine %#v", c.G)
+		}
+		if len(c.Panics) != 1 {
+			t.Fatalf("wrong number of panics %d", len(c.Panics))
+		}
+		v := c.Panics[0]
+		if len(v.Children) != 1 || v.Children[0].Value == nil || constant.StringVal(v.Children[0].Value) != "BOOM!" {
+			t.Errorf("wrong panic value %#v", v)
+		}
+	})
+
+	t.Run("fatal error", func(t *testing.T) {
+		c := findCrash(t, "fatalerror")
+		if c.Kind != proc.CrashFatalError {
+			t.Fatalf("wrong crash kind %v", c.Kind)
+		}
+		if c.Message != "go of nil func value" {
+			t.Errorf("wrong message %q", c.Message)
+		}
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		test.MustHaveCgo(t)
+		c := findCrash(t, "cgosigsegvstack")
+		if c.Kind != proc.CrashSignal {
+			t.Fatalf("wrong crash kind %v", c.Kind)
+		}
+		if c.GoSignal == nil || c.GoSignal.Name() != "SIGSEGV" || c.GoSignal.Addr != 0 {
+			t.Errorf("wrong signal %#v", c.GoSignal)
+		}
+	})
+}
+
 func TestMinidump(t *testing.T) {
 	t.Parallel()
 	if runtime.GOOS != "windows" || runtime.GOARCH != "amd64" {
@@ -545,3 +706,105 @@ func mustSupportCore(t *testing.T) {
 		t.Skip("disabled on linux, Github Actions, with PIE buildmode")
 	}
 }
+
+func TestCoreSharedLibraries(t *testing.T) {
+	t.Parallel()
+	mustSupportCore(t)
+	test.MustHaveCgo(t)
+
+	fix, corePath := makeCoreFile(t, "cgosigsegvstack", "")
+	if corePath == "" {
+		return
+	}
+
+	findLibc := func(libPaths SharedLibraryPaths) (*proc.TargetGroup, *proc.Image) {
+		t.Helper()
+		grp, err := OpenCoreWithLibraries(corePath, fix.Path, nil, libPaths)
+		if err != nil {
+			t.Fatalf("OpenCoreWithLibraries(%#v): %v", libPaths, err)
+		}
+		for _, image := range grp.Selected.BinInfo().Images[1:] {
+			if strings.HasPrefix(filepath.Base(image.Path), "libc.so") {
+				return grp, image
+			}
+		}
+		return grp, nil
+	}
+
+	grp, libc := findLibc(SharedLibraryPaths{})
+	if libc == nil {
+		t.Fatal("libc not loaded")
+	}
+	libcPath := libc.Path
+	grp.Detach(false)
+	t.Logf("libc: %s", libcPath)
+
+	// A sysroot containing a copy of libc
+	sysroot := t.TempDir()
+	buf, err := os.ReadFile(libcPath)
+	assertNoError(err, t, "ReadFile(libc)")
+	assertNoError(os.MkdirAll(filepath.Join(sysroot, filepath.Dir(libcPath)), 0o755), t, "MkdirAll")
+	assertNoError(os.WriteFile(filepath.Join(sysroot, libcPath), buf, 0o644), t, "WriteFile")
+	grp, libc = findLibc(SharedLibraryPaths{Sysroot: sysroot})
+	if libc == nil || libc.Path != filepath.Join(sysroot, libcPath) {
+		t.Errorf("libc not loaded from the sysroot: %#v", libc)
+	}
+	grp.Detach(false)
+
+	// A search path containing a copy of libc
+	searchDir := t.TempDir()
+	assertNoError(os.WriteFile(filepath.Join(searchDir, filepath.Base(libcPath)), buf, 0o644), t, "WriteFile")
+	grp, libc = findLibc(SharedLibraryPaths{Sysroot: t.TempDir(), SearchPath: []string{searchDir}})
+	if libc == nil || libc.Path != filepath.Join(searchDir, filepath.Base(libcPath)) {
+		t.Errorf("libc not loaded from the search path: %#v", libc)
+	}
+	grp.Detach(false)
+
+	// A sysroot containing a different file in place of libc
+	exe, err := os.ReadFile(fix.Path)
+	assertNoError(err, t, "ReadFile(exe)")
+	assertNoError(os.WriteFile(filepath.Join(sysroot, libcPath), exe, 0o644), t, "WriteFile")
+	var warnings []string
+	grp, libc = findLibc(SharedLibraryPaths{Sysroot: sysroot, Warn: func(msg string) { warnings = append(warnings, msg) }})
+	if libc != nil {
+		t.Errorf("libc with a mismatched build ID was loaded: %#v", libc)
+	}
+	grp.Detach(false)
+	found := false
+	for _, msg := range warnings {
+		if strings.Contains(msg, libcPath) && strings.Contains(msg, "build ID mismatch") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no build ID mismatch warning for %s: %q", libcPath, warnings)
+	}
+}
+
+func TestBuildIDFromNotes(t *testing.T) {
+	var notes []byte
+	appendNote := func(typ uint32, name string, desc []byte) {
+		for _, n := range []uint32{uint32(len(name)), uint32(len(desc)), typ} {
+			notes = binary.LittleEndian.AppendUint32(notes, n)
+		}
+		notes = append(notes, name...)
+		for len(notes)%4 != 0 {
+			notes = append(notes, 0)
+		}
+		notes = append(notes, desc...)
+		for len(notes)%4 != 0 {
+			notes = append(notes, 0)
+		}
+	}
+	appendNote(1, "GNU\x00", []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	if id := buildIDFromNotes(notes); id != "" {
+		t.Errorf("unexpected build ID %q", id)
+	}
+	appendNote(_NT_GNU_BUILD_ID, "GNU\x00", []byte{0xde, 0xad, 0xbe, 0xef, 0x01})
+	if id := buildIDFromNotes(notes); id != "deadbeef01" {
+		t.Errorf("wrong build ID %q", id)
+	}
+	if id := buildIDFromNotes(notes[:len(notes)-4]); id != "" {
+		t.Errorf("unexpected build ID from truncated notes %q", id)
+	}
+}
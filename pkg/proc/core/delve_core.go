@@ -117,7 +117,7 @@ func threadsFromDelveNotes(p *process, notes []*note) (proc.Thread, error) {
 			}
 		}
 
-		p.Threads[int(th.id)] = &thread{th, p, proc.CommonThread{}}
+		p.Threads[int(th.id)] = &thread{osThread: th, p: p}
 		if currentThread == nil {
 			currentThread = p.Threads[int(th.id)]
 		}
@@ -35,6 +35,9 @@ const _NT_AUXV elf.NType = 0x6
 // NT_FPREGSET is the note type for floating point registers.
 const _NT_FPREGSET elf.NType = 0x2
 
+// NT_SIGINFO is the siginfo of the signal that caused the core dump. Desc is a LinuxSiginfoNote.
+const _NT_SIGINFO elf.NType = 0x53494749 // "SIGI".
+
 // Fetch architecture using exeELF.Machine from core file
 // Refer https://man7.org/linux/man-pages/man5/elf.5.html
 const (
@@ -50,6 +53,7 @@ const elfErrorBadMagicNumber = "bad magic number"
 func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) proc.Thread {
 	var currentThread proc.Thread
 	var lastThread osThread
+	var lastTh *thread
 
 	for _, note := range notes {
 		switch note.Type {
@@ -70,7 +74,11 @@ func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) p
 			default:
 				continue
 			}
-			p.Threads[lastThread.ThreadID()] = &thread{lastThread, p, proc.CommonThread{}}
+			lastTh = &thread{osThread: lastThread, p: p}
+			if cursig := prStatusCursig(note.Desc); cursig != 0 {
+				lastTh.signal = &proc.SignalInfo{Signo: int(cursig)}
+			}
+			p.Threads[lastThread.ThreadID()] = lastTh
 			if currentThread == nil {
 				currentThread = p.Threads[lastThread.ThreadID()]
 			}
@@ -89,6 +97,12 @@ func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) p
 			}
 		case elf.NT_PRPSINFO:
 			p.pid = int(note.Desc.(*linuxPrPsInfo).Pid)
+		case _NT_SIGINFO:
+			// The kernel only writes the NT_SIGINFO note for the thread that
+			// received the signal, after its NT_PRSTATUS note.
+			if lastTh != nil {
+				lastTh.signal = note.Desc.(*linuxSiginfoNote).signalInfo()
+			}
 		}
 	}
 	return currentThread
@@ -108,7 +122,10 @@ var supportedLinuxMachines = map[elf.Machine]string{
 // https://uhlo.blogspot.com/2012/05/brief-look-into-core-dumps.html,
 // elf_core_dump in https://elixir.bootlin.com/linux/v4.20.17/source/fs/binfmt_elf.c,
 // and, if absolutely desperate, readelf.c from the binutils source.
-func readLinuxOrPlatformIndependentCore(corePath, exePath string) (*process, proc.Thread, error) {
+//
+// The shared libraries mapped in the core file are looked up using
+// libPaths.
+func readLinuxOrPlatformIndependentCore(corePath, exePath string, libPaths SharedLibraryPaths) (*process, proc.Thread, error) {
 	coreFile, err := elf.Open(corePath)
 	if err != nil {
 		if _, isfmterr := err.(*elf.FormatError); isfmterr && (strings.Contains(err.Error(), elfErrorBadMagicNumber) || strings.Contains(err.Error(), " at offset 0x0: too short")) {
@@ -146,8 +163,6 @@ func readLinuxOrPlatformIndependentCore(corePath, exePath string) (*process, pro
 		}
 	}
 
-	memory := buildMemory(coreFile, exeELF, exe, notes)
-
 	// TODO support 386
 	var bi *proc.BinaryInfo
 	if platformIndependentDelveCore {
@@ -164,12 +179,25 @@ func readLinuxOrPlatformIndependentCore(corePath, exePath string) (*process, pro
 
 	entryPoint := findEntryPoint(notes, bi.Arch.PtrSize())
 
+	var libs map[string]*coreLibrary
+	exeName := ""
+	if fileNote := findFileNote(notes); fileNote != nil {
+		exeName = fileNote.nameOf(entryPoint)
+		if exeName != "" {
+			libs = findCoreLibraries(coreFile, fileNote, exeName, libPaths)
+		}
+	}
+
+	memory := buildMemory(coreFile, exeELF, exe, notes, exeName, libs)
+
 	p := &process{
 		mem:         memory,
+		memoryMap:   buildMemoryMap(coreFile, notes),
 		Threads:     map[int]*thread{},
 		entryPoint:  entryPoint,
 		bi:          bi,
 		breakpoints: proc.NewBreakpointMap(),
+		libraries:   libs,
 	}
 
 	if platformIndependentDelveCore {
@@ -250,6 +278,7 @@ func (t *linuxLOONG64Thread) ThreadID() int {
 // - NT_FILE: File mapping information, e.g. program text mappings. Desc is a LinuxNTFile.
 // - NT_PRPSINFO: Information about a process, including PID and signal. Desc is a LinuxPrPsInfo.
 // - NT_PRSTATUS: Information about a thread, including base registers, state, etc. Desc is a LinuxPrStatus.
+// - NT_SIGINFO: The siginfo of the signal that caused the core dump. Desc is a LinuxSiginfoNote.
 // - NT_FPREGSET (Not implemented): x87 floating point registers.
 // - NT_X86_XSTATE: Other registers, including AVX and such.
 type note struct {
@@ -347,7 +376,7 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 		// No good documentation reference, but the structure is
 		// simply a header, including entry count, followed by that
 		// many entries, and then the file name of each entry,
-		// null-delimited. Not reading the names here.
+		// null-delimited.
 		data := &linuxNTFile{}
 		if err := binary.Read(descReader, binary.LittleEndian, &data.linuxNTFileHdr); err != nil {
 			return nil, fmt.Errorf("reading NT_FILE header: %v", err)
@@ -359,6 +388,11 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 			}
 			data.entries = append(data.entries, entry)
 		}
+		names, err := io.ReadAll(descReader)
+		if err != nil {
+			return nil, fmt.Errorf("reading NT_FILE names: %v", err)
+		}
+		data.names = strings.Split(string(names), "\x00")
 		note.Desc = data
 	case _NT_X86_XSTATE:
 		if machineType == _EM_X86_64 {
@@ -368,6 +402,11 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 			}
 			note.Desc = &fpregs
 		}
+	case _NT_SIGINFO:
+		note.Desc = &linuxSiginfoNote{}
+		if err := binary.Read(descReader, binary.LittleEndian, note.Desc); err != nil {
+			return nil, fmt.Errorf("reading NT_SIGINFO: %v", err)
+		}
 	case _NT_AUXV, elfwriter.DelveHeaderNoteType, elfwriter.DelveThreadNodeType:
 		note.Desc = desc
 	case _NT_FPREGSET:
@@ -412,20 +451,35 @@ func skipPadding(r io.ReadSeeker, pad int64) error {
 	return nil
 }
 
-func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.MemoryReader {
+// buildMemory returns the memory of the process described by the core
+// file. File mappings of the executable, named exeName, and of the shared
+// libraries in libs are read from the respective files, unless the core
+// file contains them. If exeName is empty all file mappings are assumed
+// to be of the executable.
+func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note, exeName string, libs map[string]*coreLibrary) proc.MemoryReader {
 	memory := &SplicedMemory{}
 
-	// For now, assume all file mappings are to the exe.
-	for _, note := range notes {
-		if note.Type == _NT_FILE {
-			fileNote := note.Desc.(*linuxNTFile)
-			for _, entry := range fileNote.entries {
-				r := &offsetReaderAt{
-					reader: exe,
-					offset: entry.Start - (entry.FileOfs * fileNote.PageSize),
+	if fileNote := findFileNote(notes); fileNote != nil {
+		for i, entry := range fileNote.entries {
+			reader := exe
+			if exeName != "" {
+				name := ""
+				if i < len(fileNote.names) {
+					name = fileNote.names[i]
+				}
+				if name != exeName {
+					lib := libs[name]
+					if lib == nil {
+						continue
+					}
+					reader = lib.file
 				}
-				memory.Add(r, entry.Start, entry.End-entry.Start)
 			}
+			r := &offsetReaderAt{
+				reader: reader,
+				offset: entry.Start - (entry.FileOfs * fileNote.PageSize),
+			}
+			memory.Add(r, entry.Start, entry.End-entry.Start)
 		}
 	}
 
@@ -451,6 +505,59 @@ func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.Me
 	return memory
 }
 
+// buildMemoryMap returns the memory mappings described by the PT_LOAD
+// segments of the core file, the name of the mapped files are read from
+// the NT_FILE note.
+func buildMemoryMap(core *elf.File, notes []*note) []proc.MemoryMapEntry {
+	fileNote := findFileNote(notes)
+
+	memmap := []proc.MemoryMapEntry{}
+	for _, prog := range core.Progs {
+		if prog.Type != elf.PT_LOAD {
+			continue
+		}
+		mme := proc.MemoryMapEntry{
+			Addr:  prog.Vaddr,
+			Size:  prog.Memsz,
+			Read:  prog.Flags&elf.PF_R != 0,
+			Write: prog.Flags&elf.PF_W != 0,
+			Exec:  prog.Flags&elf.PF_X != 0,
+		}
+		if fileNote != nil {
+			for i, entry := range fileNote.entries {
+				if prog.Vaddr >= entry.Start && prog.Vaddr < entry.End && i < len(fileNote.names) {
+					mme.Filename = fileNote.names[i]
+					mme.Offset = entry.FileOfs*fileNote.PageSize + (prog.Vaddr - entry.Start)
+					break
+				}
+			}
+		}
+		memmap = append(memmap, mme)
+	}
+	return memmap
+}
+
+// findFileNote returns the NT_FILE note, nil if there isn't one.
+func findFileNote(notes []*note) *linuxNTFile {
+	var fileNote *linuxNTFile
+	for _, note := range notes {
+		if note.Type == _NT_FILE {
+			fileNote = note.Desc.(*linuxNTFile)
+		}
+	}
+	return fileNote
+}
+
+// nameOf returns the name of the file mapped at addr.
+func (fileNote *linuxNTFile) nameOf(addr uint64) string {
+	for i, entry := range fileNote.entries {
+		if addr >= entry.Start && addr < entry.End && i < len(fileNote.names) {
+			return fileNote.names[i]
+		}
+	}
+	return ""
+}
+
 func findEntryPoint(notes []*note, ptrSize int) uint64 {
 	for _, note := range notes {
 		if note.Type == _NT_AUXV {
@@ -537,10 +644,46 @@ type linuxSiginfo struct {
 	Errno int32
 }
 
+// prStatusCursig returns the Cursig field of a NT_PRSTATUS note.
+func prStatusCursig(desc any) uint16 {
+	switch t := desc.(type) {
+	case *linuxPrStatusAMD64:
+		return t.Cursig
+	case *linuxPrStatusARM64:
+		return t.Cursig
+	case *linuxPrStatusRISCV64:
+		return t.Cursig
+	case *linuxPrStatusLOONG64:
+		return t.Cursig
+	}
+	return 0
+}
+
+// LinuxSiginfoNote is the start of the siginfo_t struct stored in a
+// NT_SIGINFO note, up to the si_addr field.
+type linuxSiginfoNote struct {
+	Signo int32
+	Errno int32
+	Code  int32
+	_     int32
+	Addr  uint64
+}
+
+func (si *linuxSiginfoNote) signalInfo() *proc.SignalInfo {
+	r := &proc.SignalInfo{Signo: int(si.Signo), Code: int(si.Code)}
+	switch si.Signo {
+	case 4, 7, 8, 11: // SIGILL, SIGBUS, SIGFPE, SIGSEGV
+		// si_addr is only meaningful for these signals
+		r.Addr = si.Addr
+	}
+	return r
+}
+
 // LinuxNTFile contains information on mapped files.
 type linuxNTFile struct {
 	linuxNTFileHdr
 	entries []*linuxNTFileEntry
+	names   []string
 }
 
 // LinuxNTFileHdr is a header struct for NTFile.
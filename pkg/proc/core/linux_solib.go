@@ -0,0 +1,233 @@
+package core
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// SharedLibraryPaths describes where local copies of the shared libraries
+// mapped in a core file are looked up.
+type SharedLibraryPaths struct {
+	// Sysroot is prepended to the path that each shared library had on the
+	// machine where the core file was generated.
+	Sysroot string
+	// SearchPath is a list of directories where shared libraries are looked
+	// up by file name, after Sysroot.
+	SearchPath []string
+	// Warn, if not nil, is called with a description of each shared library
+	// that could not be loaded. If it is nil the warnings are written to the
+	// debugger log.
+	Warn func(msg string)
+}
+
+func (libPaths *SharedLibraryPaths) warnf(format string, args ...any) {
+	if libPaths.Warn == nil {
+		logflags.DebuggerLogger().Warnf(format, args...)
+		return
+	}
+	libPaths.Warn(fmt.Sprintf(format, args...))
+}
+
+// candidates returns the local paths where the shared library with the
+// specified name is looked up, in order.
+func (libPaths *SharedLibraryPaths) candidates(name string) []string {
+	r := []string{name}
+	if libPaths.Sysroot != "" {
+		r[0] = filepath.Join(libPaths.Sysroot, name)
+	}
+	for _, dir := range libPaths.SearchPath {
+		r = append(r, filepath.Join(dir, filepath.Base(name)))
+	}
+	return r
+}
+
+// coreLibrary is a shared library mapped in a linux core file.
+type coreLibrary struct {
+	name       string // path of the library on the machine where the core file was generated
+	path       string // path of the local copy of the library
+	file       *os.File
+	staticBase uint64
+}
+
+const _NT_GNU_BUILD_ID = 3
+
+// findCoreLibraries returns the shared libraries listed in the NT_FILE note
+// of a core file, other than exeName, resolved to local files using
+// libPaths. If the core file contains the build ID of a library it is
+// compared with the build ID of the local files, libraries that can not be
+// found or whose build ID does not match are skipped with a warning, see
+// SharedLibraryPaths.Warn.
+func findCoreLibraries(core *elf.File, fileNote *linuxNTFile, exeName string, libPaths SharedLibraryPaths) map[string]*coreLibrary {
+	coreMem := &SplicedMemory{}
+	for _, prog := range core.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Filesz > 0 {
+			coreMem.Add(&offsetReaderAt{reader: prog.ReaderAt, offset: prog.Vaddr}, prog.Vaddr, prog.Filesz)
+		}
+	}
+
+	libs := make(map[string]*coreLibrary)
+	seen := make(map[string]bool)
+	for i, entry := range fileNote.entries {
+		if i >= len(fileNote.names) {
+			break
+		}
+		name := fileNote.names[i]
+		if name == exeName || seen[name] || entry.FileOfs != 0 || !strings.HasPrefix(name, "/") {
+			continue
+		}
+		seen[name] = true
+
+		buildID := coreBuildID(coreMem, entry.Start)
+		var mismatches []string
+		for _, path := range libPaths.candidates(name) {
+			fh, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			ef, err := elf.NewFile(fh)
+			if err != nil {
+				fh.Close()
+				continue
+			}
+			if buildID != "" {
+				if localID := fileBuildID(ef); localID != buildID {
+					mismatches = append(mismatches, fmt.Sprintf("%s has build ID %q", path, localID))
+					fh.Close()
+					continue
+				}
+			} else {
+				logflags.DebuggerLogger().Debugf("could not read the build ID of %s from the core file, using %s without verifying it", name, path)
+			}
+			libs[name] = &coreLibrary{name: name, path: path, file: fh, staticBase: entry.Start - firstLoadVaddr(ef)}
+			break
+		}
+		if libs[name] != nil {
+			continue
+		}
+		if len(mismatches) > 0 {
+			libPaths.warnf("shared library %s not loaded, build ID mismatch: core file has %q, %s", name, buildID, strings.Join(mismatches, ", "))
+		} else {
+			libPaths.warnf("shared library %s not found", name)
+		}
+	}
+	return libs
+}
+
+// firstLoadVaddr returns the page aligned virtual address of the first
+// PT_LOAD segment of ef.
+func firstLoadVaddr(ef *elf.File) uint64 {
+	for _, prog := range ef.Progs {
+		if prog.Type == elf.PT_LOAD {
+			if prog.Align > 1 {
+				return prog.Vaddr &^ (prog.Align - 1)
+			}
+			return prog.Vaddr
+		}
+	}
+	return 0
+}
+
+// coreBuildID returns the build ID of the ELF file mapped at base, reading
+// its headers from mem. Returns an empty string if it can not be read, for
+// example because the core file does not contain the first page of the
+// mapping.
+func coreBuildID(mem proc.MemoryReader, base uint64) string {
+	const (
+		ehsize    = 64
+		phentsize = 56
+	)
+	hdr := make([]byte, ehsize)
+	if _, err := mem.ReadMemory(hdr, base); err != nil {
+		return ""
+	}
+	if !bytes.HasPrefix(hdr, []byte(elf.ELFMAG)) || elf.Class(hdr[elf.EI_CLASS]) != elf.ELFCLASS64 || elf.Data(hdr[elf.EI_DATA]) != elf.ELFDATA2LSB {
+		return ""
+	}
+	phoff := binary.LittleEndian.Uint64(hdr[32:])
+	if binary.LittleEndian.Uint16(hdr[54:]) != phentsize {
+		return ""
+	}
+	phnum := binary.LittleEndian.Uint16(hdr[56:])
+	phdrs := make([]byte, int(phnum)*phentsize)
+	if _, err := mem.ReadMemory(phdrs, base+phoff); err != nil {
+		return ""
+	}
+
+	type progHeader struct {
+		Type, Flags                              uint32
+		Off, Vaddr, Paddr, Filesz, Memsz, Palign uint64
+	}
+	progs := make([]progHeader, phnum)
+	if err := binary.Read(bytes.NewReader(phdrs), binary.LittleEndian, progs); err != nil {
+		return ""
+	}
+	bias := base
+	for _, prog := range progs {
+		if elf.ProgType(prog.Type) == elf.PT_LOAD {
+			bias = base - prog.Vaddr&^(max(prog.Palign, 1)-1)
+			break
+		}
+	}
+	for _, prog := range progs {
+		if elf.ProgType(prog.Type) != elf.PT_NOTE || prog.Filesz > 1<<16 {
+			continue
+		}
+		notes := make([]byte, prog.Filesz)
+		if _, err := mem.ReadMemory(notes, bias+prog.Vaddr); err != nil {
+			continue
+		}
+		if id := buildIDFromNotes(notes); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// fileBuildID returns the build ID of ef, or an empty string.
+func fileBuildID(ef *elf.File) string {
+	for _, prog := range ef.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		notes, err := io.ReadAll(prog.Open())
+		if err != nil {
+			continue
+		}
+		if id := buildIDFromNotes(notes); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// buildIDFromNotes returns the build ID contained in the contents of a
+// PT_NOTE segment, or an empty string.
+func buildIDFromNotes(notes []byte) string {
+	align4 := func(n uint32) uint32 { return (n + 3) &^ 3 }
+	for len(notes) >= 12 {
+		namesz := binary.LittleEndian.Uint32(notes[0:])
+		descsz := binary.LittleEndian.Uint32(notes[4:])
+		typ := binary.LittleEndian.Uint32(notes[8:])
+		notes = notes[12:]
+		if uint64(align4(namesz))+uint64(align4(descsz)) > uint64(len(notes)) {
+			break
+		}
+		name := notes[:namesz]
+		desc := notes[align4(namesz):][:descsz]
+		if typ == _NT_GNU_BUILD_ID && string(name) == "GNU\x00" {
+			return hex.EncodeToString(desc)
+		}
+		notes = notes[align4(namesz)+align4(descsz):]
+	}
+	return ""
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/go-delve/delve/pkg/proc/winutil"
 )
 
-func readAMD64Minidump(minidumpPath, exePath string) (*process, proc.Thread, error) {
+func readAMD64Minidump(minidumpPath, exePath string, _ SharedLibraryPaths) (*process, proc.Thread, error) {
 	var logfn func(string, ...any)
 	if logflags.Minidump() {
 		logfn = logflags.MinidumpLogger().Infof
@@ -44,7 +44,7 @@ func readAMD64Minidump(minidumpPath, exePath string) (*process, proc.Thread, err
 
 	for i := range mdmp.Threads {
 		th := &mdmp.Threads[i]
-		p.Threads[int(th.ID)] = &thread{&windowsAMD64Thread{th}, p, proc.CommonThread{}}
+		p.Threads[int(th.ID)] = &thread{osThread: &windowsAMD64Thread{th}, p: p}
 	}
 	var currentThread proc.Thread
 	if len(mdmp.Threads) > 0 {
@@ -0,0 +1,290 @@
+package proc
+
+import (
+	"fmt"
+	"go/constant"
+)
+
+// CrashKind describes how a process crashed.
+type CrashKind uint8
+
+const (
+	CrashUnknown    CrashKind = iota // the cause of the crash could not be determined
+	CrashPanic                       // unrecovered panic
+	CrashFatalError                  // fatal runtime error (runtime.throw or runtime.fatal)
+	CrashSignal                      // signal not handled by the Go runtime
+)
+
+func (k CrashKind) String() string {
+	switch k {
+	case CrashPanic:
+		return "panic"
+	case CrashFatalError:
+		return "fatal error"
+	case CrashSignal:
+		return "signal"
+	default:
+		return "unknown"
+	}
+}
+
+// SignalInfo describes a signal received by the target process.
+type SignalInfo struct {
+	Signo int
+	Code  int
+	// Addr is the faulting address, for SIGSEGV, SIGBUS, SIGILL and
+	// SIGFPE.
+	Addr uint64
+	// PC is the address of the instruction that caused the signal, if
+	// known.
+	PC uint64
+}
+
+// Name returns the name of the signal, using linux signal numbers.
+func (sig *SignalInfo) Name() string {
+	if sig.Signo > 0 && sig.Signo < len(linuxSignalNames) && linuxSignalNames[sig.Signo] != "" {
+		return linuxSignalNames[sig.Signo]
+	}
+	return fmt.Sprintf("signal %d", sig.Signo)
+}
+
+var linuxSignalNames = [...]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	5:  "SIGTRAP",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	10: "SIGUSR1",
+	11: "SIGSEGV",
+	12: "SIGUSR2",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+	16: "SIGSTKFLT",
+	17: "SIGCHLD",
+	18: "SIGCONT",
+	19: "SIGSTOP",
+	20: "SIGTSTP",
+	21: "SIGTTIN",
+	22: "SIGTTOU",
+	23: "SIGURG",
+	24: "SIGXCPU",
+	25: "SIGXFSZ",
+	26: "SIGVTALRM",
+	27: "SIGPROF",
+	28: "SIGWINCH",
+	29: "SIGIO",
+	30: "SIGPWR",
+	31: "SIGSYS",
+}
+
+// SignalThread is implemented by threads that record the last signal they
+// received, for example the threads of linux core files.
+type SignalThread interface {
+	Thread
+	// SignalInfo returns the signal received by the thread or nil.
+	SignalInfo() *SignalInfo
+}
+
+// Crash describes the cause of the crash of a process, see FindCrash.
+type Crash struct {
+	Kind CrashKind
+	// G is the goroutine that crashed, or nil if it could not be found.
+	G *G
+	// Thread is the thread that received the signal that terminated the
+	// process.
+	Thread Thread
+	// Message is the argument of runtime.throw or runtime.fatal.
+	Message string
+	// Panics are the values passed to panic, in the order they were
+	// raised. Panics that were recovered are included.
+	Panics []*Variable
+	// Signal is the signal that terminated the process, if known.
+	Signal *SignalInfo
+	// GoSignal is the signal that caused the panic or fatal error of G, for
+	// example the SIGSEGV that caused a nil pointer dereference panic.
+	GoSignal *SignalInfo
+}
+
+const (
+	crashMaxStackDepth = 50  // maximum number of frames of each goroutine that FindCrash looks at
+	crashMaxPanics     = 100 // maximum length of the _panic list read by readPanics
+)
+
+// FindCrash determines why the target process crashed, it is meant to be
+// used on core files.
+// The crashing goroutine is the first goroutine, starting with the
+// goroutine of the current thread, that is executing an unrecovered panic
+// or a fatal runtime error.
+func FindCrash(t *Target) (*Crash, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	c := &Crash{Thread: t.CurrentThread()}
+	if st, ok := c.Thread.(SignalThread); ok {
+		c.Signal = st.SignalInfo()
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if curg, _ := GetG(c.Thread); curg != nil {
+		for i := range gs {
+			if gs[i].ID == curg.ID {
+				gs[0], gs[i] = gs[i], gs[0]
+				break
+			}
+		}
+	}
+
+	for _, g := range gs {
+		if g.Unreadable != nil || g.variable == nil {
+			continue
+		}
+		frames, err := GoroutineStacktrace(t, g, crashMaxStackDepth, 0)
+		if err != nil {
+			continue
+		}
+		if crashFromStack(t, g, frames, c) {
+			c.G = g
+			return c, nil
+		}
+	}
+
+	if c.Signal != nil {
+		c.Kind = CrashSignal
+		c.G, _ = GetG(c.Thread)
+	}
+	return c, nil
+}
+
+// crashFromStack fills c if frames, the stack of g, is executing an
+// unrecovered panic or a fatal error, or if it is handling a signal
+// received while executing code that is not Go code (for example cgo).
+func crashFromStack(t *Target, g *G, frames []Stackframe, c *Crash) bool {
+	sigtramp := -1
+	for i := range frames {
+		fn := frames[i].Current.Fn
+		if fn == nil {
+			continue
+		}
+		switch fn.Name {
+		case "runtime.throw", "runtime.fatal":
+			c.Kind = CrashFatalError
+			if s := crashFrameArg(t, g, frames[i:], "s"); s != nil && s.Value != nil && s.Value.Kind() == constant.String {
+				c.Message = constant.StringVal(s.Value)
+			}
+			c.GoSignal = goroutineSignal(g)
+			return true
+		case "runtime.fatalpanic", "runtime.startpanic":
+			c.Kind = CrashPanic
+			c.Panics = goroutinePanics(g)
+			c.GoSignal = goroutineSignal(g)
+			return true
+		case "runtime.sigtrampgo":
+			// the outermost call to sigtrampgo is the one handling the
+			// original signal, the others handle the signals used by the
+			// runtime to crash the process.
+			sigtramp = i
+		}
+	}
+	if sigtramp < 0 {
+		return false
+	}
+	c.Kind = CrashSignal
+	c.GoSignal = sigtrampSignal(t, g, frames[sigtramp:])
+	return true
+}
+
+// crashFrameArg returns the argument of the function of the first frame
+// in frames with the specified name, or nil if it can not be read.
+func crashFrameArg(t *Target, g *G, frames []Stackframe, name string) *Variable {
+	threadID := 0
+	if g.Thread != nil {
+		threadID = g.Thread.ThreadID()
+	}
+	scope := FrameToScope(t, t.Memory(), g, threadID, frames...)
+	args, err := scope.FunctionArguments(loadFullValueLongerStrings)
+	if err != nil {
+		return nil
+	}
+	for _, arg := range args {
+		if arg.Name == name && arg.Unreadable == nil {
+			return arg
+		}
+	}
+	return nil
+}
+
+// sigtrampSignal returns the signal received by the call to
+// runtime.sigtrampgo at the top of frames.
+func sigtrampSignal(t *Target, g *G, frames []Stackframe) *SignalInfo {
+	sig := crashFrameArg(t, g, frames, "sig")
+	if sig == nil || sig.Value == nil {
+		return nil
+	}
+	signo, _ := constant.Int64Val(sig.Value)
+	r := &SignalInfo{Signo: int(signo)}
+	info := crashFrameArg(t, g, frames, "info")
+	if info == nil {
+		return r
+	}
+	info = info.maybeDereference()
+	if info.Addr == 0 {
+		return r
+	}
+	if v := info.loadFieldNamed("si_code"); v != nil && v.Value != nil {
+		code, _ := constant.Int64Val(v.Value)
+		r.Code = int(code)
+	}
+	if v := info.loadFieldNamed("si_addr"); v != nil && v.Value != nil {
+		r.Addr, _ = constant.Uint64Val(v.Value)
+	}
+	return r
+}
+
+// goroutinePanics returns the values of the panics in the _panic list of
+// g, oldest first.
+func goroutinePanics(g *G) []*Variable {
+	var r []*Variable
+	for _, p := range readPanics(g) {
+		if p.Value != nil {
+			r = append(r, p.Value)
+		}
+	}
+	return r
+}
+
+// goroutineSignal returns the signal recorded in the sig, sigcode0,
+// sigcode1 and sigpc fields of g, if any.
+func goroutineSignal(g *G) *SignalInfo {
+	// +rtype -field g.sig uint32
+	// +rtype -field g.sigcode0 uintptr
+	// +rtype -field g.sigcode1 uintptr
+	// +rtype -field g.sigpc uintptr
+	sig := g.variable.loadFieldNamed("sig")
+	if sig == nil || sig.Value == nil {
+		return nil
+	}
+	signo, _ := constant.Int64Val(sig.Value)
+	if signo == 0 {
+		return nil
+	}
+	r := &SignalInfo{Signo: int(signo)}
+	if v := g.variable.loadFieldNamed("sigcode0"); v != nil && v.Value != nil {
+		code, _ := constant.Uint64Val(v.Value)
+		r.Code = int(int32(code))
+	}
+	if v := g.variable.loadFieldNamed("sigcode1"); v != nil && v.Value != nil {
+		r.Addr, _ = constant.Uint64Val(v.Value)
+	}
+	if v := g.variable.loadFieldNamed("sigpc"); v != nil && v.Value != nil {
+		r.PC, _ = constant.Uint64Val(v.Value)
+	}
+	return r
+}
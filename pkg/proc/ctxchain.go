@@ -0,0 +1,179 @@
+package proc
+
+import (
+	"errors"
+	"go/parser"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// ContextLayer is a context.Context in the chain of parents of a context,
+// see ContextChain.
+type ContextLayer struct {
+	// Kind is one of "background", "todo", "cancel", "timer", "afterFunc",
+	// "value", "withoutCancel" and "stop" for the contexts implemented by
+	// the context package, or an empty string for other implementations of
+	// context.Context.
+	Kind string
+	// Type is the dynamic type of the context.
+	Type string
+	Addr uint64
+	// Deadline is the deadline of timer contexts.
+	Deadline *Variable
+	// Canceled is true if the context has been canceled, Err and Cause are
+	// the values returned by Err and context.Cause, for cancelable
+	// contexts.
+	Canceled bool
+	Err      *Variable
+	Cause    *Variable
+	// AfterFunc is the function registered by context.AfterFunc, for
+	// afterFunc contexts.
+	AfterFunc *Variable
+	// Key and Value are the key/value pair of value contexts.
+	Key   *Variable
+	Value *Variable
+}
+
+const ctxMaxChainLen = 1000
+
+// ContextChain returns the chain of parents of the context.Context expr
+// evaluates to, starting with the context itself. Deadlines, errors, keys
+// and values are loaded using cfg.
+//
+// Contexts not implemented by the context package are followed if they
+// embed their parent context.
+func (scope *EvalScope) ContextChain(expr string, cfg LoadConfig) ([]ContextLayer, error) {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	v, err := scope.evalAST(t)
+	if err != nil {
+		return nil, err
+	}
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	switch v.Kind {
+	case reflect.Interface, reflect.Ptr, reflect.Struct:
+	default:
+		return nil, errors.New("expression is not a context")
+	}
+
+	field := func(v *Variable, name string) *Variable {
+		if v == nil {
+			return nil
+		}
+		f, err := v.structMember(name)
+		if err != nil {
+			return nil
+		}
+		return f
+	}
+	load := func(v *Variable, name string) *Variable {
+		f := field(v, name)
+		if f != nil {
+			f.Name = name
+			f.loadValue(cfg)
+		}
+		return f
+	}
+
+	var r []ContextLayer
+	visited := make(map[uint64]bool)
+	for v != nil && len(r) < ctxMaxChainLen {
+		if v.Kind == reflect.Interface {
+			v.loadInterface(0, false, LoadConfig{})
+			if v.Unreadable != nil {
+				return r, v.Unreadable
+			}
+			if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid || v.Children[0].Addr == 0 {
+				// nil context
+				break
+			}
+			v = &v.Children[0]
+		}
+		layer := ContextLayer{Type: v.TypeString()}
+		s := v
+		if s.Kind == reflect.Ptr {
+			s = s.maybeDereference()
+			if s.Unreadable != nil {
+				return r, s.Unreadable
+			}
+		}
+		typ, isstruct := s.RealType.(*godwarf.StructType)
+		if visited[s.Addr] {
+			break
+		}
+		if !isstruct || s.Addr == 0 {
+			r = append(r, layer)
+			break
+		}
+		visited[s.Addr] = true
+		layer.Addr = s.Addr
+
+		v = nil
+		switch typ.StructName {
+		case "context.backgroundCtx":
+			layer.Kind = "background"
+		case "context.todoCtx":
+			layer.Kind = "todo"
+		case "context.cancelCtx":
+			layer.Kind = "cancel"
+			readCancelCtx(s, &layer, load)
+			v = field(s, "Context")
+		case "context.timerCtx":
+			layer.Kind = "timer"
+			cc := field(s, "cancelCtx")
+			readCancelCtx(cc, &layer, load)
+			layer.Deadline = load(s, "deadline")
+			v = field(cc, "Context")
+		case "context.afterFuncCtx":
+			layer.Kind = "afterFunc"
+			cc := field(s, "cancelCtx")
+			readCancelCtx(cc, &layer, load)
+			layer.AfterFunc = load(s, "f")
+			v = field(cc, "Context")
+		case "context.valueCtx":
+			layer.Kind = "value"
+			layer.Key = load(s, "key")
+			layer.Value = load(s, "val")
+			v = field(s, "Context")
+		case "context.withoutCancelCtx":
+			layer.Kind = "withoutCancel"
+			v = field(s, "c")
+		case "context.stopCtx":
+			layer.Kind = "stop"
+			v = field(s, "Context")
+		default:
+			if parent := field(s, "Context"); parent != nil && parent.Kind == reflect.Interface {
+				v = parent
+			}
+		}
+		r = append(r, layer)
+	}
+	return r, nil
+}
+
+// readCancelCtx reads the err and cause fields of the context.cancelCtx
+// cc into layer.
+func readCancelCtx(cc *Variable, layer *ContextLayer, load func(*Variable, string) *Variable) {
+	if cc == nil {
+		return
+	}
+	err := load(cc, "err")
+	if err != nil && err.Kind == reflect.Struct {
+		// in recent versions of Go err is an atomic.Value
+		err = load(err, "v")
+		if err != nil {
+			err.Name = "err"
+		}
+	}
+	layer.Err = err
+	layer.Cause = load(cc, "cause")
+	if err != nil && err.Kind == reflect.Interface && err.Unreadable == nil {
+		_, _, isnil := err.readInterface()
+		layer.Canceled = !isnil
+	}
+}
@@ -0,0 +1,427 @@
+package proc
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/dwarf/regnum"
+	"github.com/go-delve/delve/pkg/logflags"
+)
+
+// DataRace is a data race reported by the race detector, see
+// (*Target).DataRace.
+type DataRace struct {
+	// Accesses are the two conflicting memory accesses: the first one is the
+	// access that triggered the report, the second one is the previous
+	// access it conflicts with.
+	Accesses []DataRaceAccess
+
+	bpaddr uint64 // address of the breakpoint waiting for the goroutine of the first access
+}
+
+// DataRaceAccess is one of the memory accesses of a data race.
+type DataRaceAccess struct {
+	Addr   uint64
+	Size   int
+	Write  bool
+	Atomic bool
+	// RaceGoroutine is the number assigned by the race detector to the
+	// goroutine that made the access, it is the goroutine number printed by
+	// the race detector in its reports.
+	RaceGoroutine int
+	// GoroutineID is the ID of the goroutine that made the access, or 0 if
+	// it is not known. It is only known for the access that triggered the
+	// report, for the previous access only RaceGoroutine is available.
+	GoroutineID int64
+	// Stack is the stack trace of the access, as recorded by the race
+	// detector.
+	Stack []Location
+}
+
+// tsanOnReportSymbol is the symbol of __tsan::OnReport(ReportDesc const*, bool),
+// called by the race detector runtime before printing a report.
+const tsanOnReportSymbol = "_ZN6__tsan8OnReportEPKNS_10ReportDescEb"
+
+const (
+	dataRaceMaxAccesses = 2
+	dataRaceMaxFrames   = 100
+)
+
+// DataRace returns the data race that caused the target to stop, or nil if
+// the target did not stop because of a data race.
+func (t *Target) DataRace() *DataRace {
+	return t.dataRace
+}
+
+// createDataRaceBreakpoint creates a breakpoint on the function of the race
+// detector runtime called before a report is printed, if the target was
+// built with -race.
+func (t *Target) createDataRaceBreakpoint() {
+	bi := t.BinInfo()
+	switch bi.Arch.Name {
+	case "amd64", "arm64":
+	default:
+		return
+	}
+	if bi.lookupOneFunc("runtime.raceinit") == nil {
+		return
+	}
+	addr := dataRaceReportAddr(bi)
+	if addr == 0 {
+		return
+	}
+	bp, err := t.SetBreakpoint(dataRaceID, addr, UserBreakpoint, nil)
+	if err == nil {
+		bp.Logical.Name = DataRaceBreakpoint
+		bp.Logical.Set.PidAddrs = append(bp.Logical.Set.PidAddrs, PidAddr{Pid: t.Pid(), Addr: addr})
+		bp.UserBreaklet().callback = t.dataRaceReportCallback
+	}
+}
+
+// dataRaceReportAddr returns the address of tsanOnReportSymbol in the
+// executable. The symbol is global, it is not in bi.SymNames, which only
+// contains local function symbols.
+func dataRaceReportAddr(bi *BinaryInfo) uint64 {
+	image := bi.Images[0]
+	file, err := elf.Open(image.Path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	syms, _ := file.Symbols()
+	for _, sym := range syms {
+		if sym.Name == tsanOnReportSymbol && elf.ST_TYPE(sym.Info) == elf.STT_FUNC {
+			return sym.Value + image.StaticBase
+		}
+	}
+	return 0
+}
+
+// dataRaceReportCallback is called when a thread stops on the breakpoint
+// created by createDataRaceBreakpoint. Since the stack of the race detector
+// runtime can not be unwound it reads the report and sets a breakpoint on
+// the instruction the goroutine that triggered the report will return to,
+// stopping there instead.
+func (t *Target) dataRaceReportCallback(th Thread, _ *Target) (bool, error) {
+	r, err := readDataRaceReport(t, th)
+	if err != nil {
+		return true, fmt.Errorf("could not read data race report: %v", err)
+	}
+	if r == nil {
+		t.dataRaceUncountHit(th)
+		return false, nil
+	}
+
+	g, _ := GetG(th)
+	if g == nil {
+		t.dataRace = r
+		return true, nil
+	}
+	r.Accesses[0].GoroutineID = g.ID
+
+	retaddr := dataRaceReturnAddr(t, r.Accesses[0].Stack)
+	if retaddr == 0 {
+		t.dataRace = r
+		return true, nil
+	}
+	lbp := t.Breakpoints().Logical[dataRaceID]
+	file, line, fnname := lbp.File, lbp.Line, lbp.FunctionName
+	bp, err := t.SetBreakpoint(dataRaceID, retaddr, UserBreakpoint, goroutineCondition(g.ID))
+	lbp.File, lbp.Line, lbp.FunctionName = file, line, fnname
+	if err != nil {
+		// there already is a breakpoint on the return address, stop here.
+		t.dataRace = r
+		return true, nil
+	}
+	r.bpaddr = retaddr
+	if t.dataRaces == nil {
+		t.dataRaces = make(map[uint64]*DataRace)
+	}
+	t.dataRaces[retaddr] = r
+	bp.UserBreaklet().callback = func(Thread, *Target) (bool, error) {
+		t.dataRace = r
+		return true, nil
+	}
+	t.dataRaceUncountHit(th)
+	return false, nil
+}
+
+// dataRaceUncountHit undoes the increment of the hit counts of the data
+// race breakpoint made when th stopped on it without stopping the target,
+// so that each data race is only counted once.
+func (t *Target) dataRaceUncountHit(th Thread) {
+	uncountHit(t.Breakpoints().Logical[dataRaceID], th)
+}
+
+// clearDataRace forgets the data race returned by DataRace and removes
+// the breakpoint that was used to stop for it.
+func (t *Target) clearDataRace() {
+	r := t.dataRace
+	t.dataRace = nil
+	if r == nil || r.bpaddr == 0 {
+		return
+	}
+	delete(t.dataRaces, r.bpaddr)
+	bp := t.Breakpoints().M[r.bpaddr]
+	if bp == nil || bp.LogicalID() != dataRaceID {
+		return
+	}
+	if err := t.ClearBreakpoint(r.bpaddr); err != nil {
+		logflags.DebuggerLogger().Errorf("could not clear data race breakpoint: %v", err)
+		return
+	}
+	for _, thread := range t.ThreadList() {
+		if thread.Breakpoint().Breakpoint == bp {
+			thread.Breakpoint().Clear()
+		}
+	}
+}
+
+// readDataRaceReport reads the report passed to __tsan::OnReport by the
+// thread th. It returns nil if the report is suppressed or is not a data
+// race.
+func readDataRaceReport(t *Target, th Thread) (*DataRace, error) {
+	bi := t.BinInfo()
+	regs, err := th.Registers()
+	if err != nil {
+		return nil, err
+	}
+	dregs := bi.Arch.RegistersToDwarfRegisters(0, regs)
+	var descaddr, suppressed uint64
+	switch bi.Arch.Name {
+	case "amd64":
+		descaddr, suppressed = dregs.Uint64Val(regnum.AMD64_Rdi), dregs.Uint64Val(regnum.AMD64_Rsi)
+	case "arm64":
+		descaddr, suppressed = dregs.Uint64Val(regnum.ARM64_X0), dregs.Uint64Val(regnum.ARM64_X0+1)
+	default:
+		return nil, errors.New("unsupported architecture")
+	}
+	if descaddr == 0 {
+		return nil, errors.New("nil report")
+	}
+	if suppressed&0xff != 0 {
+		return nil, nil
+	}
+
+	mops, err := readDataRaceDesc(t.Memory(), descaddr, int64(bi.Arch.PtrSize()))
+	if err != nil || len(mops) == 0 {
+		return nil, err
+	}
+	r := &DataRace{}
+	for _, mop := range mops {
+		acc := DataRaceAccess{
+			Addr:          mop.addr,
+			Size:          mop.size,
+			Write:         mop.write,
+			Atomic:        mop.atomic,
+			RaceGoroutine: mop.tid,
+		}
+		for _, pc := range mop.pcs {
+			if n := len(acc.Stack); n > 0 && acc.Stack[n-1].PC == pc {
+				// inlined calls are symbolized as multiple frames with the same
+				// address.
+				continue
+			}
+			file, line, fn := bi.PCToLine(pc)
+			acc.Stack = append(acc.Stack, Location{PC: pc, File: file, Line: line, Fn: fn})
+		}
+		r.Accesses = append(r.Accesses, acc)
+	}
+	return r, nil
+}
+
+// errDataRaceReportLayout is returned when a report does not have the
+// layout expected by readDataRaceDesc, for example because the race
+// detector runtime was built from a version of compiler-rt that changed
+// it.
+var errDataRaceReportLayout = errors.New("unsupported layout of the race detector report")
+
+// dataRaceMop is a __tsan::ReportMop, one of the memory accesses of a
+// report.
+type dataRaceMop struct {
+	tid           int
+	addr          uint64
+	size          int
+	write, atomic bool
+	pcs           []uint64
+}
+
+// readDataRaceDesc reads the memory accesses of the __tsan::ReportDesc at
+// descaddr. It returns no accesses if the report is not a data race.
+//
+// The layouts of the structures of the report are the ones declared in
+// compiler-rt/lib/tsan/rtl/tsan_report.h, which the race detector runtime
+// is built from. They are not described by debugging information, the
+// values read are checked for consistency and errDataRaceReportLayout is
+// returned if they are not.
+//
+// The layout of __tsan::ReportDesc is:
+//
+//	ReportType typ;
+//	uptr tag;
+//	Vector<ReportStack*> stacks;
+//	Vector<ReportMop*> mops;
+//	...
+//
+// and a Vector is a {begin, end, last} triple of pointers.
+func readDataRaceDesc(mem MemoryReadWriter, descaddr uint64, ptrSize int64) ([]dataRaceMop, error) {
+	readPtr := func(addr uint64) (uint64, error) {
+		return readUintRaw(mem, addr, ptrSize)
+	}
+
+	const (
+		reportTypeRace  = 0
+		reportTypeCount = 32 // upper bound of the number of report types
+		maxMops         = 64
+	)
+	typ, err := readUintRaw(mem, descaddr, 4)
+	if err != nil {
+		return nil, err
+	}
+	if typ >= reportTypeCount {
+		return nil, errDataRaceReportLayout
+	}
+	if _, _, err := readDataRaceVector(mem, descaddr+uint64(2*ptrSize), ptrSize, ptrSize); err != nil {
+		return nil, err
+	}
+	mopsBegin, mopsEnd, err := readDataRaceVector(mem, descaddr+uint64(5*ptrSize), ptrSize, ptrSize)
+	if err != nil {
+		return nil, err
+	}
+	if typ != reportTypeRace {
+		return nil, nil
+	}
+	n := int((mopsEnd - mopsBegin) / uint64(ptrSize))
+	if n > maxMops {
+		return nil, errDataRaceReportLayout
+	}
+	if n > dataRaceMaxAccesses {
+		n = dataRaceMaxAccesses
+	}
+
+	mops := make([]dataRaceMop, 0, n)
+	for i := 0; i < n; i++ {
+		mopaddr, err := readPtr(mopsBegin + uint64(i)*uint64(ptrSize))
+		if err != nil {
+			return nil, err
+		}
+		mop, err := readDataRaceMop(mem, mopaddr, ptrSize)
+		if err != nil {
+			return nil, err
+		}
+		mops = append(mops, mop)
+	}
+	return mops, nil
+}
+
+// readDataRaceMop reads the __tsan::ReportMop at mopaddr, whose layout
+// is:
+//
+//	int tid;
+//	uptr addr;
+//	int size;
+//	bool write;
+//	bool atomic;
+//	uptr external_tag;
+//	Vector<ReportMopMutex> mset;
+//	ReportStack *stack;
+func readDataRaceMop(mem MemoryReadWriter, mopaddr uint64, ptrSize int64) (dataRaceMop, error) {
+	if mopaddr == 0 || mopaddr%uint64(ptrSize) != 0 {
+		return dataRaceMop{}, errDataRaceReportLayout
+	}
+	buf := make([]byte, 8*ptrSize)
+	if _, err := mem.ReadMemory(buf, mopaddr); err != nil {
+		return dataRaceMop{}, err
+	}
+	order := binary.LittleEndian
+	mop := dataRaceMop{
+		tid:    int(int32(order.Uint32(buf[0:]))),
+		addr:   order.Uint64(buf[ptrSize:]),
+		size:   int(int32(order.Uint32(buf[2*ptrSize:]))),
+		write:  buf[2*ptrSize+4] != 0,
+		atomic: buf[2*ptrSize+5] != 0,
+	}
+	if mop.tid < 0 || mop.size <= 0 || buf[2*ptrSize+4] > 1 || buf[2*ptrSize+5] > 1 {
+		return dataRaceMop{}, errDataRaceReportLayout
+	}
+	// ReportMopMutex is {u64 id; bool write;}
+	if _, _, err := readDataRaceVector(mem, mopaddr+uint64(4*ptrSize), ptrSize, 16); err != nil {
+		return dataRaceMop{}, err
+	}
+	stackaddr := order.Uint64(buf[7*ptrSize:])
+	if stackaddr == 0 {
+		return mop, nil
+	}
+	if stackaddr%uint64(ptrSize) != 0 {
+		return dataRaceMop{}, errDataRaceReportLayout
+	}
+
+	// ReportStack starts with a pointer to a linked list of
+	// SymbolizedStack {SymbolizedStack *next; AddressInfo info;} and
+	// AddressInfo starts with the address of the frame.
+	frame, err := readUintRaw(mem, stackaddr, ptrSize)
+	if err != nil {
+		return mop, err
+	}
+	for frame != 0 && len(mop.pcs) < dataRaceMaxFrames {
+		if frame%uint64(ptrSize) != 0 {
+			return dataRaceMop{}, errDataRaceReportLayout
+		}
+		next, err := readUintRaw(mem, frame, ptrSize)
+		if err != nil {
+			return mop, err
+		}
+		pc, err := readUintRaw(mem, frame+uint64(ptrSize), ptrSize)
+		if err != nil {
+			return mop, err
+		}
+		frame = next
+		mop.pcs = append(mop.pcs, pc)
+	}
+	return mop, nil
+}
+
+// readDataRaceVector reads the __sanitizer::Vector at addr, whose elements
+// are elemSize bytes long, and returns its begin and end pointers.
+func readDataRaceVector(mem MemoryReadWriter, addr uint64, ptrSize, elemSize int64) (begin, end uint64, err error) {
+	var v [3]uint64
+	for i := range v {
+		v[i], err = readUintRaw(mem, addr+uint64(int64(i)*ptrSize), ptrSize)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	begin, end, last := v[0], v[1], v[2]
+	if begin > end || end > last || (begin == 0 && last != 0) || begin%uint64(ptrSize) != 0 || (end-begin)%uint64(elemSize) != 0 {
+		return 0, 0, errDataRaceReportLayout
+	}
+	return begin, end, nil
+}
+
+// dataRaceReturnAddr returns the address of the instruction the goroutine
+// that made the access with the given stack will return to, once the race
+// detector runtime is done with the report. This is the return address of
+// the innermost frame that doesn't belong to the runtime.
+func dataRaceReturnAddr(t *Target, stack []Location) uint64 {
+	for _, loc := range stack {
+		if loc.Fn == nil || loc.Fn.privateRuntime() {
+			continue
+		}
+		// The race detector reports the address of the call instruction
+		// instead of the return address.
+		var retaddr uint64
+		switch t.BinInfo().Arch.Name {
+		case "amd64":
+			retaddr = loc.PC + 1
+		case "arm64":
+			retaddr = loc.PC + 4
+		}
+		if t.BinInfo().PCToFunc(retaddr) != loc.Fn {
+			return 0
+		}
+		return retaddr
+	}
+	return 0
+}
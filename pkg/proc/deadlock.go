@@ -0,0 +1,541 @@
+package proc
+
+import (
+	"errors"
+	"go/constant"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/goversion"
+)
+
+// WaitResourceKind is the kind of object a goroutine is blocked on.
+type WaitResourceKind uint8
+
+const (
+	WaitResourceChan WaitResourceKind = iota // a channel, Addr is the address of the runtime.hchan
+	WaitResourceSema                         // a semaphore (sync.Mutex, sync.WaitGroup, etc), Addr is the address of the semaphore
+)
+
+func (k WaitResourceKind) String() string {
+	switch k {
+	case WaitResourceChan:
+		return "chan"
+	case WaitResourceSema:
+		return "sema"
+	default:
+		return "unknown"
+	}
+}
+
+// WaitResource is a channel or semaphore a goroutine is blocked on.
+type WaitResource struct {
+	Kind WaitResourceKind
+	Addr uint64
+}
+
+// BlockedGoroutine is a goroutine that can never be woken up.
+type BlockedGoroutine struct {
+	G          *G
+	WaitReason string
+	// Resources are the channels and semaphores the goroutine is waiting
+	// on, it is empty for goroutines blocked on a nil channel or on a select
+	// statement without cases.
+	Resources []WaitResource
+	// WaitsFor lists the IDs of other goroutines that could wake this
+	// goroutine, because they reference one of its resources, all of them
+	// are also blocked forever.
+	WaitsFor []int64
+}
+
+// DeadlockReport is the result of Deadlocks.
+type DeadlockReport struct {
+	// Goroutines contains all goroutines that can never be woken up, sorted
+	// by ID.
+	Goroutines []*BlockedGoroutine
+	// Cycles lists the IDs of goroutines that are waiting on each other.
+	Cycles [][]int64
+}
+
+// deadlockLoadConfig is used to load the variables that are searched for
+// references to channels and semaphores.
+var deadlockLoadConfig = LoadConfig{FollowPointers: true, MaxVariableRecurse: 3, MaxStringLen: 0, MaxArrayValues: 64, MaxStructFields: -1}
+
+const (
+	deadlockMaxStackDepth = 64
+	deadlockMaxSudogs     = 1 << 20
+)
+
+// blockingWaitReasons are the wait reasons of goroutines that can only be
+// woken up by another goroutine.
+var blockingWaitReasons = map[string]bool{
+	"chan receive":            true,
+	"chan send":               true,
+	"chan receive (nil chan)": true,
+	"chan send (nil chan)":    true,
+	"select":                  true,
+	"select (no cases)":       true,
+	"semacquire":              true,
+	"sync.Cond.Wait":          true,
+	"sync.Mutex.Lock":         true,
+	"sync.RWMutex.RLock":      true,
+	"sync.RWMutex.Lock":       true,
+	"sync.WaitGroup.Wait":     true,
+}
+
+type waitNode struct {
+	g         *G
+	reason    string
+	blocked   bool
+	unknown   bool // blocked on something we can not identify
+	resources []WaitResource
+	refs      []addrRange
+}
+
+type addrRange struct {
+	start, end uint64
+}
+
+// Deadlocks builds a wait-for graph of the goroutines of t and returns the
+// goroutines that can never be woken up.
+//
+// A goroutine is blocked if it is waiting on a channel operation, a select
+// statement or a semaphore (sync.Mutex, sync.RWMutex, sync.WaitGroup...).
+// The channels it is waiting on are read from the sudog list in g.waiting,
+// the semaphores from the runtime semaphore table.
+// A goroutine can wake up a blocked goroutine if one of its stack frames
+// references the channel or semaphore the blocked goroutine is waiting on.
+// Resources reachable from package variables can be used by any goroutine.
+// A blocked goroutine is considered deadlocked if none of the goroutines
+// that could wake it up can make progress.
+//
+// Only references that can be reached from stack frames and package
+// variables by following a limited number of pointers are considered, the
+// result may contain goroutines that can be woken up through references
+// held exclusively by heap objects.
+func Deadlocks(t *Target) (*DeadlockReport, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	if producer := t.BinInfo().Producer(); producer != "" && !goversion.ProducerAfterOrEqual(producer, 1, 11) {
+		return nil, errors.New("deadlock detection requires Go 1.11 or later")
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := globalScope(t, t.BinInfo(), t.BinInfo().Images[0], t.Memory())
+	reasons, err := waitReasonStrings(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*waitNode, 0, len(gs))
+	gByAddr := make(map[uint64]*waitNode)
+	for _, g := range gs {
+		if g.Unreadable != nil || g.variable == nil {
+			continue
+		}
+		n := &waitNode{g: g}
+		if g.Status == Gwaiting && g.WaitReason >= 0 && g.WaitReason < int64(len(reasons)) {
+			n.reason = reasons[g.WaitReason]
+			n.blocked = blockingWaitReasons[n.reason]
+		}
+		nodes = append(nodes, n)
+		gByAddr[g.variable.Addr] = n
+	}
+
+	// Find the resources blocked goroutines are waiting on.
+	timerChans := make(map[uint64]bool)
+	for _, n := range nodes {
+		if !n.blocked {
+			continue
+		}
+		waitingChans(n, timerChans)
+	}
+	semaWaiters(scope, gByAddr)
+	for _, n := range nodes {
+		if n.blocked && len(n.resources) == 0 && !strings.HasSuffix(n.reason, "(nil chan)") && n.reason != "select (no cases)" {
+			n.unknown = true
+		}
+	}
+
+	// Find the memory referenced by each goroutine and by package variables.
+	for _, n := range nodes {
+		n.refs = goroutineRefs(t, n.g)
+	}
+	globalRefs := packageVarsRefs(scope)
+
+	wakeable := make(map[*waitNode]bool)
+	userWakeable := false
+	for _, n := range nodes {
+		if !n.blocked || n.unknown {
+			wakeable[n] = true
+			if !n.g.System(t) {
+				userWakeable = true
+			}
+		}
+	}
+
+	wakers := func(n *waitNode, res WaitResource) []*waitNode {
+		var r []*waitNode
+		for _, m := range nodes {
+			if m != n && rangesContain(m.refs, res.Addr) {
+				r = append(r, m)
+			}
+		}
+		return r
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, n := range nodes {
+			if wakeable[n] {
+				continue
+			}
+			for _, res := range n.resources {
+				if (res.Kind == WaitResourceChan && timerChans[res.Addr]) || (userWakeable && rangesContain(globalRefs, res.Addr)) {
+					wakeable[n] = true
+				}
+				for _, m := range wakers(n, res) {
+					if wakeable[m] {
+						wakeable[n] = true
+						break
+					}
+				}
+				if wakeable[n] {
+					break
+				}
+			}
+			if wakeable[n] {
+				changed = true
+				if !n.g.System(t) {
+					userWakeable = true
+				}
+			}
+		}
+	}
+
+	r := &DeadlockReport{}
+	deadlocked := make(map[*waitNode]*BlockedGoroutine)
+	for _, n := range nodes {
+		if wakeable[n] {
+			continue
+		}
+		bg := &BlockedGoroutine{G: n.g, WaitReason: n.reason, Resources: n.resources}
+		deadlocked[n] = bg
+		r.Goroutines = append(r.Goroutines, bg)
+	}
+
+	edges := make(map[*waitNode][]*waitNode)
+	for n, bg := range deadlocked {
+		for _, res := range n.resources {
+			for _, m := range wakers(n, res) {
+				if !slices.Contains(edges[n], m) {
+					edges[n] = append(edges[n], m)
+					bg.WaitsFor = append(bg.WaitsFor, m.g.ID)
+				}
+			}
+		}
+		slices.Sort(bg.WaitsFor)
+	}
+	sort.Slice(r.Goroutines, func(i, j int) bool { return r.Goroutines[i].G.ID < r.Goroutines[j].G.ID })
+
+	var ordered []*waitNode
+	for _, bg := range r.Goroutines {
+		ordered = append(ordered, gByAddr[bg.G.variable.Addr])
+	}
+	for _, scc := range stronglyConnected(ordered, edges) {
+		if len(scc) < 2 {
+			continue
+		}
+		cycle := make([]int64, len(scc))
+		for i := range scc {
+			cycle[i] = scc[i].g.ID
+		}
+		slices.Sort(cycle)
+		r.Cycles = append(r.Cycles, cycle)
+	}
+	sort.Slice(r.Cycles, func(i, j int) bool { return r.Cycles[i][0] < r.Cycles[j][0] })
+
+	return r, nil
+}
+
+// waitReasonStrings reads runtime.waitReasonStrings, the values of
+// waitReason change between versions of Go.
+func waitReasonStrings(scope *EvalScope) ([]string, error) {
+	// +rtype -var waitReasonStrings anytype
+	v, err := scope.findGlobal("runtime", "waitReasonStrings")
+	if err != nil {
+		return nil, err
+	}
+	v.loadValue(LoadConfig{MaxStringLen: 64, MaxArrayValues: 256})
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	r := make([]string, len(v.Children))
+	for i := range v.Children {
+		if v.Children[i].Value != nil {
+			r[i] = constant.StringVal(v.Children[i].Value)
+		}
+	}
+	return r, nil
+}
+
+// waitingChans adds the channels n is waiting on, read from the list of
+// sudogs in g.waiting, to n.resources.
+func waitingChans(n *waitNode, timerChans map[uint64]bool) {
+	// +rtype -field g.waiting *sudog
+	// +rtype -field sudog.c *hchan|maybeTraceableChan
+	// +rtype -field sudog.waitlink *sudog
+	waiting, err := n.g.variable.structMember("waiting")
+	if err != nil {
+		return
+	}
+	sg := waiting.maybeDereference()
+	for i := 0; sg.Addr != 0 && sg.Unreadable == nil && i < deadlockMaxSudogs; i++ {
+		c, err := sudogPtrField(sg, "c")
+		if err == nil && c != 0 {
+			n.resources = append(n.resources, WaitResource{Kind: WaitResourceChan, Addr: c})
+			if hchanHasTimer(sg, c) {
+				timerChans[c] = true
+			}
+		}
+		next, err := sg.structMember("waitlink")
+		if err != nil {
+			return
+		}
+		sg = next.maybeDereference()
+	}
+}
+
+// hchanHasTimer returns true if the channel at address c is fed by a timer
+// (for example a channel returned by time.After).
+func hchanHasTimer(sg *Variable, c uint64) bool {
+	hchanType, err := sg.bi.findType("runtime.hchan")
+	if err != nil {
+		return false
+	}
+	// +rtype go1.23 -field hchan.timer *timer
+	timer, err := newVariable("", c, hchanType, sg.bi, sg.mem).structMember("timer")
+	if err != nil {
+		return false
+	}
+	addr, err := readUintRaw(timer.mem, timer.Addr, timer.RealType.Size())
+	return err == nil && addr != 0
+}
+
+// semaWaiters walks the runtime semaphore table and adds the semaphore
+// each goroutine is waiting on to its resources.
+func semaWaiters(scope *EvalScope, gByAddr map[uint64]*waitNode) {
+	// +rtype -var semtable semTable
+	// +rtype -field semaRoot.treap *sudog
+	// +rtype -field sudog.g *g
+	// +rtype -field sudog.elem unsafe.Pointer|maybeTraceablePtr
+	// +rtype -field sudog.prev *sudog
+	// +rtype -field sudog.next *sudog
+	semtable, err := scope.findGlobal("runtime", "semtable")
+	if err != nil || semtable == nil || semtable.Kind != reflect.Array {
+		return
+	}
+	count := 0
+	for i := int64(0); i < semtable.Len; i++ {
+		entry, err := semtable.sliceAccess(int(i))
+		if err != nil {
+			return
+		}
+		treap := structMemberMulti(entry, "root", "treap")
+		if treap == nil {
+			continue
+		}
+		// The treap contains one node for each address, prev and next are the
+		// children of the node, other sudogs waiting on the same address are
+		// linked through waitlink.
+		queue := []*Variable{treap.maybeDereference()}
+		for len(queue) > 0 && count < deadlockMaxSudogs {
+			node := queue[0]
+			queue = queue[1:]
+			if node.Addr == 0 || node.Unreadable != nil {
+				continue
+			}
+			elem, _ := sudogPtrField(node, "elem")
+			for sg := node; sg.Addr != 0 && sg.Unreadable == nil && count < deadlockMaxSudogs; count++ {
+				if g, err := sudogPtrField(sg, "g"); err == nil && gByAddr[g] != nil && gByAddr[g].blocked {
+					n := gByAddr[g]
+					n.resources = append(n.resources, WaitResource{Kind: WaitResourceSema, Addr: elem})
+				}
+				next, err := sg.structMember("waitlink")
+				if err != nil {
+					break
+				}
+				sg = next.maybeDereference()
+			}
+			for _, child := range []string{"prev", "next"} {
+				if cv, err := node.structMember(child); err == nil {
+					queue = append(queue, cv.maybeDereference())
+				}
+			}
+		}
+	}
+}
+
+// sudogPtrField reads a pointer field of a sudog, depending on the version
+// of Go pointer fields of sudog can be wrapped by maybeTraceablePtr.
+func sudogPtrField(sg *Variable, name string) (uint64, error) {
+	v, err := sg.structMember(name)
+	if err != nil {
+		return 0, err
+	}
+	if v.Kind == reflect.Struct {
+		// +rtype go1.25 -field maybeTraceablePtr.vu uintptr
+		v, err = v.structMember("vu")
+		if err != nil {
+			return 0, err
+		}
+	}
+	return readUintRaw(v.mem, v.Addr, v.RealType.Size())
+}
+
+// goroutineRefs returns the memory ranges reachable from the stack frames
+// of g.
+func goroutineRefs(t *Target, g *G) []addrRange {
+	frames, err := GoroutineStacktrace(t, g, deadlockMaxStackDepth, 0)
+	if err != nil {
+		return nil
+	}
+	threadID := 0
+	if g.Thread != nil {
+		threadID = g.Thread.ThreadID()
+	}
+	var refs []addrRange
+	for i := range frames {
+		if frames[i].Current.Fn == nil {
+			continue
+		}
+		scope := FrameToScope(t, t.Memory(), g, threadID, frames[i:]...)
+		vars, err := scope.Locals(0, "")
+		if err != nil {
+			continue
+		}
+		loadValues(vars, deadlockLoadConfig)
+		for _, v := range vars {
+			refs = variableRefs(v, refs)
+		}
+	}
+	return mergeRanges(refs)
+}
+
+// packageVarsRefs returns the memory ranges reachable from package
+// variables, variables of the runtime and internal packages are skipped.
+func packageVarsRefs(scope *EvalScope) []addrRange {
+	vars, err := scope.packageVariables(deadlockLoadConfig, func(name string) bool {
+		return !strings.HasPrefix(name, "runtime.") && !strings.HasPrefix(name, "internal/")
+	})
+	if err != nil {
+		return nil
+	}
+	var refs []addrRange
+	for _, v := range vars {
+		refs = variableRefs(v, refs)
+	}
+	return mergeRanges(refs)
+}
+
+// variableRefs appends to refs the memory occupied by v and by everything
+// loaded as a child of v.
+func variableRefs(v *Variable, refs []addrRange) []addrRange {
+	if v.Unreadable != nil {
+		return refs
+	}
+	if v.Addr != 0 && v.Flags&VariableFakeAddress == 0 && v.RealType != nil && v.RealType.Size() > 0 {
+		refs = append(refs, addrRange{v.Addr, v.Addr + uint64(v.RealType.Size())})
+	}
+	if v.Kind == reflect.Chan && v.Base != 0 {
+		refs = append(refs, addrRange{v.Base, v.Base + 1})
+	}
+	for i := range v.Children {
+		refs = variableRefs(&v.Children[i], refs)
+	}
+	return refs
+}
+
+func mergeRanges(refs []addrRange) []addrRange {
+	if len(refs) == 0 {
+		return nil
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].start < refs[j].start })
+	r := refs[:1]
+	for _, rng := range refs[1:] {
+		last := &r[len(r)-1]
+		if rng.start <= last.end {
+			last.end = max(last.end, rng.end)
+		} else {
+			r = append(r, rng)
+		}
+	}
+	return r
+}
+
+func rangesContain(refs []addrRange, addr uint64) bool {
+	i := sort.Search(len(refs), func(i int) bool { return refs[i].end > addr })
+	return i < len(refs) && refs[i].start <= addr
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// described by nodes and edges, using Tarjan's algorithm.
+func stronglyConnected(nodes []*waitNode, edges map[*waitNode][]*waitNode) [][]*waitNode {
+	var (
+		index   = make(map[*waitNode]int)
+		lowlink = make(map[*waitNode]int)
+		onStack = make(map[*waitNode]bool)
+		stack   []*waitNode
+		r       [][]*waitNode
+	)
+	inGraph := make(map[*waitNode]bool, len(nodes))
+	for _, n := range nodes {
+		inGraph[n] = true
+	}
+
+	var visit func(n *waitNode)
+	visit = func(n *waitNode) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+		for _, m := range edges[n] {
+			if !inGraph[m] {
+				continue
+			}
+			if _, visited := index[m]; !visited {
+				visit(m)
+				lowlink[n] = min(lowlink[n], lowlink[m])
+			} else if onStack[m] {
+				lowlink[n] = min(lowlink[n], index[m])
+			}
+		}
+		if lowlink[n] == index[n] {
+			var scc []*waitNode
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				scc = append(scc, m)
+				if m == n {
+					break
+				}
+			}
+			r = append(r, scc)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, visited := index[n]; !visited {
+			visit(n)
+		}
+	}
+	return r
+}
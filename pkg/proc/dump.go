@@ -6,7 +6,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"sort"
 	"sync"
 
 	"github.com/go-delve/delve/pkg/elfwriter"
@@ -30,6 +33,11 @@ type DumpState struct {
 	MemDone, MemTotal         uint64
 
 	Err error
+
+	// SnapshotDone, if set, is called by a dump started with the
+	// DumpSnapshot flag once the contents of the target have been copied and
+	// the target is no longer accessed.
+	SnapshotDone func()
 }
 
 // DumpFlags is used to configure (*Target).Dump
@@ -37,8 +45,19 @@ type DumpFlags uint16
 
 const (
 	DumpPlatformIndependent DumpFlags = 1 << iota // always use platform-independent notes format
+	DumpSnapshot                                  // copy the memory of the target before writing the core file, see DumpState.SnapshotDone
+	DumpExcludeFileBacked                         // do not write read-only mappings backed by a file
+	DumpHeapInUse                                 // only write the Go heap spans that are in use and the goroutine stacks of Go heap arenas
 )
 
+// DumpOptions is used to configure (*Target).Dump
+type DumpOptions struct {
+	Flags DumpFlags
+	// MaxMappingSize, if not 0, is the maximum number of bytes written for
+	// each memory mapping.
+	MaxMappingSize uint64
+}
+
 // MemoryMapEntry represent a memory mapping in the target process.
 type MemoryMapEntry struct {
 	Addr uint64
@@ -93,7 +112,8 @@ func (state *DumpState) isCanceled() bool {
 }
 
 // Dump writes a core dump to out. State is updated as the core dump is written.
-func (t *Target) Dump(out elfwriter.WriteCloserSeeker, flags DumpFlags, state *DumpState) {
+func (t *Target) Dump(out elfwriter.WriteCloserSeeker, opts DumpOptions, state *DumpState) {
+	flags := opts.Flags
 	defer func() {
 		state.Mutex.Lock()
 		if ierr := recover(); ierr != nil {
@@ -198,17 +218,85 @@ func (t *Target) Dump(out elfwriter.WriteCloserSeeker, flags DumpFlags, state *D
 	}
 
 	memmapFilter := make([]MemoryMapEntry, 0, len(memmap))
-	memtot := uint64(0)
 	for i := range memmap {
 		mme := &memmap[i]
-		if t.shouldDumpMemory(mme) {
-			memmapFilter = append(memmapFilter, *mme)
-			memtot += mme.Size
+		if !t.shouldDumpMemory(mme) {
+			continue
 		}
+		if flags&DumpExcludeFileBacked != 0 && mme.Filename != "" && !mme.Write {
+			continue
+		}
+		if opts.MaxMappingSize > 0 && mme.Size > opts.MaxMappingSize {
+			mme.Size = opts.MaxMappingSize
+		}
+		memmapFilter = append(memmapFilter, *mme)
+	}
+	if flags&DumpHeapInUse != 0 {
+		memmapFilter = t.filterHeapInUse(memmapFilter)
+	}
+	memtot := uint64(0)
+	for i := range memmapFilter {
+		memtot += memmapFilter[i].Size
 	}
 
 	state.setMemTotal(memtot)
 
+	if flags&DumpSnapshot != 0 {
+		// Copy all the memory first so that the target can be resumed before
+		// we start writing to out. The copy is written to a temporary file,
+		// it can be as big as the core file.
+		snapshot, err := os.CreateTemp("", "dlv-dump-")
+		if err != nil {
+			state.setErr(fmt.Errorf("could not create snapshot file: %v", err))
+			return
+		}
+		defer func() {
+			snapshot.Close()
+			os.Remove(snapshot.Name())
+		}()
+		buf := make([]byte, 1024*1024)
+		for i := range memmapFilter {
+			addr, sz := memmapFilter[i].Addr, memmapFilter[i].Size
+			for sz > 0 {
+				if state.isCanceled() {
+					return
+				}
+				chunk := buf[:min(uint64(len(buf)), sz)]
+				t.readMemoryForDump(state, chunk, addr)
+				if _, err := snapshot.Write(chunk); err != nil {
+					state.setErr(fmt.Errorf("error writing to snapshot file: %v", err))
+					return
+				}
+				addr += uint64(len(chunk))
+				sz -= uint64(len(chunk))
+			}
+		}
+		if state.SnapshotDone != nil {
+			state.SnapshotDone()
+		}
+		if _, err := snapshot.Seek(0, io.SeekStart); err != nil {
+			state.setErr(fmt.Errorf("error reading snapshot file: %v", err))
+			return
+		}
+		for i := range memmapFilter {
+			dumpProgHeader(w, &memmapFilter[i])
+			for sz := memmapFilter[i].Size; sz > 0; {
+				if w.Err != nil {
+					state.setErr(fmt.Errorf("error writing to output file: %v", w.Err))
+					return
+				}
+				chunk := buf[:min(uint64(len(buf)), sz)]
+				if _, err := io.ReadFull(snapshot, chunk); err != nil {
+					state.setErr(fmt.Errorf("error reading snapshot file: %v", err))
+					return
+				}
+				w.Write(chunk)
+				sz -= uint64(len(chunk))
+			}
+		}
+		memmapFilter = nil
+	}
+
 	for i := range memmapFilter {
 		mme := &memmapFilter[i]
 		if w.Err != nil {
@@ -294,7 +382,9 @@ func (t *Target) dumpThreadNotes(notes []elfwriter.Note, state *DumpState, th Th
 	})
 }
 
-func (t *Target) dumpMemory(state *DumpState, w *elfwriter.Writer, mme *MemoryMapEntry) {
+// dumpProgHeader adds the program header for the memory mapping mme, whose
+// contents will be written at the current position of w.
+func dumpProgHeader(w *elfwriter.Writer, mme *MemoryMapEntry) {
 	var flags elf.ProgFlag
 	if mme.Read {
 		flags |= elf.PF_R
@@ -316,11 +406,14 @@ func (t *Target) dumpMemory(state *DumpState, w *elfwriter.Writer, mme *MemoryMa
 		Memsz:  mme.Size,
 		Align:  0,
 	})
+}
+
+func (t *Target) dumpMemory(state *DumpState, w *elfwriter.Writer, mme *MemoryMapEntry) {
+	dumpProgHeader(w, mme)
 
 	buf := make([]byte, 1024*1024)
 	addr := mme.Addr
 	sz := mme.Size
-	mem := t.Memory()
 
 	for sz > 0 {
 		if w.Err != nil {
@@ -334,6 +427,19 @@ func (t *Target) dumpMemory(state *DumpState, w *elfwriter.Writer, mme *MemoryMa
 		if uint64(len(chunk)) > sz {
 			chunk = chunk[:sz]
 		}
+		t.readMemoryForDump(state, chunk, addr)
+		w.Write(chunk)
+		addr += uint64(len(chunk))
+		sz -= uint64(len(chunk))
+	}
+}
+
+// readMemoryForDump fills buf with the memory at addr, in chunks of at most
+// 1MB, zeroing the parts that can not be read.
+func (t *Target) readMemoryForDump(state *DumpState, buf []byte, addr uint64) {
+	mem := t.Memory()
+	for len(buf) > 0 {
+		chunk := buf[:min(len(buf), 1024*1024)]
 		n, err := mem.ReadMemory(chunk, addr)
 		for i := n; i < len(chunk); i++ {
 			chunk[i] = 0
@@ -342,13 +448,58 @@ func (t *Target) dumpMemory(state *DumpState, w *elfwriter.Writer, mme *MemoryMa
 		// (*ProcessInternal).MemoryMap gave us a bad mapping that can't be read
 		// and the behavior that's maximally useful to the user is to generate an
 		// incomplete dump.
-		w.Write(chunk)
-		addr += uint64(len(chunk))
-		sz -= uint64(len(chunk))
 		if err == nil {
 			state.memDone(uint64(len(chunk)))
 		}
+		buf = buf[len(chunk):]
+		addr += uint64(len(chunk))
+	}
+}
+
+// filterHeapInUse removes from memmap the parts of the Go heap arenas that
+// are neither in use heap spans nor goroutine stacks.
+func (t *Target) filterHeapInUse(memmap []MemoryMapEntry) []MemoryMapEntry {
+	h, err := t.loadHeap(false)
+	if err != nil || len(h.spans) == 0 {
+		return memmap
+	}
+
+	keep := make([]addrRange, 0, len(h.spans))
+	for _, s := range h.spans {
+		keep = append(keep, addrRange{s.base, s.limit})
+	}
+	if gs, _, err := GoroutinesInfo(t, 0, 0); err == nil {
+		for _, g := range gs {
+			if g.stack.hi > g.stack.lo {
+				keep = append(keep, addrRange{g.stack.lo, g.stack.hi})
+			}
+		}
 	}
+	merged := mergeRanges(keep)
+
+	r := make([]MemoryMapEntry, 0, len(memmap))
+	for _, mme := range memmap {
+		end := mme.Addr + mme.Size
+		i := sort.Search(len(h.spans), func(i int) bool { return h.spans[i].limit > mme.Addr })
+		if i >= len(h.spans) || h.spans[i].base >= end {
+			// not a heap arena
+			r = append(r, mme)
+			continue
+		}
+		for _, k := range merged {
+			start, kend := max(k.start, mme.Addr), min(k.end, end)
+			if start >= kend {
+				continue
+			}
+			part := mme
+			part.Addr, part.Size = start, kend-start
+			if part.Filename != "" {
+				part.Offset += start - mme.Addr
+			}
+			r = append(r, part)
+		}
+	}
+	return r
 }
 
 func (t *Target) shouldDumpMemory(mme *MemoryMapEntry) bool {
@@ -250,74 +250,53 @@ func (scope *EvalScope) ChanGoroutines(expr string, start, count int) ([]int64,
 		return nil, nil
 	}
 
-	structMemberMulti := func(v *Variable, names ...string) *Variable {
-		for _, name := range names {
-			var err error
-			v, err = v.structMember(name)
-			if err != nil {
-				return nil
-			}
-		}
-		return v
-	}
-
-	waitqFirst := func(qname string) *Variable {
-		qvar := structMemberMulti(v, qname, "first")
-		if qvar == nil {
-			return nil
-		}
-		return qvar.maybeDereference()
-	}
-
 	var goids []int64
 
-	waitqToGoIDSlice := func(qvar *Variable) error {
-		if qvar == nil {
-			return nil
+	waitqToGoIDSlice := func(sg *Variable) (bool, error) {
+		if len(goids) > count {
+			return false, nil
 		}
-		for {
-			if qvar.Addr == 0 {
-				return nil
-			}
-			if len(goids) > count {
-				return nil
-			}
-			goidVar := structMemberMulti(qvar, "g", "goid")
-			if goidVar == nil {
-				return nil
-			}
-			goidVar.loadValue(loadSingleValue)
-			if goidVar.Unreadable != nil {
-				return goidVar.Unreadable
-			}
-			goid, _ := constant.Int64Val(goidVar.Value)
-			if start > 0 {
-				start--
-			} else {
-				goids = append(goids, goid)
-			}
-
-			nextVar, err := qvar.structMember("next")
-			if err != nil {
-				return err
-			}
-			qvar = nextVar.maybeDereference()
+		goidVar := structMemberMulti(sg, "g", "goid")
+		if goidVar == nil {
+			return false, nil
+		}
+		goidVar.loadValue(loadSingleValue)
+		if goidVar.Unreadable != nil {
+			return false, goidVar.Unreadable
+		}
+		goid, _ := constant.Int64Val(goidVar.Value)
+		if start > 0 {
+			start--
+		} else {
+			goids = append(goids, goid)
 		}
+		return true, nil
 	}
 
-	recvqVar := waitqFirst("recvq")
-	err = waitqToGoIDSlice(recvqVar)
+	err = walkChanWaitq(v, "recvq", waitqToGoIDSlice)
 	if err != nil {
 		return nil, err
 	}
-	sendqVar := waitqFirst("sendq")
-	err = waitqToGoIDSlice(sendqVar)
+	err = walkChanWaitq(v, "sendq", waitqToGoIDSlice)
 	if err != nil {
 		return nil, err
 	}
 	return goids, nil
 }
 
+// structMemberMulti returns the field of v identified by the sequence of
+// names, or nil if any of them can not be found.
+func structMemberMulti(v *Variable, names ...string) *Variable {
+	for _, name := range names {
+		var err error
+		v, err = v.structMember(name)
+		if err != nil {
+			return nil
+		}
+	}
+	return v
+}
+
 // Locals returns all variables in 'scope' named wantedName, or all of them
 // if wantedName is "".
 // If scope is the scope for a range-over-func closure body it will merge in
@@ -750,15 +729,25 @@ func regsReplaceStaticBase(regs op.DwarfRegisters, image *Image) op.DwarfRegiste
 
 // PackageVariables returns the name, value, and type of all package variables in the application.
 func (scope *EvalScope) PackageVariables(cfg LoadConfig) ([]*Variable, error) {
-	pkgvars := make([]packageVar, len(scope.BinInfo.packageVars))
-	copy(pkgvars, scope.BinInfo.packageVars)
+	return scope.packageVariables(cfg, nil)
+}
+
+// packageVariables returns the package variables whose name is accepted by
+// include, or all of them if include is nil.
+func (scope *EvalScope) packageVariables(cfg LoadConfig, include func(name string) bool) ([]*Variable, error) {
+	pkgvars := make([]packageVar, 0, len(scope.BinInfo.packageVars))
+	for _, pkgvar := range scope.BinInfo.packageVars {
+		if include == nil || include(pkgvar.name) {
+			pkgvars = append(pkgvars, pkgvar)
+		}
+	}
 	sort.Slice(pkgvars, func(i, j int) bool {
 		if pkgvars[i].cu.image.addr == pkgvars[j].cu.image.addr {
 			return pkgvars[i].offset < pkgvars[j].offset
 		}
 		return pkgvars[i].cu.image.addr < pkgvars[j].cu.image.addr
 	})
-	vars := make([]*Variable, 0, len(scope.BinInfo.packageVars))
+	vars := make([]*Variable, 0, len(pkgvars))
 	for _, pkgvar := range pkgvars {
 		reader := pkgvar.cu.image.dwarfReader
 		reader.Seek(pkgvar.offset)
@@ -0,0 +1,403 @@
+package proc
+
+import (
+	"fmt"
+	"go/constant"
+	"net"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// FileDescriptor is a file descriptor of the target process, see
+// FileDescriptors.
+type FileDescriptor struct {
+	FD int
+	// Path is the file the descriptor refers to, as reported by the
+	// operating system (for example "socket:[1234]" or "/dev/null" on
+	// linux). It is only available for live processes.
+	Path string
+	// Socket describes the socket the descriptor refers to, if known to
+	// the operating system.
+	Socket *Socket
+
+	// Name is the name of the os.File that owns the descriptor.
+	Name string
+	// Net, LocalAddr and RemoteAddr are read from the net.netFD that owns
+	// the descriptor.
+	Net        string
+	LocalAddr  string
+	RemoteAddr string
+
+	// PollDesc is the address of the runtime.pollDesc used to register the
+	// descriptor with the netpoller, or 0.
+	PollDesc uint64
+	// Closing is true if the descriptor is being closed.
+	Closing bool
+	// ReadG and WriteG are the IDs of the goroutines parked in the
+	// netpoller waiting for the descriptor to become readable or writable,
+	// or 0.
+	ReadG  int64
+	WriteG int64
+
+	// Refs lists the variables that hold the descriptor.
+	Refs []FileDescriptorRef
+}
+
+// Socket is a socket as described by the operating system.
+type Socket struct {
+	// Proto is the protocol of the socket, for example "tcp", "tcp6",
+	// "udp" or "unix".
+	Proto      string
+	Inode      uint64
+	LocalAddr  string
+	RemoteAddr string
+	// State is the state of TCP sockets (for example "LISTEN" or
+	// "ESTABLISHED").
+	State string
+}
+
+// FileDescriptorRef is a variable that holds a file descriptor, directly
+// or through pointers.
+type FileDescriptorRef struct {
+	// GoroutineID is the ID of the goroutine whose stack contains the
+	// variable, 0 for package variables.
+	GoroutineID int64
+	// Frame is the index of the stack frame that contains the variable.
+	Frame    int
+	Function string
+	Name     string
+	Type     string
+}
+
+// FileDescriptorLister is implemented by processes that can list the file
+// descriptors opened by the target, for example live processes on linux.
+type FileDescriptorLister interface {
+	// FileDescriptors returns the file descriptors opened by the target,
+	// only the FD, Path and Socket fields are filled.
+	FileDescriptors() ([]FileDescriptor, error)
+}
+
+const (
+	fdMaxStackDepth = 64   // maximum number of frames of each goroutine that are searched for file descriptors
+	fdScanMaxDepth  = 8    // maximum nesting depth of file descriptors inside variables
+	fdScanMaxNodes  = 1000 // maximum number of values visited in each variable
+)
+
+// fdInternalPackages are the packages implementing files and sockets.
+var fdInternalPackages = map[string]bool{"runtime": true, "syscall": true, "os": true, "net": true}
+
+// goFD is a file descriptor found in a variable of the target.
+type goFD struct {
+	sysfd    int
+	pollDesc uint64
+	name     string
+	net      string
+	laddr    string
+	raddr    string
+}
+
+// FileDescriptors returns the file descriptors of the target, sorted by
+// number.
+// If the target process implements FileDescriptorLister all its
+// descriptors are returned, otherwise (for example for core files) only
+// the descriptors that can be found by searching stack frames and package
+// variables for values of type os.File, net.netFD and internal/poll.FD.
+// Each descriptor is associated to the runtime.pollDesc registered with
+// the netpoller and to the goroutines parked on it.
+func FileDescriptors(t *Target) ([]FileDescriptor, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+
+	fds := make(map[int]*FileDescriptor)
+	get := func(n int) *FileDescriptor {
+		if fds[n] == nil {
+			fds[n] = &FileDescriptor{FD: n}
+		}
+		return fds[n]
+	}
+
+	if lister, ok := t.proc.(FileDescriptorLister); ok {
+		osfds, err := lister.FileDescriptors()
+		if err != nil {
+			return nil, err
+		}
+		for i := range osfds {
+			fds[osfds[i].FD] = &osfds[i]
+		}
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	gByAddr := make(map[uint64]*G)
+	for _, g := range gs {
+		if g.variable != nil && g.Unreadable == nil {
+			gByAddr[g.variable.Addr] = g
+		}
+	}
+
+	addGoFD := func(gfd goFD, ref *FileDescriptorRef) {
+		fd := get(gfd.sysfd)
+		if gfd.pollDesc != 0 {
+			fd.PollDesc = gfd.pollDesc
+		}
+		if gfd.name != "" {
+			fd.Name = gfd.name
+		}
+		if gfd.net != "" {
+			fd.Net, fd.LocalAddr, fd.RemoteAddr = gfd.net, gfd.laddr, gfd.raddr
+		}
+		if ref != nil && !slices.Contains(fd.Refs, *ref) {
+			fd.Refs = append(fd.Refs, *ref)
+		}
+	}
+
+	for _, g := range gs {
+		if g.variable == nil || g.Unreadable != nil {
+			continue
+		}
+		frames, err := GoroutineStacktrace(t, g, fdMaxStackDepth, 0)
+		if err != nil {
+			continue
+		}
+		threadID := 0
+		if g.Thread != nil {
+			threadID = g.Thread.ThreadID()
+		}
+		for i := range frames {
+			fn := frames[i].Current.Fn
+			if fn == nil {
+				continue
+			}
+			scope := FrameToScope(t, t.Memory(), g, threadID, frames[i:]...)
+			vars, err := scope.Locals(0, "")
+			if err != nil {
+				continue
+			}
+			// Variables of the packages that implement files and sockets
+			// are only used to find descriptors, they are not reported as
+			// references.
+			internal := fdInternalPackages[fn.PackageName()] || strings.HasPrefix(fn.PackageName(), "internal/")
+			for _, v := range vars {
+				var ref *FileDescriptorRef
+				if !internal && !strings.HasPrefix(v.Name, ".") {
+					ref = &FileDescriptorRef{GoroutineID: g.ID, Frame: i, Function: fn.Name, Name: v.Name, Type: v.TypeString()}
+				}
+				for _, gfd := range findGoFDs(v) {
+					addGoFD(gfd, ref)
+				}
+			}
+		}
+	}
+
+	scope := globalScope(t, t.BinInfo(), t.BinInfo().Images[0], t.Memory())
+	pkgvars, err := scope.packageVariables(LoadConfig{}, func(name string) bool {
+		return !strings.HasPrefix(name, "runtime.") && !strings.HasPrefix(name, "internal/")
+	})
+	if err == nil {
+		for _, v := range pkgvars {
+			ref := &FileDescriptorRef{Name: v.Name, Type: v.TypeString()}
+			for _, gfd := range findGoFDs(v) {
+				addGoFD(gfd, ref)
+			}
+		}
+	}
+
+	r := make([]FileDescriptor, 0, len(fds))
+	for _, fd := range fds {
+		if fd.PollDesc != 0 {
+			readPollDesc(t, fd, gByAddr)
+		}
+		r = append(r, *fd)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].FD < r[j].FD })
+	return r, nil
+}
+
+// readPollDesc fills the fields of fd read from its runtime.pollDesc. If
+// the pollDesc has been reused for a different descriptor fd.PollDesc is
+// set to 0.
+func readPollDesc(t *Target, fd *FileDescriptor, gByAddr map[uint64]*G) {
+	// +rtype -field pollDesc.fd uintptr
+	// +rtype -field pollDesc.closing bool
+	// +rtype -field pollDesc.rg uintptr|internal/runtime/atomic.Uintptr
+	// +rtype -field pollDesc.wg uintptr|internal/runtime/atomic.Uintptr
+	typ, err := t.BinInfo().findType("runtime.pollDesc")
+	if err != nil {
+		return
+	}
+	pd := newVariable("", fd.PollDesc, typ, t.BinInfo(), t.Memory())
+	if n, ok := schedUint(pd, "fd"); !ok || int(n) != fd.FD {
+		fd.PollDesc = 0
+		return
+	}
+	closing, _ := schedUint(pd, "closing")
+	fd.Closing = closing != 0
+	// rg and wg contain either a pointer to a g or one of pdNil, pdReady
+	// and pdWait, which are never valid g addresses.
+	if rg, _ := schedUint(pd, "rg"); gByAddr[rg] != nil {
+		fd.ReadG = gByAddr[rg].ID
+	}
+	if wg, _ := schedUint(pd, "wg"); gByAddr[wg] != nil {
+		fd.WriteG = gByAddr[wg].ID
+	}
+}
+
+// findGoFDs returns the file descriptors contained in v, following
+// pointers and interfaces.
+func findGoFDs(v *Variable) []goFD {
+	s := &fdScanner{visited: make(map[uint64]bool)}
+	s.scan(v, 0)
+	return s.found
+}
+
+type fdScanner struct {
+	visited map[uint64]bool
+	nodes   int
+	found   []goFD
+}
+
+func (s *fdScanner) scan(v *Variable, depth int) {
+	if v == nil || v.Unreadable != nil || v.Addr == 0 || depth > fdScanMaxDepth || s.nodes >= fdScanMaxNodes {
+		return
+	}
+	s.nodes++
+	switch typ := v.RealType.(type) {
+	case *godwarf.StructType:
+		switch typ.StructName {
+		case "internal/poll.FD":
+			if gfd, ok := pollFD(v); ok {
+				s.found = append(s.found, gfd)
+			}
+			return
+		case "os.file":
+			pfd, err := v.structMember("pfd")
+			if err != nil {
+				return
+			}
+			if gfd, ok := pollFD(pfd); ok {
+				if name := v.loadFieldNamed("name"); name != nil && name.Value != nil {
+					gfd.name = constant.StringVal(name.Value)
+				}
+				s.found = append(s.found, gfd)
+			}
+			return
+		case "net.netFD":
+			pfd, err := v.structMember("pfd")
+			if err != nil {
+				return
+			}
+			if gfd, ok := pollFD(pfd); ok {
+				if nw := v.loadFieldNamed("net"); nw != nil && nw.Value != nil {
+					gfd.net = constant.StringVal(nw.Value)
+				}
+				gfd.laddr = netAddrString(v, "laddr")
+				gfd.raddr = netAddrString(v, "raddr")
+				s.found = append(s.found, gfd)
+			}
+			return
+		}
+		for _, field := range typ.Field {
+			fv, err := v.toField(field)
+			if err != nil {
+				continue
+			}
+			s.scan(fv, depth+1)
+		}
+	case *godwarf.PtrType:
+		if _, isstruct := godwarf.ResolveTypedef(typ.Type).(*godwarf.StructType); !isstruct {
+			return
+		}
+		ptr, err := readUintRaw(v.mem, v.Addr, typ.ByteSize)
+		if err != nil || ptr == 0 || s.visited[ptr] {
+			return
+		}
+		s.visited[ptr] = true
+		s.scan(v.maybeDereference(), depth+1)
+	case *godwarf.InterfaceType:
+		v.loadInterface(0, false, LoadConfig{})
+		if v.Unreadable == nil && len(v.Children) > 0 {
+			s.scan(&v.Children[0], depth+1)
+		}
+	}
+}
+
+// pollFD reads the internal/poll.FD struct v. Returns false if the
+// descriptor has been closed.
+func pollFD(v *Variable) (goFD, bool) {
+	sysfd, ok := schedUint(v, "Sysfd")
+	if !ok || int(sysfd) < 0 {
+		return goFD{}, false
+	}
+	gfd := goFD{sysfd: int(sysfd)}
+	gfd.pollDesc, _ = schedUint(v, "pd", "runtimeCtx")
+	return gfd, true
+}
+
+// netAddrString returns the string representation of the net.Addr stored
+// in the field of netfd with the specified name.
+func netAddrString(netfd *Variable, field string) string {
+	v, err := netfd.structMember(field)
+	if err != nil || v.Kind != reflect.Interface {
+		return ""
+	}
+	v.loadInterface(0, false, LoadConfig{})
+	if v.Unreadable != nil || len(v.Children) == 0 {
+		return ""
+	}
+	addr := v.Children[0].maybeDereference()
+	if addr.Addr == 0 || addr.Unreadable != nil {
+		return ""
+	}
+	addr.loadValue(LoadConfig{MaxVariableRecurse: 1, MaxStringLen: 256, MaxArrayValues: net.IPv6len, MaxStructFields: -1})
+	if addr.Unreadable != nil {
+		return ""
+	}
+	str := func(name string) string {
+		if f := addr.fieldVariable(name); f != nil && f.Value != nil && f.Value.Kind() == constant.String {
+			return constant.StringVal(f.Value)
+		}
+		return ""
+	}
+	integer := func(name string) int {
+		if f := addr.fieldVariable(name); f != nil && f.Value != nil {
+			n, _ := constant.Int64Val(f.Value)
+			return int(n)
+		}
+		return 0
+	}
+	ip := func() net.IP {
+		f := addr.fieldVariable("IP")
+		if f == nil {
+			return nil
+		}
+		r := make(net.IP, 0, len(f.Children))
+		for i := range f.Children {
+			n, _ := constant.Int64Val(f.Children[i].Value)
+			r = append(r, byte(n))
+		}
+		return r
+	}
+	typ, ok := addr.RealType.(*godwarf.StructType)
+	if !ok {
+		return ""
+	}
+	switch typ.StructName {
+	case "net.TCPAddr":
+		return (&net.TCPAddr{IP: ip(), Port: integer("Port"), Zone: str("Zone")}).String()
+	case "net.UDPAddr":
+		return (&net.UDPAddr{IP: ip(), Port: integer("Port"), Zone: str("Zone")}).String()
+	case "net.IPAddr":
+		return (&net.IPAddr{IP: ip(), Zone: str("Zone")}).String()
+	case "net.UnixAddr":
+		return str("Name")
+	default:
+		return fmt.Sprintf("(%s)", addr.TypeString())
+	}
+}
@@ -0,0 +1,167 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"errors"
+	"sort"
+)
+
+// findChunkSize is the size of the blocks of memory read by FindMemory.
+const findChunkSize = 1 << 20
+
+// MemoryMatch is an occurrence of the pattern searched by FindMemory.
+type MemoryMatch struct {
+	Addr uint64
+	// Mapping is the memory mapping containing Addr, nil if the memory map
+	// of the target is not available.
+	Mapping *MemoryMapEntry
+	// Symbol is the function or package variable containing Addr,
+	// SymbolOffset is the offset of Addr from its start.
+	Symbol       string
+	SymbolOffset uint64
+	// HeapObject is the address of the heap object containing Addr, 0 if
+	// Addr isn't inside a heap object, HeapType is its type.
+	HeapObject uint64
+	HeapType   string
+}
+
+// FindMemory searches memory between start and end for pattern and
+// returns the address of each occurrence, annotated with the mapping, the
+// symbol and the heap object containing it.
+// If start and end are both 0 all the readable mappings of the target are
+// searched. At most maxMatches occurrences are returned, the second return
+// value is true if more exist. If maxMatches is 0 all occurrences are
+// returned.
+func FindMemory(t *Target, start, end uint64, pattern []byte, maxMatches int) ([]MemoryMatch, bool, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, false, err
+	}
+	if len(pattern) == 0 {
+		return nil, false, errors.New("empty pattern")
+	}
+	all := start == 0 && end == 0
+	if !all && end <= start {
+		return nil, false, errors.New("invalid range")
+	}
+
+	type region struct {
+		start, end uint64
+		mapping    *MemoryMapEntry
+	}
+	var regions []region
+	memmap, err := t.proc.MemoryMap()
+	switch {
+	case err == nil:
+		for i := range memmap {
+			mme := &memmap[i]
+			if !mme.Read {
+				continue
+			}
+			r := region{mme.Addr, mme.Addr + mme.Size, mme}
+			if !all {
+				r.start, r.end = max(r.start, start), min(r.end, end)
+			}
+			if r.start < r.end {
+				regions = append(regions, r)
+			}
+		}
+	case all:
+		return nil, false, err
+	default:
+		regions = append(regions, region{start, end, nil})
+	}
+
+	var matches []MemoryMatch
+	truncated := false
+	mem := t.Memory()
+	buf := make([]byte, findChunkSize+len(pattern)-1)
+search:
+	for _, r := range regions {
+		for addr := r.start; addr < r.end; addr += findChunkSize {
+			sz := min(uint64(len(buf)), r.end-addr)
+			n, _ := mem.ReadMemory(buf[:sz], addr)
+			for off := 0; ; {
+				i := bytes.Index(buf[off:n], pattern)
+				if i < 0 || off+i >= findChunkSize {
+					break
+				}
+				if maxMatches > 0 && len(matches) >= maxMatches {
+					truncated = true
+					break search
+				}
+				matches = append(matches, MemoryMatch{Addr: addr + uint64(off+i), Mapping: r.mapping})
+				off += i + 1
+			}
+		}
+	}
+
+	t.annotateMemoryMatches(matches)
+	return matches, truncated, nil
+}
+
+// annotateMemoryMatches fills the symbol and heap object of each match.
+func (t *Target) annotateMemoryMatches(matches []MemoryMatch) {
+	bi := t.BinInfo()
+	var h *heapInfo
+	for i := range matches {
+		m := &matches[i]
+		m.Symbol, m.SymbolOffset = bi.addrSymbol(m.Addr)
+		if m.Symbol != "" {
+			continue
+		}
+		if h == nil {
+			var err error
+			h, err = t.loadHeap(false)
+			if err != nil {
+				return
+			}
+		}
+		if s, idx, ok := h.findObject(m.Addr); ok {
+			if !h.inferred {
+				h, _ = t.loadHeap(true)
+			}
+			m.HeapObject = s.objectAddr(idx)
+			m.HeapType = h.typeName(s, idx)
+		}
+	}
+}
+
+// addrSymbol returns the name of the function or package variable
+// containing addr and the offset of addr from its start.
+func (bi *BinaryInfo) addrSymbol(addr uint64) (string, uint64) {
+	if fn := bi.PCToFunc(addr); fn != nil {
+		return fn.Name, addr - fn.Entry
+	}
+	i := sort.Search(len(bi.packageVars), func(i int) bool {
+		return bi.packageVars[i].addr > addr
+	}) - 1
+	if i < 0 || bi.packageVars[i].addr == 0 {
+		return "", 0
+	}
+	pkgvar := bi.packageVars[i]
+	if addr < pkgvar.addr+uint64(packageVarSize(pkgvar)) {
+		return pkgvar.name, addr - pkgvar.addr
+	}
+	return "", 0
+}
+
+// packageVarSize returns the size of pkgvar, 0 if its type can not be
+// read.
+func packageVarSize(pkgvar packageVar) int64 {
+	reader := pkgvar.cu.image.dwarfReader
+	reader.Seek(pkgvar.offset)
+	entry, err := reader.Next()
+	if err != nil {
+		return 0
+	}
+	off, ok := entry.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		return 0
+	}
+	typ, err := pkgvar.cu.image.Type(off)
+	if err != nil {
+		return 0
+	}
+	return typ.Size()
+}
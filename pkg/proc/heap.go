@@ -0,0 +1,692 @@
+package proc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// HeapTypeStats is the number of allocated heap objects of a type and the
+// number of bytes they occupy.
+type HeapTypeStats struct {
+	Type  string
+	Count int64
+	Bytes int64
+}
+
+const (
+	// heapMaxStackDepth is the maximum number of frames of each goroutine
+	// that are used as roots to infer the type of heap objects.
+	heapMaxStackDepth = 64
+	// heapMaxScanSize is the maximum number of bytes of a single object or
+	// variable that are scanned for pointers.
+	heapMaxScanSize = 16 * 1024 * 1024
+	// mSpanInUse is the value of mspan.state for spans containing heap
+	// objects.
+	mSpanInUse = 1
+)
+
+// heapSpan is a span of heap memory containing objects of the same size.
+type heapSpan struct {
+	base     uint64 // address of the first object
+	limit    uint64 // end of the last object
+	elemsize uint64 // size of each object, including the malloc header
+	nelems   uint64
+	header   uint64 // size of the malloc header at the start of each object
+	noscan   bool   // objects in the span do not contain pointers
+	alloc    []bool // alloc[i] is true if object i is allocated
+	types    []godwarf.Type
+}
+
+// heapInfo describes the heap of a target process.
+type heapInfo struct {
+	bi    *BinaryInfo
+	mem   MemoryReadWriter
+	spans []*heapSpan // sorted by base address
+	roots []heapRoot
+
+	inferred bool // inferTypes was called
+
+	ptrSize    int64
+	mds        []ModuleData
+	rtype      godwarf.Type
+	rtypeCache map[uint64]heapRuntimeType
+	uint8Type  godwarf.Type
+	hasPtrs    map[godwarf.Type]bool
+	interior   map[heapInteriorKey]bool
+	work       []heapScanJob
+}
+
+type heapRuntimeType struct {
+	typ    godwarf.Type
+	direct bool
+}
+
+type heapInteriorKey struct {
+	addr uint64
+	typ  string
+}
+
+// heapScanJob is a memory region that must be scanned for pointers, the
+// region contains count consecutive values of type typ.
+type heapScanJob struct {
+	addr  uint64
+	typ   godwarf.Type
+	count int64
+}
+
+// HeapHistogram walks the heap of t and returns the number of allocated
+// objects and the number of bytes they occupy, grouped by type and sorted
+// by decreasing size.
+//
+// The type of objects with a malloc header (Go 1.22 and later) is read from
+// the header. The type of the other objects is inferred by following typed
+// pointers starting at package variables and at the local variables of all
+// goroutines, objects that can not be reached this way are reported as
+// unknown and grouped by size.
+func HeapHistogram(t *Target) ([]HeapTypeStats, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	h, err := t.loadHeap(true)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*HeapTypeStats)
+	for _, s := range h.spans {
+		for i := range s.alloc {
+			if !s.alloc[i] {
+				continue
+			}
+			name := h.typeName(s, uint64(i))
+			st := stats[name]
+			if st == nil {
+				st = &HeapTypeStats{Type: name}
+				stats[name] = st
+			}
+			st.Count++
+			st.Bytes += int64(s.elemsize)
+		}
+	}
+	r := make([]HeapTypeStats, 0, len(stats))
+	for _, st := range stats {
+		r = append(r, *st)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Bytes != r[j].Bytes {
+			return r[i].Bytes > r[j].Bytes
+		}
+		return r[i].Type < r[j].Type
+	})
+	return r, nil
+}
+
+// loadHeap returns the heap of t, if infer is true types are also inferred
+// for objects without a malloc header, see inferTypes.
+func (t *Target) loadHeap(infer bool) (*heapInfo, error) {
+	if t.heapCache == nil {
+		h, err := readHeap(t)
+		if err != nil {
+			return nil, err
+		}
+		t.heapCache = h
+	}
+	if infer && !t.heapCache.inferred {
+		t.heapCache.inferTypes(t)
+		t.heapCache.inferred = true
+	}
+	return t.heapCache, nil
+}
+
+// readHeap reads the list of in use spans from runtime.mheap_ and the type
+// of the objects that have a malloc header.
+func readHeap(t *Target) (*heapInfo, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	scope := globalScope(t, bi, bi.Images[0], mem)
+
+	// +rtype -var mheap_ mheap
+	// +rtype -field mheap.allspans []*mspan
+	mheap, err := scope.findGlobal("runtime", "mheap_")
+	if err != nil {
+		return nil, err
+	}
+	allspans, err := mheap.structMember("allspans")
+	if err != nil {
+		return nil, err
+	}
+	ptrSize := int64(bi.Arch.PtrSize())
+	spansAddr, err := readUintRaw(mem, allspans.Addr, ptrSize)
+	if err != nil {
+		return nil, err
+	}
+	nspans, err := readUintRaw(mem, allspans.Addr+uint64(ptrSize), ptrSize)
+	if err != nil {
+		return nil, err
+	}
+
+	mspanType, err := bi.findType("runtime.mspan")
+	if err != nil {
+		return nil, err
+	}
+	mspanStruct, ok := godwarf.ResolveTypedef(mspanType).(*godwarf.StructType)
+	if !ok {
+		return nil, errors.New("runtime.mspan is not a struct")
+	}
+	fields := make(map[string]*godwarf.StructField)
+	for _, f := range mspanStruct.Field {
+		fields[f.Name] = f
+	}
+	// +rtype -field mspan.startAddr uintptr
+	// +rtype -field mspan.nelems uint16|uintptr
+	// +rtype -field mspan.freeindex uint16|uintptr
+	// +rtype -field mspan.allocBits *gcBits
+	// +rtype -field mspan.spanclass spanClass
+	// +rtype -field mspan.state mSpanStateBox
+	// +rtype -field mspan.elemsize uintptr
+	for _, name := range []string{"startAddr", "nelems", "freeindex", "allocBits", "spanclass", "state", "elemsize"} {
+		if fields[name] == nil {
+			return nil, fmt.Errorf("unsupported runtime.mspan layout: field %s not found", name)
+		}
+	}
+	freeindex := fields["freeindex"]
+	if f := fields["freeIndexForScan"]; f != nil {
+		freeindex = f
+	}
+	// The largeType field and malloc headers were introduced in Go 1.22.
+	largeType := fields["largeType"]
+
+	h := &heapInfo{
+		bi:         bi,
+		mem:        mem,
+		ptrSize:    ptrSize,
+		rtypeCache: make(map[uint64]heapRuntimeType),
+		hasPtrs:    make(map[godwarf.Type]bool),
+		interior:   make(map[heapInteriorKey]bool),
+	}
+	h.mds, _ = bi.getModuleData(mem)
+	h.rtype, _ = bi.findType(bi.runtimeTypeTypename())
+	h.uint8Type, _ = bi.findType("uint8")
+
+	if nspans > 0 {
+		ptrs := make([]byte, nspans*uint64(ptrSize))
+		if _, err := mem.ReadMemory(ptrs, spansAddr); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, mspanStruct.Size())
+		field := func(f *godwarf.StructField) uint64 {
+			off := f.ByteOffset
+			switch f.Type.Size() {
+			case 1:
+				return uint64(buf[off])
+			case 2:
+				return uint64(binary.LittleEndian.Uint16(buf[off:]))
+			case 4:
+				return uint64(binary.LittleEndian.Uint32(buf[off:]))
+			default:
+				return h.readPtr(buf[off:])
+			}
+		}
+		for i := uint64(0); i < nspans; i++ {
+			spanAddr := h.readPtr(ptrs[i*uint64(ptrSize):])
+			if spanAddr == 0 {
+				continue
+			}
+			if _, err := mem.ReadMemory(buf, spanAddr); err != nil {
+				continue
+			}
+			// The state field is a uint8 or a struct wrapping an atomic uint8.
+			if buf[fields["state"].ByteOffset] != mSpanInUse {
+				continue
+			}
+			s := &heapSpan{
+				base:     field(fields["startAddr"]),
+				nelems:   field(fields["nelems"]),
+				elemsize: field(fields["elemsize"]),
+			}
+			if s.elemsize == 0 || s.nelems == 0 {
+				continue
+			}
+			s.limit = s.base + s.nelems*s.elemsize
+			spanclass := field(fields["spanclass"])
+			s.noscan = spanclass&1 != 0
+			sizeclass := spanclass >> 1
+
+			s.alloc = make([]bool, s.nelems)
+			nfree := field(freeindex)
+			var bits []byte
+			if allocBits := field(fields["allocBits"]); allocBits != 0 {
+				bits = make([]byte, (s.nelems+7)/8)
+				if _, err := mem.ReadMemory(bits, allocBits); err != nil {
+					bits = nil
+				}
+			}
+			for j := uint64(0); j < s.nelems; j++ {
+				s.alloc[j] = j < nfree || (bits != nil && bits[j/8]&(1<<(j%8)) != 0)
+			}
+			s.types = make([]godwarf.Type, s.nelems)
+
+			if largeType != nil && !s.noscan {
+				if sizeclass == 0 {
+					if typ := h.runtimeType(field(largeType)); typ != nil {
+						s.types[0] = typ
+					}
+				} else if s.elemsize > uint64(ptrSize*ptrSize*8) {
+					s.header = uint64(ptrSize)
+					h.readHeaders(s)
+				}
+			}
+			h.spans = append(h.spans, s)
+		}
+	}
+	sort.Slice(h.spans, func(i, j int) bool { return h.spans[i].base < h.spans[j].base })
+
+	for _, s := range h.spans {
+		for i, typ := range s.types {
+			if typ != nil && s.alloc[i] && !s.noscan {
+				h.pushObject(s, uint64(i))
+			}
+		}
+	}
+	return h, nil
+}
+
+// readHeaders reads the malloc headers of the allocated objects of s.
+func (h *heapInfo) readHeaders(s *heapSpan) {
+	buf := make([]byte, h.ptrSize)
+	for i := range s.alloc {
+		if !s.alloc[i] {
+			continue
+		}
+		if _, err := h.mem.ReadMemory(buf, s.base+uint64(i)*s.elemsize); err != nil {
+			continue
+		}
+		if typ := h.runtimeType(h.readPtr(buf)); typ != nil {
+			s.types[i] = typ
+		}
+	}
+}
+
+// runtimeType returns the DWARF type corresponding to the runtime._type at
+// addr.
+func (h *heapInfo) runtimeType(addr uint64) godwarf.Type {
+	typ, _ := h.runtimeTypeDirect(addr)
+	return typ
+}
+
+func (h *heapInfo) runtimeTypeDirect(addr uint64) (godwarf.Type, bool) {
+	if addr == 0 || h.rtype == nil {
+		return nil, false
+	}
+	if rt, ok := h.rtypeCache[addr]; ok {
+		return rt.typ, rt.direct
+	}
+	typ, direct, err := RuntimeTypeToDIE(newVariable("", addr, h.rtype, h.bi, h.mem), 0, h.mds)
+	if err != nil || typ.Size() <= 0 {
+		typ, direct = nil, false
+	}
+	h.rtypeCache[addr] = heapRuntimeType{typ, direct}
+	return typ, direct
+}
+
+func (h *heapInfo) readPtr(buf []byte) uint64 {
+	if h.ptrSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(buf))
+	}
+	return binary.LittleEndian.Uint64(buf)
+}
+
+// findSpan returns the in use span containing addr and the index of the
+// object slot containing addr in the span.
+func (h *heapInfo) findSpan(addr uint64) (*heapSpan, uint64, bool) {
+	i := sort.Search(len(h.spans), func(i int) bool { return h.spans[i].limit > addr })
+	if i >= len(h.spans) || addr < h.spans[i].base {
+		return nil, 0, false
+	}
+	s := h.spans[i]
+	return s, (addr - s.base) / s.elemsize, true
+}
+
+// findObject returns the span containing addr and the index of the object
+// containing addr in the span.
+func (h *heapInfo) findObject(addr uint64) (*heapSpan, uint64, bool) {
+	s, idx, ok := h.findSpan(addr)
+	if !ok || !s.alloc[idx] {
+		return nil, 0, false
+	}
+	return s, idx, true
+}
+
+// objectAddr returns the address of object idx of s, after the malloc
+// header.
+func (s *heapSpan) objectAddr(idx uint64) uint64 {
+	return s.base + idx*s.elemsize + s.header
+}
+
+// objectCount returns how many values of the type of object idx of s fit
+// in the object.
+func (s *heapSpan) objectCount(idx uint64) int64 {
+	sz := s.types[idx].Size()
+	if sz <= 0 {
+		return 1
+	}
+	return int64((s.elemsize - s.header) / uint64(sz))
+}
+
+// typeName returns the name of the type of object idx of s, objects
+// containing more than one value are reported as slices.
+func (h *heapInfo) typeName(s *heapSpan, idx uint64) string {
+	typ := s.types[idx]
+	if typ == nil {
+		return fmt.Sprintf("<unknown %d bytes>", s.elemsize)
+	}
+	if s.objectCount(idx) > 1 {
+		return "[]" + heapTypeName(typ)
+	}
+	return heapTypeName(typ)
+}
+
+// pushObject schedules object idx of s to be scanned for pointers.
+func (h *heapInfo) pushObject(s *heapSpan, idx uint64) {
+	if s.noscan || !h.hasPointers(s.types[idx]) {
+		return
+	}
+	h.work = append(h.work, heapScanJob{s.objectAddr(idx), s.types[idx], s.objectCount(idx)})
+}
+
+// heapRoot is a variable used as a starting point to walk the heap.
+type heapRoot struct {
+	v           *Variable
+	goroutineID int64 // goroutine of stack variables, 0 for package variables
+	frame       int
+}
+
+// heapPointerFunc is called for every pointer found while walking the
+// heap, off is the offset of the pointer from the start of the memory
+// region being walked, ptr is its value and typ the type it points to.
+type heapPointerFunc func(off int64, ptr uint64, typ godwarf.Type)
+
+// inferTypes collects package variables and the local variables of all
+// goroutines in h.roots and assigns a type to the heap objects that can be
+// reached from them.
+func (h *heapInfo) inferTypes(t *Target) {
+	scope := globalScope(t, h.bi, h.bi.Images[0], h.mem)
+	if vars, err := scope.packageVariables(LoadConfig{}, nil); err == nil {
+		for _, v := range vars {
+			h.root(heapRoot{v: v})
+		}
+	}
+	h.scanAll()
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return
+	}
+	for _, g := range gs {
+		if g.Unreadable != nil {
+			continue
+		}
+		frames, err := GoroutineStacktrace(t, g, heapMaxStackDepth, 0)
+		if err != nil {
+			continue
+		}
+		threadID := 0
+		if g.Thread != nil {
+			threadID = g.Thread.ThreadID()
+		}
+		for i := range frames {
+			if frames[i].Current.Fn == nil {
+				continue
+			}
+			vars, err := FrameToScope(t, h.mem, g, threadID, frames[i:]...).Locals(0, "")
+			if err != nil {
+				continue
+			}
+			for _, v := range vars {
+				h.root(heapRoot{v: v, goroutineID: g.ID, frame: i})
+			}
+			h.scanAll()
+		}
+	}
+}
+
+// root uses variable r.v as a starting point for type inference.
+func (h *heapInfo) root(r heapRoot) {
+	v := r.v
+	if v.Unreadable != nil || v.DwarfType == nil {
+		return
+	}
+	h.roots = append(h.roots, r)
+	typ := v.DwarfType
+	if v.Flags&VariableFakeAddress == 0 {
+		if s, idx, ok := h.findObject(v.Addr); ok {
+			// escaped variable
+			h.visit(s, idx, v.Addr, typ)
+			return
+		}
+	}
+	if !h.hasPointers(typ) {
+		return
+	}
+	h.pointers(v.mem, v.Addr, typ, 1, h.follow)
+}
+
+// scanAll scans all pending heap objects.
+func (h *heapInfo) scanAll() {
+	for len(h.work) > 0 {
+		job := h.work[len(h.work)-1]
+		h.work = h.work[:len(h.work)-1]
+		h.pointers(h.mem, job.addr, job.typ, job.count, h.follow)
+	}
+}
+
+// pointers reads count values of type typ at addr and calls fn for each
+// pointer they contain.
+func (h *heapInfo) pointers(mem MemoryReadWriter, addr uint64, typ godwarf.Type, count int64, fn heapPointerFunc) {
+	sz := typ.Size()
+	if sz <= 0 || count <= 0 {
+		return
+	}
+	if sz*count > heapMaxScanSize {
+		count = heapMaxScanSize / sz
+		if count == 0 {
+			return
+		}
+	}
+	buf := make([]byte, sz*count)
+	if _, err := mem.ReadMemory(buf, addr); err != nil {
+		return
+	}
+	for i := int64(0); i < count; i++ {
+		h.walk(buf, i*sz, typ, fn)
+	}
+}
+
+// conservativePointers reads size bytes at addr and calls fn for each
+// aligned word that points to a heap object, typ is always nil.
+func (h *heapInfo) conservativePointers(addr, size uint64, fn heapPointerFunc) {
+	if size > heapMaxScanSize {
+		size = heapMaxScanSize
+	}
+	buf := make([]byte, size)
+	if _, err := h.mem.ReadMemory(buf, addr); err != nil {
+		return
+	}
+	for off := int64(0); off+h.ptrSize <= int64(len(buf)); off += h.ptrSize {
+		if ptr := h.readPtr(buf[off:]); ptr != 0 {
+			if _, _, ok := h.findObject(ptr); ok {
+				fn(off, ptr, nil)
+			}
+		}
+	}
+}
+
+// walk calls fn for each pointer contained in the value of type typ
+// stored at offset off of buf.
+func (h *heapInfo) walk(buf []byte, off int64, typ godwarf.Type, fn heapPointerFunc) {
+	if off+typ.Size() > int64(len(buf)) {
+		return
+	}
+	switch typ := godwarf.ResolveTypedef(typ).(type) {
+	case *godwarf.PtrType:
+		fn(off, h.readPtr(buf[off:]), typ.Type)
+	case *godwarf.StructType:
+		for _, f := range typ.Field {
+			if h.hasPointers(f.Type) {
+				h.walk(buf, off+f.ByteOffset, f.Type, fn)
+			}
+		}
+	case *godwarf.ArrayType:
+		if !h.hasPointers(typ.Type) {
+			return
+		}
+		stride := typ.StrideBitSize / 8
+		if stride <= 0 {
+			stride = typ.Type.Size()
+		}
+		for i := int64(0); i < typ.Count && off+(i+1)*stride <= int64(len(buf)); i++ {
+			h.walk(buf, off+i*stride, typ.Type, fn)
+		}
+	case *godwarf.SliceType:
+		fn(off, h.readPtr(buf[off:]), typ.ElemType)
+	case *godwarf.StringType:
+		if h.uint8Type != nil {
+			fn(off, h.readPtr(buf[off:]), h.uint8Type)
+		}
+	case *godwarf.InterfaceType:
+		h.walkInterface(buf, off, typ, fn)
+	case *godwarf.MapType:
+		h.walk(buf, off, typ.TypedefType.Type, fn)
+	case *godwarf.ChanType:
+		h.walk(buf, off, typ.TypedefType.Type, fn)
+	}
+}
+
+// walkInterface calls fn for the data pointer of the interface value
+// stored at offset off of buf, using its dynamic type.
+func (h *heapInfo) walkInterface(buf []byte, off int64, typ *godwarf.InterfaceType, fn heapPointerFunc) {
+	ityp, ok := godwarf.ResolveTypedef(&typ.TypedefType).(*godwarf.StructType)
+	if !ok || len(ityp.Field) == 0 || off+2*h.ptrSize > int64(len(buf)) {
+		return
+	}
+	tab := h.readPtr(buf[off:])
+	data := h.readPtr(buf[off+h.ptrSize:])
+	if tab == 0 || data == 0 {
+		return
+	}
+	rtypeAddr := tab
+	if ityp.Field[0].Name == "tab" {
+		// the _type field of runtime.itab follows the inter field.
+		var err error
+		rtypeAddr, err = readUintRaw(h.mem, tab+uint64(h.ptrSize), h.ptrSize)
+		if err != nil {
+			return
+		}
+	}
+	dyntyp, direct := h.runtimeTypeDirect(rtypeAddr)
+	if dyntyp == nil {
+		return
+	}
+	if direct {
+		h.walk(buf, off+h.ptrSize, dyntyp, fn)
+		return
+	}
+	fn(off+h.ptrSize, data, dyntyp)
+}
+
+// follow is called for a pointer to a value of type typ.
+func (h *heapInfo) follow(_ int64, addr uint64, typ godwarf.Type) {
+	if addr == 0 || typ.Size() <= 0 {
+		return
+	}
+	s, idx, ok := h.findObject(addr)
+	if !ok {
+		// Values outside of the heap that aren't variables, for example
+		// values on the stack referenced through a pointer.
+		h.scanInterior(addr, typ)
+		return
+	}
+	h.visit(s, idx, addr, typ)
+}
+
+// visit is called when a value of type typ is found at addr, inside object
+// idx of s.
+func (h *heapInfo) visit(s *heapSpan, idx, addr uint64, typ godwarf.Type) {
+	if s.setType(idx, addr, typ) {
+		h.pushObject(s, idx)
+		return
+	}
+	if addr <= s.objectAddr(idx) || addr+uint64(typ.Size()) > s.objectAddr(idx)+s.elemsize-s.header {
+		return
+	}
+	// pointer to the inside of an object, scan the value it points to since
+	// it could be of a type we wouldn't otherwise know about.
+	h.scanInterior(addr, typ)
+}
+
+// scanInterior schedules the value of type typ at addr to be scanned for
+// pointers, unless it was already scanned.
+func (h *heapInfo) scanInterior(addr uint64, typ godwarf.Type) {
+	if !h.hasPointers(typ) {
+		return
+	}
+	k := heapInteriorKey{addr, typ.String()}
+	if h.interior[k] {
+		return
+	}
+	h.interior[k] = true
+	h.work = append(h.work, heapScanJob{addr, typ, 1})
+}
+
+// heapTypeName returns the name of typ.
+func heapTypeName(typ godwarf.Type) string {
+	if name := typ.Common().Name; name != "" {
+		return name
+	}
+	return typ.String()
+}
+
+// setType sets the type of object idx of s, if it is unknown, knowing that
+// a value of type typ is stored at addr. Returns true if the type was set.
+func (s *heapSpan) setType(idx, addr uint64, typ godwarf.Type) bool {
+	if s.types[idx] != nil || typ == nil || addr < s.objectAddr(idx) {
+		return false
+	}
+	off := addr - s.objectAddr(idx)
+	sz := uint64(typ.Size())
+	if sz == 0 || off+sz > s.elemsize-s.header || off%sz != 0 {
+		return false
+	}
+	// Pointers to elements of a slice do not necessarily point to the start
+	// of the backing array.
+	s.types[idx] = typ
+	return true
+}
+
+// hasPointers returns true if values of type typ contain pointers.
+func (h *heapInfo) hasPointers(typ godwarf.Type) bool {
+	if r, ok := h.hasPtrs[typ]; ok {
+		return r
+	}
+	h.hasPtrs[typ] = false // breaks cycles of recursive types
+	r := false
+	switch rtyp := godwarf.ResolveTypedef(typ).(type) {
+	case *godwarf.PtrType, *godwarf.SliceType, *godwarf.StringType, *godwarf.InterfaceType, *godwarf.MapType, *godwarf.ChanType:
+		r = true
+	case *godwarf.StructType:
+		for _, f := range rtyp.Field {
+			if h.hasPointers(f.Type) {
+				r = true
+				break
+			}
+		}
+	case *godwarf.ArrayType:
+		r = rtyp.Count > 0 && h.hasPointers(rtyp.Type)
+	}
+	h.hasPtrs[typ] = r
+	return r
+}
@@ -0,0 +1,134 @@
+package proc
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// MemoryMappingKind classifies the contents of a memory mapping.
+type MemoryMappingKind uint8
+
+const (
+	MappingOther         MemoryMappingKind = iota
+	MappingHeap                            // Go heap arena
+	MappingStack                           // goroutine stack
+	MappingText                            // text of the executable
+	MappingData                            // data of the executable
+	MappingSharedLibrary                   // shared library
+)
+
+func (k MemoryMappingKind) String() string {
+	switch k {
+	case MappingHeap:
+		return "heap"
+	case MappingStack:
+		return "stack"
+	case MappingText:
+		return "text"
+	case MappingData:
+		return "data"
+	case MappingSharedLibrary:
+		return "shared library"
+	}
+	return ""
+}
+
+// MemoryMapping is a memory mapping of the target, classified by its
+// contents.
+type MemoryMapping struct {
+	MemoryMapEntry
+	Kind MemoryMappingKind
+	// GoroutineID is the goroutine owning the stack, for MappingStack.
+	GoroutineID int64
+}
+
+// MemoryMappings returns the memory map of the target with each mapping
+// classified as Go heap arena, text or data of a Go module, shared library
+// or other. Goroutine stacks are reported as separate mappings, splitting
+// the mapping containing them.
+func MemoryMappings(t *Target) ([]MemoryMapping, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	memmap, err := t.proc.MemoryMap()
+	if err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	mds, _ := bi.getModuleData(t.Memory())
+	var heapSpans []*heapSpan
+	if h, err := t.loadHeap(false); err == nil {
+		heapSpans = h.spans
+	}
+	var stacks []*G
+	if gs, _, err := GoroutinesInfo(t, 0, 0); err == nil {
+		for _, g := range gs {
+			if g.stack.lo != 0 && g.stack.hi > g.stack.lo {
+				stacks = append(stacks, g)
+			}
+		}
+		sort.Slice(stacks, func(i, j int) bool { return stacks[i].stack.lo < stacks[j].stack.lo })
+	}
+
+	overlaps := func(mme *MemoryMapEntry, start, end uint64) bool {
+		return start < mme.Addr+mme.Size && mme.Addr < end
+	}
+
+	r := make([]MemoryMapping, 0, len(memmap))
+	for i := range memmap {
+		m := MemoryMapping{MemoryMapEntry: memmap[i]}
+		for _, md := range mds {
+			switch {
+			case overlaps(&m.MemoryMapEntry, md.text, md.etext):
+				m.Kind = MappingText
+			case overlaps(&m.MemoryMapEntry, md.types, md.etypes), overlaps(&m.MemoryMapEntry, md.noptrdata, md.end):
+				m.Kind = MappingData
+			default:
+				continue
+			}
+			break
+		}
+		if m.Kind == MappingOther && m.Filename != "" {
+			for _, image := range bi.Images[1:] {
+				if image.Path == m.Filename || filepath.Base(image.Path) == filepath.Base(m.Filename) {
+					m.Kind = MappingSharedLibrary
+					break
+				}
+			}
+		}
+		if m.Kind == MappingOther {
+			i := sort.Search(len(heapSpans), func(i int) bool { return heapSpans[i].limit > m.Addr })
+			if i < len(heapSpans) && heapSpans[i].base < m.Addr+m.Size {
+				m.Kind = MappingHeap
+			}
+		}
+
+		// Split the mapping around the goroutine stacks it contains.
+		end := m.Addr + m.Size
+		for _, g := range stacks {
+			if g.stack.lo < m.Addr || g.stack.hi > end {
+				continue
+			}
+			if g.stack.lo > m.Addr {
+				before := m
+				before.Size = g.stack.lo - m.Addr
+				r = append(r, before)
+			}
+			stk := m
+			stk.Addr, stk.Size = g.stack.lo, g.stack.hi-g.stack.lo
+			stk.Kind, stk.GoroutineID = MappingStack, g.ID
+			if stk.Filename != "" {
+				stk.Offset += stk.Addr - m.Addr
+			}
+			r = append(r, stk)
+			if m.Filename != "" {
+				m.Offset += g.stack.hi - m.Addr
+			}
+			m.Addr, m.Size = g.stack.hi, end-g.stack.hi
+		}
+		if m.Size > 0 {
+			r = append(r, m)
+		}
+	}
+	return r, nil
+}
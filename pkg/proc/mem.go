@@ -4,12 +4,51 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"go/parser"
+	"reflect"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 )
 
 const cacheEnabled = true
 
+// ValueMemory returns the address and size of the memory storing the value
+// of expr: the backing array of slices, the contents of strings, the value
+// pointed to by pointers and the memory storing the value itself for all
+// other types.
+func (scope *EvalScope) ValueMemory(expr string) (uint64, uint64, error) {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return 0, 0, err
+	}
+	v, err := scope.evalAST(t)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v.Unreadable != nil {
+		return 0, 0, v.Unreadable
+	}
+	switch v.Kind {
+	case reflect.Slice, reflect.String:
+		if v.Base == 0 {
+			return 0, 0, errors.New("empty value")
+		}
+		return v.Base, uint64(v.Len) * uint64(v.stride), nil
+	case reflect.Ptr:
+		v = v.maybeDereference()
+		if v.Unreadable != nil {
+			return 0, 0, v.Unreadable
+		}
+		if v.Addr == 0 {
+			return 0, 0, errors.New("nil pointer")
+		}
+	}
+	if v.Addr == 0 || v.Flags&VariableFakeAddress != 0 {
+		return 0, 0, errors.New("expression does not have an address")
+	}
+	return v.Addr, uint64(v.RealType.Size()), nil
+}
+
 // MemoryReader is like io.ReaderAt, but the offset is a uint64 so that it
 // can address all of 64-bit memory.
 // Redundant with memoryReadWriter but more easily suited to working with
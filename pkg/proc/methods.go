@@ -0,0 +1,500 @@
+package proc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// Method is a method of a type, see MethodSet.
+type Method struct {
+	Name string
+	// Fn is the function implementing the method, it is nil for the methods
+	// of interface types and for methods whose code was removed by the
+	// linker.
+	Fn *Function
+	// PtrRecv is true if the method has a pointer receiver, and is
+	// therefore only in the method set of *T.
+	PtrRecv bool
+}
+
+// Implementation is a type implementing an interface, see Implements.
+type Implementation struct {
+	Type string
+	// Itab is true if the runtime has an itab for the type and the
+	// interface, which means that a value of the type was converted to the
+	// interface. Otherwise the type is only a candidate: it has methods
+	// with the names of the methods of the interface but their signatures
+	// were not checked.
+	Itab bool
+}
+
+const (
+	tflagUncommon = 1 << 0 // +rtype internal/abi.TFlagUncommon
+
+	// runtimeKindMask masks the kind in the Kind_ field of internal/abi.Type,
+	// Go 1.25 and earlier store flags in the upper bits.
+	runtimeKindMask = (1 << 5) - 1
+
+	maxRuntimeNameLen = 1 << 16
+)
+
+// MethodSet returns the methods of typename, sorted by name.
+// For interface types the methods are read from the runtime type
+// metadata. For other types typename can be either T or *T, the result is
+// the same: the methods listed in the runtime type metadata of T and *T
+// (which include methods promoted from embedded fields) and the methods
+// found in debug info, methods with PtrRecv set are not in the method set
+// of T.
+func MethodSet(t *Target, typename string) (godwarf.Type, []Method, error) {
+	typ, err := findMethodSetType(t, typename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, isiface := godwarf.ResolveTypedef(typ).(*godwarf.InterfaceType); isiface {
+		_, names, err := interfaceMethods(t, typ)
+		if err != nil {
+			return nil, nil, err
+		}
+		methods := make([]Method, len(names))
+		for i := range names {
+			methods[i] = Method{Name: names[i]}
+		}
+		return typ, methods, nil
+	}
+
+	byName := make(map[string]*Method)
+	get := func(name string) *Method {
+		m := byName[name]
+		if m == nil {
+			m = &Method{Name: name, PtrRecv: true}
+			byName[name] = m
+		}
+		return m
+	}
+
+	bi := t.BinInfo()
+	ptrtyp, _ := bi.findType("*" + typ.Common().Name)
+	for _, x := range []struct {
+		typ     godwarf.Type
+		ptrRecv bool
+	}{{typ, false}, {ptrtyp, true}} {
+		if x.typ == nil {
+			continue
+		}
+		rms, err := runtimeTypeMethods(t, x.typ)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rm := range rms {
+			m := get(rm.name)
+			if !x.ptrRecv {
+				m.PtrRecv = false
+			}
+			if m.Fn == nil && rm.pc != 0 {
+				m.Fn = bi.PCToFunc(rm.pc)
+			}
+		}
+	}
+
+	// Prefer the functions with the receiver the methods were declared with
+	// over the wrappers generated by the compiler.
+	for _, fm := range debugInfoMethods(bi)[typ.Common().Name] {
+		m := get(fm.fn.BaseName())
+		switch {
+		case !fm.ptrRecv:
+			m.PtrRecv = false
+			m.Fn = fm.fn
+		case m.PtrRecv:
+			m.Fn = fm.fn
+		}
+	}
+
+	methods := make([]Method, 0, len(byName))
+	for _, m := range byName {
+		methods = append(methods, *m)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return typ, methods, nil
+}
+
+// Implements returns the types implementing the interface typename, sorted
+// by name. They are the types for which the runtime has an itab for the
+// interface and, as candidates, the named types whose methods, found in
+// debug info, include all the methods of the interface. For candidates
+// only method names are compared, not their signatures, unexported names
+// only match methods declared in the package of the interface.
+func Implements(t *Target, typename string) ([]Implementation, error) {
+	typ, err := findMethodSetType(t, typename)
+	if err != nil {
+		return nil, err
+	}
+	if _, isiface := godwarf.ResolveTypedef(typ).(*godwarf.InterfaceType); !isiface {
+		return nil, fmt.Errorf("%s is not an interface type", typename)
+	}
+	ifaceAddr, names, err := interfaceMethods(t, typ)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s has no methods, it is implemented by every type", typename)
+	}
+
+	impls := make(map[string]*Implementation)
+
+	itabTypes, err := itabTypes(t, ifaceAddr)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range itabTypes {
+		impls[name] = &Implementation{Type: name, Itab: true}
+	}
+
+	ifacePkg := packageName(typ.Common().Name)
+	for i := range names {
+		names[i] = methodKey(ifacePkg, names[i])
+	}
+
+	bi := t.BinInfo()
+	for recv, fms := range debugInfoMethods(bi) {
+		if _, err := bi.findType(recv); err != nil {
+			continue
+		}
+		valueMethods := make(map[string]bool)
+		allMethods := make(map[string]bool)
+		for _, fm := range fms {
+			key := methodKey(fm.fn.PackageName(), fm.fn.BaseName())
+			if !fm.ptrRecv {
+				valueMethods[key] = true
+			}
+			allMethods[key] = true
+		}
+		var name string
+		switch {
+		case hasAllMethods(valueMethods, names):
+			name = recv
+		case hasAllMethods(allMethods, names):
+			name = "*" + recv
+		default:
+			continue
+		}
+		if impls[name] == nil {
+			impls[name] = &Implementation{Type: name}
+		}
+	}
+
+	r := make([]Implementation, 0, len(impls))
+	for _, impl := range impls {
+		r = append(r, *impl)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Type < r[j].Type })
+	return r, nil
+}
+
+// methodKey returns the name used to match a method named name, declared
+// in package pkg, with the methods of an interface. Unexported names are
+// qualified by their package, methods of other packages can not implement
+// them.
+func methodKey(pkg, name string) string {
+	if token.IsExported(name) {
+		return name
+	}
+	return pkg + "." + name
+}
+
+func hasAllMethods(methods map[string]bool, names []string) bool {
+	for _, name := range names {
+		if !methods[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// findMethodSetType returns the named type typename, if typename is a
+// pointer type the type it points to is returned.
+func findMethodSetType(t *Target, typename string) (godwarf.Type, error) {
+	expr, err := parser.ParseExpr(typename)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse type %q: %v", typename, err)
+	}
+	if star, isstar := expr.(*ast.StarExpr); isstar {
+		expr = star.X
+	}
+	typ, err := t.BinInfo().findTypeExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("could not find type %s: %v", typename, err)
+	}
+	return typ, nil
+}
+
+type debugInfoMethod struct {
+	fn      *Function
+	ptrRecv bool
+}
+
+// debugInfoMethods returns the functions in debug info that are methods,
+// indexed by the name of their receiver type.
+func debugInfoMethods(bi *BinaryInfo) map[string][]debugInfoMethod {
+	r := make(map[string][]debugInfoMethod)
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		recv := fn.ReceiverName()
+		if recv == "" {
+			continue
+		}
+		ptrRecv := false
+		if strings.HasPrefix(recv, "(*") && strings.HasSuffix(recv, ")") {
+			recv = recv[2 : len(recv)-1]
+			ptrRecv = true
+		}
+		recv = fn.PackageName() + "." + recv
+		r[recv] = append(r[recv], debugInfoMethod{fn, ptrRecv})
+	}
+	return r
+}
+
+type runtimeMethod struct {
+	name string
+	pc   uint64 // entry point of the method, 0 if it was removed by the linker
+}
+
+// runtimeTypeMethods returns the methods of typ listed in the
+// internal/abi.UncommonType of its runtime type, or nil if typ doesn't
+// have a runtime type.
+func runtimeTypeMethods(t *Target, typ godwarf.Type) ([]runtimeMethod, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	typeAddr, _, found, err := dwarfToRuntimeType(bi, mem, typ)
+	if err != nil || !found {
+		return nil, err
+	}
+	mds, err := LoadModuleData(bi, mem)
+	if err != nil {
+		return nil, err
+	}
+	md := findModuleDataForType(mds, typeAddr)
+	if md == nil {
+		return nil, fmt.Errorf("could not find module data for type %s", typ)
+	}
+
+	// +rtype -field internal/abi.Type.TFlag internal/abi.TFlag
+	// +rtype -field internal/abi.Type.Kind_ internal/abi.Kind|uint8
+	// +rtype -field internal/abi.UncommonType.Mcount uint16
+	// +rtype -field internal/abi.UncommonType.Moff uint32
+	// +rtype -field internal/abi.Method.Name internal/abi.NameOff
+	// +rtype -field internal/abi.Method.Tfn internal/abi.TextOff
+	rtyp, err := bi.findType("internal/abi.Type")
+	if err != nil {
+		return nil, err
+	}
+	_type := newVariable("", typeAddr, rtyp, bi, mem)
+	tflag, _ := schedUint(_type, "TFlag")
+	if tflag&tflagUncommon == 0 {
+		return nil, nil
+	}
+	kind, _ := schedUint(_type, "Kind_")
+
+	// The UncommonType follows the type specific struct.
+	var ktypname string
+	switch reflect.Kind(kind & runtimeKindMask) {
+	case reflect.Struct:
+		ktypname = "internal/abi.StructType"
+	case reflect.Ptr:
+		ktypname = "internal/abi.PtrType"
+	case reflect.Func:
+		ktypname = "internal/abi.FuncType"
+	case reflect.Slice:
+		ktypname = "internal/abi.SliceType"
+	case reflect.Array:
+		ktypname = "internal/abi.ArrayType"
+	case reflect.Chan:
+		ktypname = "internal/abi.ChanType"
+	case reflect.Map:
+		ktypname = "internal/abi.MapType"
+	case reflect.Interface:
+		ktypname = "internal/abi.InterfaceType"
+	default:
+		ktypname = "internal/abi.Type"
+	}
+	ktyp, err := bi.findType(ktypname)
+	if err != nil {
+		return nil, err
+	}
+	utyp, err := bi.findType("internal/abi.UncommonType")
+	if err != nil {
+		return nil, err
+	}
+	mtyp, err := bi.findType("internal/abi.Method")
+	if err != nil {
+		return nil, err
+	}
+	uaddr := typeAddr + uint64(ktyp.Size())
+	u := newVariable("", uaddr, utyp, bi, mem)
+	mcount, _ := schedUint(u, "Mcount")
+	moff, _ := schedUint(u, "Moff")
+
+	r := make([]runtimeMethod, 0, mcount)
+	for i := uint64(0); i < mcount; i++ {
+		m := newVariable("", uaddr+moff+i*uint64(mtyp.Size()), mtyp, bi, mem)
+		nameOff, ok1 := schedUint(m, "Name")
+		tfn, ok2 := schedUint(m, "Tfn")
+		if !ok1 || !ok2 {
+			return nil, errors.New("could not read method of runtime type")
+		}
+		name, err := readRuntimeName(mem, md.types+nameOff)
+		if err != nil {
+			return nil, err
+		}
+		rm := runtimeMethod{name: name}
+		if int32(tfn) != -1 {
+			rm.pc = md.text + tfn
+		}
+		r = append(r, rm)
+	}
+	return r, nil
+}
+
+// interfaceMethods returns the address of the runtime type of the
+// interface type typ and the names of its methods, read from its
+// internal/abi.InterfaceType.
+func interfaceMethods(t *Target, typ godwarf.Type) (uint64, []string, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	typeAddr, _, found, err := dwarfToRuntimeType(bi, mem, typ)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !found {
+		return 0, nil, fmt.Errorf("could not find runtime type of %s", typ)
+	}
+	mds, err := LoadModuleData(bi, mem)
+	if err != nil {
+		return 0, nil, err
+	}
+	md := findModuleDataForType(mds, typeAddr)
+	if md == nil {
+		return 0, nil, fmt.Errorf("could not find module data for type %s", typ)
+	}
+
+	// +rtype -field internal/abi.InterfaceType.Methods []internal/abi.Imethod
+	// +rtype -field internal/abi.Imethod.Name internal/abi.NameOff
+	ityp, err := bi.findType("internal/abi.InterfaceType")
+	if err != nil {
+		return 0, nil, err
+	}
+	methods, err := newVariable("", typeAddr, ityp, bi, mem).structMember("Methods")
+	if err != nil {
+		return 0, nil, err
+	}
+	methods.loadValue(LoadConfig{MaxArrayValues: int(methods.Len), MaxStructFields: -1})
+	if methods.Unreadable != nil {
+		return 0, nil, methods.Unreadable
+	}
+	names := make([]string, 0, len(methods.Children))
+	for i := range methods.Children {
+		nameOff, ok := schedUint(&methods.Children[i], "Name")
+		if !ok {
+			return 0, nil, errors.New("could not read method of interface type")
+		}
+		name, err := readRuntimeName(mem, md.types+nameOff)
+		if err != nil {
+			return 0, nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return typeAddr, names, nil
+}
+
+// itabTypes returns the names of the types that have an itab for the
+// interface whose runtime type is at ifaceAddr in the runtime itab table.
+func itabTypes(t *Target, ifaceAddr uint64) ([]string, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	scope := globalScope(t, bi, bi.Images[0], mem)
+
+	// +rtype -var itabTable *itabTableType
+	// +rtype -field itabTableType.size uintptr
+	// +rtype -field itabTableType.entries [512]*itab|[512]*internal/abi.ITab
+	// +rtype -field internal/abi.ITab.Inter *internal/abi.InterfaceType
+	// +rtype -field internal/abi.ITab.Type *internal/abi.Type
+	// +rtype -field internal/abi.ITab.Fun [1]uintptr
+	itabTable, err := scope.findGlobal("runtime", "itabTable")
+	if err != nil {
+		return nil, err
+	}
+	itabTable = itabTable.maybeDereference()
+	size, ok := schedUint(itabTable, "size")
+	entries, err := itabTable.structMember("entries")
+	if !ok || err != nil {
+		return nil, errors.New("could not read runtime.itabTable")
+	}
+	itabType, err := bi.findType("internal/abi.ITab")
+	if err != nil {
+		return nil, err
+	}
+	rtyp, err := bi.findType(bi.runtimeTypeTypename())
+	if err != nil {
+		return nil, err
+	}
+	mds, err := LoadModuleData(bi, mem)
+	if err != nil {
+		return nil, err
+	}
+
+	ptrSize := uint64(bi.Arch.PtrSize())
+	var r []string
+	for i := uint64(0); i < size; i++ {
+		tab, err := readUintRaw(mem, entries.Addr+i*ptrSize, int64(ptrSize))
+		if err != nil {
+			return nil, err
+		}
+		if tab == 0 {
+			continue
+		}
+		itab := newVariable("", tab, itabType, bi, mem)
+		inter, _ := schedUint(itab, "Inter")
+		fun, _ := schedUint(itab, "Fun")
+		if inter != ifaceAddr || fun == 0 {
+			// fun[0] == 0 means that the type does not implement the interface
+			continue
+		}
+		typeAddr, ok := schedUint(itab, "Type")
+		if !ok {
+			continue
+		}
+		typ, _, err := RuntimeTypeToDIE(newVariable("", typeAddr, rtyp, bi, mem), 0, mds)
+		if err != nil {
+			continue
+		}
+		r = append(r, typ.Common().Name)
+	}
+	return r, nil
+}
+
+// readRuntimeName reads the internal/abi.Name at addr: a byte of flags
+// followed by the length of the name, as a varint, and by the name.
+func readRuntimeName(mem MemoryReadWriter, addr uint64) (string, error) {
+	buf := make([]byte, 1+binary.MaxVarintLen64)
+	if _, err := mem.ReadMemory(buf, addr); err != nil {
+		return "", err
+	}
+	n, sz := binary.Uvarint(buf[1:])
+	if sz <= 0 || n > maxRuntimeNameLen {
+		return "", fmt.Errorf("invalid runtime name at %#x", addr)
+	}
+	name := make([]byte, n)
+	if _, err := mem.ReadMemory(name, addr+1+uint64(sz)); err != nil {
+		return "", err
+	}
+	return string(name), nil
+}
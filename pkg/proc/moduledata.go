@@ -2,15 +2,18 @@ package proc
 
 // ModuleData counterpart to runtime.moduleData
 type ModuleData struct {
-	text, etext   uint64
-	types, etypes uint64
-	typemapVar    *Variable
+	text, etext    uint64
+	types, etypes  uint64
+	noptrdata, end uint64
+	typemapVar     *Variable
 }
 
 func LoadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]ModuleData, error) {
 	// +rtype -var firstmoduledata moduledata
 	// +rtype -field moduledata.text uintptr
 	// +rtype -field moduledata.types uintptr
+	// +rtype -field moduledata.noptrdata uintptr
+	// +rtype -field moduledata.end uintptr
 
 	scope := globalScope(nil, bi, bi.Images[0], mem)
 	var md *Variable
@@ -23,16 +26,18 @@ func LoadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]ModuleData, error)
 
 	for md.Addr != 0 {
 		const (
-			typesField   = "types"
-			etypesField  = "etypes"
-			textField    = "text"
-			etextField   = "etext"
-			nextField    = "next"
-			typemapField = "typemap"
+			typesField     = "types"
+			etypesField    = "etypes"
+			textField      = "text"
+			etextField     = "etext"
+			nextField      = "next"
+			typemapField   = "typemap"
+			noptrdataField = "noptrdata"
+			endField       = "end"
 		)
 		vars := map[string]*Variable{}
 
-		for _, fieldName := range []string{typesField, etypesField, textField, etextField, nextField, typemapField} {
+		for _, fieldName := range []string{typesField, etypesField, textField, etextField, nextField, typemapField, noptrdataField, endField} {
 			var err error
 			vars[fieldName], err = md.structMember(fieldName)
 			if err != nil {
@@ -54,6 +59,7 @@ func LoadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]ModuleData, error)
 		r = append(r, ModuleData{
 			types: touint(typesField), etypes: touint(etypesField),
 			text: touint(textField), etext: touint(etextField),
+			noptrdata: touint(noptrdataField), end: touint(endField),
 			typemapVar: vars[typemapField],
 		})
 		if err != nil {
@@ -35,8 +35,14 @@ func (p *nativeProcess) DumpProcessNotes(notes []elfwriter.Note, threadDone func
 		return out.Bytes()
 	}
 
+	// The notes of a snapshot describe the process it was copied from.
+	pid := p.pid
+	if p.snapshot != nil {
+		pid = p.snapshot.pid
+	}
+
 	prpsinfo := linuxPrPsInfo{
-		Pid: int32(p.pid),
+		Pid: int32(pid),
 	}
 
 	fname := p.os.comm
@@ -80,24 +86,32 @@ func (p *nativeProcess) DumpProcessNotes(notes []elfwriter.Note, threadDone func
 		})
 	}
 
-	for _, th := range p.threads {
-		regs, err := th.Registers()
-		if err != nil {
-			return false, notes, err
-		}
-
-		regs, err = regs.Copy() // triggers floating point register load
-		if err != nil {
-			return false, notes, err
+	threads := []snapshotThread{}
+	if p.snapshot != nil {
+		threads = p.snapshot.threads
+	} else {
+		for _, th := range p.threads {
+			regs, err := th.Registers()
+			if err != nil {
+				return false, notes, err
+			}
+
+			regs, err = regs.Copy() // triggers floating point register load
+			if err != nil {
+				return false, notes, err
+			}
+			threads = append(threads, snapshotThread{id: th.ID, regs: regs})
 		}
+	}
 
-		nregs := regs.(*linutil.AMD64Registers)
+	for _, th := range threads {
+		nregs := th.regs.(*linutil.AMD64Registers)
 
 		var prstatus linuxPrStatusAMD64
-		prstatus.Pid = int32(th.ID)
-		prstatus.Ppid = int32(p.pid)
-		prstatus.Pgrp = int32(p.pid)
-		prstatus.Sid = int32(p.pid)
+		prstatus.Pid = int32(th.id)
+		prstatus.Ppid = int32(pid)
+		prstatus.Pgrp = int32(pid)
+		prstatus.Sid = int32(pid)
 		prstatus.Reg = *(nregs.Regs)
 		notes = append(notes, elfwriter.Note{
 			Type: elf.NT_PRSTATUS,
@@ -0,0 +1,150 @@
+package native
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// FileDescriptors implements proc.FileDescriptorLister, it reads the
+// descriptors from /proc/<pid>/fd and the sockets from /proc/<pid>/net.
+func (p *nativeProcess) FileDescriptors() ([]proc.FileDescriptor, error) {
+	fddir := fmt.Sprintf("/proc/%d/fd", p.pid)
+	entries, err := os.ReadDir(fddir)
+	if err != nil {
+		return nil, err
+	}
+	sockets := readSockets(p.pid)
+	r := make([]proc.FileDescriptor, 0, len(entries))
+	for _, entry := range entries {
+		n, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fd := proc.FileDescriptor{FD: n}
+		fd.Path, _ = os.Readlink(filepath.Join(fddir, entry.Name()))
+		if inode, ok := strings.CutPrefix(fd.Path, "socket:["); ok {
+			if inode, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64); err == nil {
+				fd.Socket = sockets[inode]
+			}
+		}
+		r = append(r, fd)
+	}
+	return r, nil
+}
+
+// tcpStates are the names of the states of TCP sockets, as used in
+// /proc/net/tcp, see include/net/tcp_states.h.
+var tcpStates = [...]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+}
+
+// readSockets returns the sockets in the network namespace of pid, by
+// inode.
+func readSockets(pid int) map[uint64]*proc.Socket {
+	r := make(map[uint64]*proc.Socket)
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		readInetSockets(fmt.Sprintf("/proc/%d/net/%s", pid, proto), proto, r)
+	}
+	readUnixSockets(fmt.Sprintf("/proc/%d/net/unix", pid), r)
+	return r
+}
+
+// readInetSockets parses a file in the format of /proc/net/tcp:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 12345 ...
+func readInetSockets(path, proto string, r map[uint64]*proc.Socket) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer fh.Close()
+	scan := bufio.NewScanner(fh)
+	scan.Scan() // header
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		sock := &proc.Socket{Proto: proto, Inode: inode, LocalAddr: parseProcNetAddr(fields[1]), RemoteAddr: parseProcNetAddr(fields[2])}
+		if strings.HasPrefix(proto, "tcp") {
+			if st, err := strconv.ParseUint(fields[3], 16, 8); err == nil && int(st) < len(tcpStates) {
+				sock.State = tcpStates[st]
+			}
+		}
+		r[inode] = sock
+	}
+}
+
+// parseProcNetAddr parses an address in the format used by /proc/net/tcp,
+// the IP address is a sequence of 32bit words in host byte order, which
+// is little endian on all architectures supported on linux.
+func parseProcNetAddr(s string) string {
+	ipstr, portstr, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	ip, err := hex.DecodeString(ipstr)
+	if err != nil || len(ip)%4 != 0 {
+		return s
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	port, err := strconv.ParseUint(portstr, 16, 16)
+	if err != nil {
+		return s
+	}
+	return net.JoinHostPort(net.IP(ip).String(), strconv.Itoa(int(port)))
+}
+
+// readUnixSockets parses a file in the format of /proc/net/unix:
+//
+//	Num       RefCount Protocol Flags    Type St Inode Path
+//	0000000000000000: 00000002 00000000 00010000 0001 01 12345 /run/socket
+func readUnixSockets(path string, r map[uint64]*proc.Socket) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer fh.Close()
+	scan := bufio.NewScanner(fh)
+	scan.Scan() // header
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		sock := &proc.Socket{Proto: "unix", Inode: inode}
+		if len(fields) > 7 {
+			sock.LocalAddr = fields[7]
+		}
+		r[inode] = sock
+	}
+}
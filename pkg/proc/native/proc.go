@@ -39,9 +39,24 @@ type nativeProcess struct {
 
 	iscgo bool
 
+	// snapshot is set if this process is a copy of another process made by
+	// AttachSnapshot.
+	snapshot *snapshot
+
 	exited, detached atomic.Bool
 }
 
+// snapshot describes the process a snapshot process was copied from.
+type snapshot struct {
+	pid     int
+	threads []snapshotThread // threads of the copied process and their registers at the time of the copy
+}
+
+type snapshotThread struct {
+	id   int
+	regs proc.Registers
+}
+
 // newProcess returns an initialized Process struct. Before returning,
 // it will also launch a goroutine in order to handle ptrace(2)
 // functions. For more information, see the documentation on
@@ -240,7 +255,7 @@ func (procgrp *processGroup) procForThread(tid int) *nativeProcess {
 
 func (procgrp *processGroup) procForPid(pid int) *nativeProcess {
 	for _, p := range procgrp.procs {
-		if p.pid == pid {
+		if p.pid == pid || (p.snapshot != nil && p.snapshot.pid == pid) {
 			return p
 		}
 	}
@@ -383,7 +398,11 @@ func (dbp *nativeProcess) initialize(path string, debugInfoDirs []string) (*proc
 		CanDump:    runtime.GOOS == "linux" || runtime.GOOS == "freebsd" || (runtime.GOOS == "windows" && runtime.GOARCH == "amd64"),
 	})
 	procgrp.addTarget = addTarget
-	tgt, err := procgrp.add(dbp, dbp.pid, dbp.memthread, path, stopReason, cmdline)
+	pid := dbp.pid
+	if dbp.snapshot != nil {
+		pid = dbp.snapshot.pid
+	}
+	tgt, err := procgrp.add(dbp, pid, dbp.memthread, path, stopReason, cmdline)
 	if err != nil {
 		return nil, err
 	}
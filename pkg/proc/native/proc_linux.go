@@ -862,6 +862,15 @@ func (procgrp *processGroup) detachChild(dbp *nativeProcess) error {
 }
 
 func (dbp *nativeProcess) detach(kill bool) error {
+	if dbp.snapshot != nil {
+		// Snapshots are killed without being resumed, they share open files
+		// and other resources with the process they were copied from.
+		if err := sys.Kill(dbp.pid, sys.SIGKILL); err != nil {
+			return err
+		}
+		_, _, err := dbp.wait(dbp.pid, 0)
+		return err
+	}
 	for threadID := range dbp.threads {
 		err := ptraceDetach(threadID, 0)
 		if err != nil {
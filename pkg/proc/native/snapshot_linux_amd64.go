@@ -0,0 +1,305 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	sys "golang.org/x/sys/unix"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/linutil"
+)
+
+// SnapshotSupported is true if AttachSnapshot is implemented on this platform.
+const SnapshotSupported = true
+
+// AttachSnapshot makes a copy of the process with the given PID and
+// attaches to the copy. The process is stopped only for the time it takes
+// to fork it, once that's done Delve detaches from it and calls
+// snapshotDone, the debug information of the executable is loaded
+// afterwards.
+// The copy is never resumed and it shares the memory of the process
+// through copy-on-write. Its core dump describes the threads of the
+// process as they were when the copy was made. Detaching from it always
+// kills it.
+func AttachSnapshot(pid int, debugInfoDirs []string, snapshotDone func()) (*proc.TargetGroup, error) {
+	dbp := newProcess(pid)
+
+	var err error
+	dbp.execPtraceFunc(func() { err = ptraceAttach(dbp.pid) })
+	if err != nil {
+		return nil, err
+	}
+	if err := dbp.waitAttach(); err != nil {
+		_ = detachWithoutGroup(dbp, false)
+		return nil, err
+	}
+
+	if _, err := dbp.initializeBasic(); err != nil {
+		_ = detachWithoutGroup(dbp, false)
+		return nil, err
+	}
+
+	child, err := dbp.fork()
+	if err != nil {
+		_ = detachWithoutGroup(dbp, false)
+		return nil, err
+	}
+
+	// Detach from the process here, instead of using dbp.detach, so that
+	// signals received while it was stopped are delivered and snapshotDone
+	// is called as soon as it is resumed.
+	dbp.execPtraceFunc(func() {
+		for _, th := range dbp.threads {
+			if err1 := ptraceDetach(th.ID, th.os.delayedSignal); err == nil {
+				err = err1
+			}
+		}
+	})
+	dbp.detached.Store(true)
+	dbp.postExit()
+	if err != nil {
+		_ = detachWithoutGroup(child, true)
+		return nil, err
+	}
+	if snapshotDone != nil {
+		snapshotDone()
+	}
+	// See (*nativeProcess).detach
+	time.Sleep(50 * time.Millisecond)
+	if s := status(pid, dbp.os.comm); s == 'T' {
+		_ = sys.Kill(pid, sys.SIGCONT)
+	}
+
+	tgt, err := child.initialize(findExecutable("", pid), debugInfoDirs)
+	if err != nil {
+		_ = detachWithoutGroup(child, true)
+		return nil, err
+	}
+
+	// ElfUpdateSharedObjects can only be done after we initialize because it
+	// needs an initialized BinaryInfo object to work.
+	err = linutil.ElfUpdateSharedObjects(child)
+	if err != nil {
+		_ = tgt.Detach(true)
+		return nil, err
+	}
+	return tgt, nil
+}
+
+// waitAttach waits for the process to stop after being attached. It's like
+// dbp.wait but it polls more frequently, the process must not stay stopped
+// longer than necessary.
+func (dbp *nativeProcess) waitAttach() error {
+	if _, err := initialize(dbp); err != nil {
+		return err
+	}
+	for {
+		wpid, _, err := dbp.wait(dbp.pid, sys.WNOHANG)
+		if err != nil {
+			return err
+		}
+		if wpid != 0 {
+			return nil
+		}
+		if status(dbp.pid, dbp.os.comm) == statusZombie {
+			return proc.ErrProcessExited{Pid: dbp.pid}
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fork saves the registers of all the threads of dbp and makes one of them
+// call clone(2), returning the stopped child process. The registers and
+// the code of the thread are restored afterwards, in both processes.
+//
+// The child is not a child of dbp: an intermediate process is cloned first,
+// with no exit signal, which clones the child and is then killed and
+// reaped by dbp. This way the child is reparented to init (or the closest
+// subreaper) and dbp is left without a zombie when the child is killed.
+func (dbp *nativeProcess) fork() (*nativeProcess, error) {
+	threads := make([]snapshotThread, 0, len(dbp.threads))
+	for _, th := range dbp.threads {
+		regs, err := th.Registers()
+		if err != nil {
+			return nil, err
+		}
+		regs, err = regs.Copy() // triggers floating point register load
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, snapshotThread{id: th.ID, regs: regs})
+	}
+
+	th := dbp.memthread
+	var savedRegs proc.Registers
+	for i := range threads {
+		if threads[i].id == th.ID {
+			savedRegs = threads[i].regs
+		}
+	}
+	pc := savedRegs.PC()
+
+	syscallInstr := []byte{0x0f, 0x05} // SYSCALL
+	origInstr := make([]byte, len(syscallInstr))
+	if _, err := th.ReadMemory(origInstr, pc); err != nil {
+		return nil, err
+	}
+	if _, err := th.WriteMemory(pc, syscallInstr); err != nil {
+		return nil, err
+	}
+
+	var err error
+	dbp.execPtraceFunc(func() {
+		err = syscall.PtraceSetOptions(th.ID, ptraceOptionsNormal|syscall.PTRACE_O_TRACEFORK)
+	})
+	var midPid, childPid int
+	if err == nil {
+		midPid, childPid, err = dbp.forkChild(th, (*sys.PtraceRegs)(savedRegs.(*linutil.AMD64Registers).Regs))
+	}
+
+	var child *nativeProcess
+	if childPid != 0 {
+		child = newChildProcess(dbp, childPid)
+		child.snapshot = &snapshot{pid: dbp.pid, threads: threads}
+	}
+	if _, err1 := th.WriteMemory(pc, origInstr); err == nil {
+		err = err1
+	}
+	if err1 := th.restoreRegisters(savedRegs); err == nil {
+		err = err1
+	}
+	if child != nil && err == nil {
+		child.execPtraceFunc(func() { _, err = sys.PtracePokeData(childPid, uintptr(pc), origInstr) })
+	}
+	if err != nil {
+		if midPid != 0 {
+			dbp.killFork(midPid)
+		}
+		if child != nil {
+			_ = detachWithoutGroup(child, true)
+		}
+		return nil, fmt.Errorf("could not fork process %d: %v", dbp.pid, err)
+	}
+	return child, nil
+}
+
+// forkChild executes the system calls that create the child process of
+// fork. The thread th must be stopped on a SYSCALL instruction, regs are
+// the registers it should be restored to.
+// If an error is returned midPid is the PID of the intermediate process,
+// if it has not been reaped.
+func (dbp *nativeProcess) forkChild(th *nativeThread, regs *sys.PtraceRegs) (midPid, childPid int, err error) {
+	// Prevent the kernel from restarting the system call the thread was
+	// stopped in, if any, when it executes our system calls.
+	cloneRegs := *regs
+	cloneRegs.Orig_rax = ^uint64(0)
+	cloneRegs.Rax = sys.SYS_CLONE
+	cloneRegs.Rsi, cloneRegs.Rdx, cloneRegs.R10, cloneRegs.R8 = 0, 0, 0, 0
+
+	// The intermediate process has no exit signal, dbp is not notified when
+	// it is killed.
+	cloneRegs.Rdi = 0
+	midNsPid, midPid, err := dbp.stepSyscall(th.ID, &cloneRegs, &th.os.delayedSignal)
+	if err != nil {
+		return midPid, 0, err
+	}
+	if midPid == 0 {
+		return 0, 0, errors.New("no fork event for the intermediate process")
+	}
+
+	// The intermediate process is stopped after the SYSCALL instruction,
+	// which is still in its memory.
+	cloneRegs.Rdi = uint64(sys.SIGCHLD) // flags, the same used by fork(2)
+	var delayedSignal int
+	_, childPid, err = dbp.stepSyscall(midPid, &cloneRegs, &delayedSignal)
+	if err == nil && childPid == 0 {
+		err = errors.New("no fork event for the child process")
+	}
+	if err != nil {
+		return midPid, childPid, err
+	}
+
+	dbp.killFork(midPid)
+
+	waitRegs := *regs
+	waitRegs.Orig_rax = ^uint64(0)
+	waitRegs.Rax = sys.SYS_WAIT4
+	waitRegs.Rdi = midNsPid
+	waitRegs.Rsi, waitRegs.Rdx, waitRegs.R10 = 0, sys.WALL, 0
+	ret, _, err := dbp.stepSyscall(th.ID, &waitRegs, &th.os.delayedSignal)
+	if err == nil && ret != midNsPid {
+		err = fmt.Errorf("could not reap intermediate process %d", midPid)
+	}
+	return 0, childPid, err
+}
+
+// stepSyscall sets the registers of thread tid to regs and single steps it
+// over the system call they set up, returning its result. If the system
+// call created a new process its PID is returned as childPid, the new
+// process is left stopped.
+// Signals received by the thread are stored in delayedSignal.
+func (dbp *nativeProcess) stepSyscall(tid int, regs *sys.PtraceRegs, delayedSignal *int) (ret uint64, childPid int, err error) {
+	dbp.execPtraceFunc(func() { err = sys.PtraceSetRegs(tid, regs) })
+	if err != nil {
+		return 0, 0, err
+	}
+	for {
+		dbp.execPtraceFunc(func() { err = ptraceSingleStep(tid, 0) })
+		if err != nil {
+			return 0, childPid, err
+		}
+		_, status, err := dbp.waitFast(tid)
+		if err != nil {
+			return 0, childPid, err
+		}
+		if status.Exited() || status.Signaled() {
+			return 0, childPid, proc.ErrProcessExited{Pid: tid, Status: status.ExitStatus()}
+		}
+		switch s := status.StopSignal(); s {
+		case sys.SIGTRAP:
+			// The system call is reported as a clone if the exit signal of the
+			// new process is not SIGCHLD.
+			if cause := status.TrapCause(); cause != sys.PTRACE_EVENT_FORK && cause != sys.PTRACE_EVENT_CLONE {
+				var after sys.PtraceRegs
+				dbp.execPtraceFunc(func() { err = sys.PtraceGetRegs(tid, &after) })
+				if err != nil {
+					return 0, childPid, err
+				}
+				if int64(after.Rax) < 0 && int64(after.Rax) > -4096 {
+					return 0, childPid, syscall.Errno(-int64(after.Rax))
+				}
+				return after.Rax, childPid, nil
+			}
+			var msg uint
+			dbp.execPtraceFunc(func() { msg, err = sys.PtraceGetEventMsg(tid) })
+			if err != nil {
+				return 0, 0, err
+			}
+			childPid = int(msg)
+			// The child starts stopped with a SIGSTOP.
+			if _, _, err := dbp.waitFast(childPid); err != nil {
+				return 0, childPid, err
+			}
+		case sys.SIGSTOP:
+			// delayed SIGSTOP, ignore it
+		default:
+			*delayedSignal = int(s)
+		}
+	}
+}
+
+// killFork kills a process created by fork and waits for it to exit.
+func (dbp *nativeProcess) killFork(pid int) {
+	if err := sys.Kill(pid, sys.SIGKILL); err != nil {
+		return
+	}
+	for {
+		_, status, err := dbp.waitFast(pid)
+		if err != nil || status.Exited() || status.Signaled() {
+			return
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !linux || !amd64
+
+package native
+
+import (
+	"errors"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// SnapshotSupported is true if AttachSnapshot is implemented on this platform.
+const SnapshotSupported = false
+
+// AttachSnapshot is not implemented on this platform.
+func AttachSnapshot(int, []string, func()) (*proc.TargetGroup, error) {
+	return nil, errors.New("snapshots not implemented")
+}
@@ -0,0 +1,169 @@
+package proc
+
+import (
+	"go/constant"
+)
+
+// Panic is a panic in the _panic list of a goroutine, see
+// GoroutinePanics.
+type Panic struct {
+	Addr uint64
+	// Value is the value passed to panic, it is nil for the pseudo-panics
+	// used by runtime.Goexit to run deferred calls.
+	Value *Variable
+	// Recovered is true if a deferred call called recover for this panic,
+	// Repanicked is true if the recovered value was then passed to panic
+	// again.
+	Recovered  bool
+	Repanicked bool
+	// Goexit is true if this is the pseudo-panic of a call to
+	// runtime.Goexit.
+	Goexit bool
+	// Nested is true if the panic was started while the deferred calls of
+	// an earlier panic (or runtime.Goexit) were running.
+	Nested bool
+	// Aborted is true if the panic will not resume running deferred calls
+	// because a nested panic was recovered in a frame outside of it.
+	Aborted bool
+	// PanicFrame is the index, in the stacktrace of the goroutine, of the
+	// frame that called panic (or runtime.Goexit). RecoverFrame is the index
+	// of the deferred call that called recover. Both are -1 if the frame
+	// could not be determined.
+	PanicFrame   int
+	RecoverFrame int
+	// PanicLoc and RecoverLoc are the locations of PanicFrame and
+	// RecoverFrame.
+	PanicLoc   *Location
+	RecoverLoc *Location
+}
+
+const panicMaxStackDepth = 100 // maximum number of frames that GoroutinePanics looks at
+
+// GoroutinePanics returns the panics in the _panic list of g, oldest
+// first. Recovered panics are only included until the deferred call that
+// recovered them returns.
+func GoroutinePanics(tgt *Target, g *G) ([]Panic, error) {
+	ps := readPanics(g)
+	if len(ps) == 0 {
+		return ps, nil
+	}
+
+	// The frames of runtime.gopanic and runtime.Goexit are on the stack in
+	// the same order as the panics in the list.
+	frames, err := GoroutineStacktrace(tgt, g, panicMaxStackDepth, 0)
+	if err != nil {
+		return ps, err
+	}
+	i := len(ps) - 1
+	for j := range frames {
+		if i < 0 {
+			break
+		}
+		fn := frames[j].Call.Fn
+		if fn == nil || (fn.Name != "runtime.gopanic" && fn.Name != "runtime.Goexit") {
+			continue
+		}
+		if (fn.Name == "runtime.Goexit") != ps[i].Goexit {
+			break
+		}
+		for k := j + 1; k < len(frames); k++ {
+			if fn := frames[k].Call.Fn; fn != nil && !fn.privateRuntime() {
+				ps[i].PanicFrame = k
+				ps[i].PanicLoc = &frames[k].Call
+				break
+			}
+		}
+		if ps[i].Recovered && j > 0 {
+			if fn := frames[j-1].Call.Fn; fn != nil && !fn.privateRuntime() {
+				ps[i].RecoverFrame = j - 1
+				ps[i].RecoverLoc = &frames[j-1].Call
+			}
+		}
+		i--
+	}
+	return ps, nil
+}
+
+// readPanics reads the _panic list of g, oldest first.
+func readPanics(g *G) []Panic {
+	// +rtype -field g._panic *_panic
+	// +rtype -field _panic.arg any
+	// +rtype -field _panic.link *_panic
+	// +rtype -field _panic.sp unsafe.Pointer
+	// +rtype -field _panic.recovered bool
+	// +rtype -field _panic.goexit bool
+	// +rtype go1.25 -field _panic.repanicked bool
+	// +rtype go1.22 -field _panic.startSP unsafe.Pointer
+	type panicSP struct {
+		sp, startSP uint64
+	}
+	var r []Panic
+	var sps []panicSP
+	boolField := func(p *Variable, name string) bool {
+		v := p.loadFieldNamed(name)
+		return v != nil && v.Value != nil && constant.BoolVal(v.Value)
+	}
+	ptrField := func(p *Variable, name string) uint64 {
+		v, err := p.structMember(name)
+		if err != nil {
+			return 0
+		}
+		n, _ := readUintRaw(v.mem, v.Addr, v.RealType.Size())
+		return n
+	}
+	hasAborted := false
+	p, _ := g.variable.structMember("_panic")
+	for p != nil && len(r) < crashMaxPanics {
+		p = p.maybeDereference()
+		if p.Addr == 0 || p.Unreadable != nil {
+			break
+		}
+		arg, _ := p.structMember("arg")
+		if arg == nil {
+			break
+		}
+		link, _ := p.structMember("link")
+		pn := Panic{
+			Addr:         p.Addr,
+			Recovered:    boolField(p, "recovered"),
+			Repanicked:   boolField(p, "repanicked"),
+			Goexit:       boolField(p, "goexit"),
+			Aborted:      boolField(p, "aborted"),
+			PanicFrame:   -1,
+			RecoverFrame: -1,
+			Nested:       ptrField(p, "link") != 0,
+		}
+		if !pn.Goexit {
+			arg.Name = ""
+			arg.loadValue(loadFullValueLongerStrings)
+			pn.Value = arg
+		}
+		if _, err := p.structMember("aborted"); err == nil {
+			hasAborted = true
+		}
+		r = append(r, pn)
+		sps = append(sps, panicSP{sp: ptrField(p, "sp"), startSP: ptrField(p, "startSP")})
+		p = link
+	}
+
+	if !hasAborted {
+		// Since Go 1.22 the runtime does not track aborted panics, instead
+		// when a panic is recovered all the panics that were started by the
+		// frames being unwound are removed from the list.
+		for i := range r {
+			if !r[i].Recovered || sps[i].sp == 0 {
+				continue
+			}
+			for j := i + 1; j < len(r); j++ {
+				if sps[j].startSP != 0 && sps[j].startSP < sps[i].sp {
+					r[j].Aborted = true
+				}
+			}
+		}
+	}
+
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return r
+}
@@ -1,6 +1,7 @@
 package proc
 
 import (
+	"encoding/binary"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -120,6 +121,69 @@ func TestReadCStringValue(t *testing.T) {
 	}
 }
 
+func TestReadDataRaceDesc(t *testing.T) {
+	const base = 0x1000
+	newReport := func() *dummyMem {
+		dm := &dummyMem{t: t, base: base, mem: make([]byte, 0x600)}
+		put := func(addr, v uint64) { binary.LittleEndian.PutUint64(dm.mem[addr-base:], v) }
+		// ReportDesc
+		put(base+0x28, base+0x100) // mops.begin
+		put(base+0x30, base+0x110) // mops.end
+		put(base+0x38, base+0x110) // mops.last
+		put(base+0x100, base+0x200)
+		put(base+0x108, base+0x300)
+		// first ReportMop
+		put(base+0x200, 1)
+		put(base+0x208, 0xc000010000)
+		put(base+0x210, 8|1<<32) // size and write
+		put(base+0x238, base+0x400)
+		// ReportStack and SymbolizedStack list
+		put(base+0x400, base+0x500)
+		put(base+0x500, base+0x540)
+		put(base+0x508, 0x401000)
+		put(base+0x548, 0x402000)
+		// second ReportMop
+		put(base+0x300, 2)
+		put(base+0x308, 0xc000010000)
+		put(base+0x310, 8|1<<40) // size and atomic
+		return dm
+	}
+
+	mops, err := readDataRaceDesc(newReport(), base, 8)
+	assertNoError(err, t, "readDataRaceDesc")
+	if len(mops) != 2 {
+		t.Fatalf("wrong number of accesses %d", len(mops))
+	}
+	if m := mops[0]; m.tid != 1 || m.addr != 0xc000010000 || m.size != 8 || !m.write || m.atomic || len(m.pcs) != 2 || m.pcs[0] != 0x401000 || m.pcs[1] != 0x402000 {
+		t.Errorf("wrong first access %#v", m)
+	}
+	if m := mops[1]; m.tid != 2 || m.write || !m.atomic || len(m.pcs) != 0 {
+		t.Errorf("wrong second access %#v", m)
+	}
+
+	dm := newReport()
+	dm.mem[0] = 1 // ReportTypeVptrRace
+	if mops, err := readDataRaceDesc(dm, base, 8); err != nil || len(mops) != 0 {
+		t.Errorf("expected no accesses for a report that isn't a data race, got %v %v", mops, err)
+	}
+
+	for _, corrupt := range []struct {
+		off uint64
+		val byte
+	}{
+		{0x000, 0xff}, // type
+		{0x031, 0x00}, // mops.end before mops.begin
+		{0x214, 0x02}, // write is not a bool
+		{0x220, 0x01}, // mset.begin not aligned
+	} {
+		dm := newReport()
+		dm.mem[corrupt.off] = corrupt.val
+		if _, err := readDataRaceDesc(dm, base, 8); err != errDataRaceReportLayout {
+			t.Errorf("corrupting offset %#x: expected layout error, got %v", corrupt.off, err)
+		}
+	}
+}
+
 func assertNoError(err error, t testing.TB, s string) {
 	if err != nil {
 		_, file, line, _ := runtime.Caller(1)
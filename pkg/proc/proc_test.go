@@ -30,6 +30,7 @@ import (
 
 	"github.com/go-delve/delve/pkg/dwarf/frame"
 	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/elfwriter"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
@@ -2103,6 +2104,862 @@ func TestGoroutineLabels(t *testing.T) {
 	})
 }
 
+func TestDeadlocks(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("deadlockprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		r, err := proc.Deadlocks(p)
+		assertNoError(err, t, "Deadlocks()")
+
+		startFn := map[int64]string{}
+		for _, bg := range r.Goroutines {
+			startFn[bg.G.ID] = bg.G.StartLoc(p).Fn.Name
+			t.Logf("goroutine %d %s [%s] resources %v waits for %v", bg.G.ID, startFn[bg.G.ID], bg.WaitReason, bg.Resources, bg.WaitsFor)
+		}
+
+		count := map[string]int{}
+		for _, fn := range startFn {
+			count[fn]++
+		}
+		for fn, n := range map[string]int{"main.lockOrder": 2, "main.relay": 2, "main.leak": 1, "main.nilchan": 1, "main.waiter": 0, "main.main": 0} {
+			if count[fn] != n {
+				t.Errorf("expected %d deadlocked goroutines starting at %s, got %d", n, fn, count[fn])
+			}
+		}
+
+		if len(r.Cycles) != 2 {
+			t.Fatalf("expected 2 cycles, got %v", r.Cycles)
+		}
+		for _, cycle := range r.Cycles {
+			if len(cycle) != 2 || startFn[cycle[0]] != startFn[cycle[1]] {
+				t.Errorf("unexpected cycle %v", cycle)
+			}
+		}
+	})
+}
+
+func TestSchedulerInfo(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("schedprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		si, err := proc.SchedulerInfo(p)
+		assertNoError(err, t, "SchedulerInfo()")
+		t.Logf("%#v", si)
+
+		if si.GOMAXPROCS != 1 || len(si.Ps) != 1 {
+			t.Fatalf("wrong GOMAXPROCS %d, Ps %v", si.GOMAXPROCS, si.Ps)
+		}
+		if si.GCPhase != "off" {
+			t.Errorf("wrong GC phase %q", si.GCPhase)
+		}
+		curg, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		p0 := si.Ps[0]
+		if p0.Status != "running" || p0.CurG != curg.ID || p0.M < 0 {
+			t.Errorf("wrong P %#v (current goroutine %d)", p0, curg.ID)
+		}
+		if len(p0.RunQ) != 5 {
+			t.Errorf("wrong run queue %v", p0.RunQ)
+		}
+		for _, id := range p0.RunQ {
+			g, err := proc.FindGoroutine(p, id)
+			assertNoError(err, t, "FindGoroutine()")
+			if fn := g.StartLoc(p).Fn; fn == nil || fn.Name != "main.worker" {
+				t.Errorf("wrong goroutine %d in run queue", id)
+			}
+		}
+
+		foundM, foundLocked := false, false
+		for _, m := range si.Ms {
+			if m.ID == p0.M && m.P == p0.ID && m.CurG == curg.ID && m.ThreadID == p.CurrentThread().ThreadID() {
+				foundM = true
+			}
+			if m.LockedG != 0 {
+				g, err := proc.FindGoroutine(p, m.LockedG)
+				assertNoError(err, t, "FindGoroutine()")
+				foundLocked = g.StartLoc(p).Fn.Name == "main.locked"
+			}
+		}
+		if !foundM {
+			t.Errorf("M of P 0 not found")
+		}
+		if !foundLocked {
+			t.Errorf("M locked to main.locked not found")
+		}
+	})
+}
+
+func TestTimers(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("timersprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		ti, err := proc.Timers(p)
+		assertNoError(err, t, "Timers()")
+		t.Logf("now: %d", ti.Now)
+
+		startFn := func(id int64) string {
+			g, err := proc.FindGoroutine(p, id)
+			assertNoError(err, t, "FindGoroutine()")
+			if fn := g.StartLoc(p).Fn; fn != nil {
+				return fn.Name
+			}
+			return ""
+		}
+
+		found := map[string]bool{}
+		for _, tmr := range ti.Timers {
+			t.Logf("%#v", tmr)
+			if tmr.Func == nil {
+				continue
+			}
+			switch tmr.Func.Name {
+			case "runtime.goroutineReady":
+				if len(tmr.Goroutines) == 1 && startFn(tmr.Goroutines[0]) == "main.sleeper" {
+					found["sleep"] = true
+					// ti.Now is a stale lower bound of the current time, by how much
+					// depends on when the runtime last looked at the clock.
+					if ti.Now != 0 && (tmr.When-ti.Now < int64(50*time.Minute) || tmr.When-ti.Now > int64(time.Hour+10*time.Minute)) {
+						t.Errorf("wrong fire time for time.Sleep timer %d (now %d)", tmr.When, ti.Now)
+					}
+				}
+			case "time.sendTime":
+				if tmr.Period != int64(time.Minute) {
+					t.Errorf("wrong period for ticker %d", tmr.Period)
+				}
+				if len(tmr.Goroutines) != 1 || startFn(tmr.Goroutines[0]) != "main.tick" {
+					t.Errorf("wrong goroutines for ticker %v", tmr.Goroutines)
+				}
+				found["ticker"] = true
+			case "time.goFunc":
+				if tmr.AfterFunc == nil || tmr.AfterFunc.Name != "main.afterFuncCallback" {
+					t.Errorf("wrong AfterFunc function %v", tmr.AfterFunc)
+				}
+				if tmr.ArgType != "func()" {
+					t.Errorf("wrong argument type %q", tmr.ArgType)
+				}
+				found["afterfunc"] = true
+			}
+		}
+		for _, kind := range []string{"sleep", "ticker", "afterfunc"} {
+			if !found[kind] {
+				t.Errorf("%s timer not found", kind)
+			}
+		}
+	})
+}
+
+func TestFileDescriptors(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("fdsprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		fds, err := proc.FileDescriptors(p)
+		assertNoError(err, t, "FileDescriptors()")
+
+		startFn := func(id int64) string {
+			g, err := proc.FindGoroutine(p, id)
+			assertNoError(err, t, "FindGoroutine()")
+			if fn := g.StartLoc(p).Fn; fn != nil {
+				return fn.Name
+			}
+			return ""
+		}
+		hasRef := func(fd proc.FileDescriptor, fn, name string) bool {
+			for _, ref := range fd.Refs {
+				if ref.Function == fn && ref.Name == name {
+					return true
+				}
+			}
+			return false
+		}
+		recorded, _ := grp.Recorded()
+		live := runtime.GOOS == "linux" && !recorded
+
+		found := map[string]bool{}
+		for _, fd := range fds {
+			t.Logf("%#v", fd)
+			switch {
+			case fd.Name == "/dev/null":
+				if !hasRef(fd, "main.main", "f") {
+					t.Errorf("reference to /dev/null not found")
+				}
+				found["file"] = true
+			case fd.Net == "tcp" && fd.RemoteAddr == "":
+				if fd.ReadG == 0 || startFn(fd.ReadG) != "main.acceptor" {
+					t.Errorf("wrong goroutine waiting on listener: %d", fd.ReadG)
+				}
+				if !hasRef(fd, "main.main", "ln") || !hasRef(fd, "main.acceptor", "ln") {
+					t.Errorf("references to listener not found")
+				}
+				if live && (fd.Socket == nil || fd.Socket.State != "LISTEN" || fd.Socket.LocalAddr != fd.LocalAddr) {
+					t.Errorf("wrong socket for listener %#v", fd.Socket)
+				}
+				found["listener"] = true
+			case fd.Net == "tcp" && fd.ReadG != 0:
+				if startFn(fd.ReadG) != "main.reader" {
+					t.Errorf("wrong goroutine waiting on connection: %d", fd.ReadG)
+				}
+				if !hasRef(fd, "main.reader", "conn") {
+					t.Errorf("reference to connection not found")
+				}
+				if live && (fd.Socket == nil || fd.Socket.State != "ESTABLISHED" || fd.Socket.RemoteAddr != fd.RemoteAddr) {
+					t.Errorf("wrong socket for connection %#v", fd.Socket)
+				}
+				found["conn"] = true
+			}
+		}
+		for _, kind := range []string{"file", "listener", "conn"} {
+			if !found[kind] {
+				t.Errorf("%s file descriptor not found", kind)
+			}
+		}
+	})
+}
+
+func TestChanState(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("chanstate", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+
+		cs, err := scope.ChanState("buffered", normalLoadConfig)
+		assertNoError(err, t, "ChanState(buffered)")
+		if cs.Len != 4 || cs.Cap != 4 || cs.Closed {
+			t.Errorf("wrong state for buffered: len=%d cap=%d closed=%v", cs.Len, cs.Cap, cs.Closed)
+		}
+		var queue []string
+		for i := range cs.Queue.Children {
+			queue = append(queue, api.ConvertVar(&cs.Queue.Children[i]).SinglelineString())
+		}
+		if got := strings.Join(queue, " "); got != "2 3 4 5" {
+			t.Errorf("wrong queue for buffered: %q", got)
+		}
+
+		cs, err = scope.ChanState("closed", normalLoadConfig)
+		assertNoError(err, t, "ChanState(closed)")
+		if cs.Len != 1 || !cs.Closed {
+			t.Errorf("wrong state for closed: len=%d closed=%v", cs.Len, cs.Closed)
+		}
+
+		cs, err = scope.ChanState("unbuffered", normalLoadConfig)
+		assertNoError(err, t, "ChanState(unbuffered)")
+		if len(cs.Recvq) != 0 || len(cs.Sendq) != 3 {
+			t.Fatalf("wrong wait queues for unbuffered: %d %d", len(cs.Recvq), len(cs.Sendq))
+		}
+		for i, tgt := range []string{`main.item {name: "first", n: 1}`, `main.item {name: "second", n: 2}`, `main.item {name: "select", n: 3}`} {
+			if got := api.ConvertVar(cs.Sendq[i].Value).SinglelineString(); got != tgt {
+				t.Errorf("sendq[%d] value %q, expected %q", i, got, tgt)
+			}
+			if sel := i == 2; cs.Sendq[i].Select != sel {
+				t.Errorf("sendq[%d] select %v", i, cs.Sendq[i].Select)
+			}
+		}
+
+		cs2, err := scope.ChanState("other", normalLoadConfig)
+		assertNoError(err, t, "ChanState(other)")
+		if len(cs2.Recvq) != 1 || cs2.Recvq[0].G.ID != cs.Sendq[2].G.ID || !cs2.Recvq[0].Select {
+			t.Fatalf("wrong recvq for other")
+		}
+		if cases := cs2.Recvq[0].SelectCases; len(cases) != 1 || cases[0].Chan != cs.Addr || !cases[0].Send {
+			t.Errorf("wrong select cases %#v (unbuffered at %#x)", cases, cs.Addr)
+		}
+
+		cs, err = scope.ChanState("nilchan", normalLoadConfig)
+		assertNoError(err, t, "ChanState(nilchan)")
+		if cs.Addr != 0 {
+			t.Errorf("nilchan has address %#x", cs.Addr)
+		}
+	})
+}
+
+func TestContextChain(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("ctxchain", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+
+		layers, err := scope.ContextChain("my", normalLoadConfig)
+		assertNoError(err, t, "ContextChain(my)")
+		str := func(v *proc.Variable) string {
+			if v == nil {
+				return "<nil>"
+			}
+			return api.ConvertVar(v).SinglelineString()
+		}
+		var kinds []string
+		for _, layer := range layers {
+			t.Logf("%s %s %#x deadline=%s canceled=%v err=%s cause=%s key=%s value=%s", layer.Kind, layer.Type, layer.Addr, str(layer.Deadline), layer.Canceled, str(layer.Err), str(layer.Cause), str(layer.Key), str(layer.Value))
+			kinds = append(kinds, layer.Kind)
+		}
+		if got := strings.Join(kinds, ","); got != ",withoutCancel,value,value,timer,cancel,background" {
+			t.Fatalf("wrong chain %q", got)
+		}
+		if layers[0].Type != "main.myCtx" {
+			t.Errorf("wrong type for first layer %q", layers[0].Type)
+		}
+		if got := str(layers[2].Key); got != "interface {}(int) 42" {
+			t.Errorf("wrong key %q", got)
+		}
+		if got := str(layers[2].Value); got != "interface {}([]int) [1,2,3]" {
+			t.Errorf("wrong value %q", got)
+		}
+		if got := str(layers[3].Key); got != `interface {}(main.ctxKey) "user"` {
+			t.Errorf("wrong key %q", got)
+		}
+		if got := str(layers[3].Value); got != `interface {}(string) "alice"` {
+			t.Errorf("wrong value %q", got)
+		}
+		timer := layers[4]
+		if !timer.Canceled || timer.Deadline == nil || !strings.Contains(str(timer.Err), "context canceled") || !strings.Contains(str(timer.Cause), "shutting down") {
+			t.Errorf("wrong timer context %#v", timer)
+		}
+		if !layers[5].Canceled || !strings.Contains(str(layers[5].Cause), "shutting down") {
+			t.Errorf("wrong cancel context %#v", layers[5])
+		}
+
+		layers, err = scope.ContextChain("nilctx", normalLoadConfig)
+		assertNoError(err, t, "ContextChain(nilctx)")
+		if len(layers) != 0 {
+			t.Errorf("wrong chain for nil context %#v", layers)
+		}
+	})
+}
+
+func TestGoroutinePanics(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("panicsprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		panics, err := proc.GoroutinePanics(p, g)
+		assertNoError(err, t, "GoroutinePanics()")
+		for _, pn := range panics {
+			t.Logf("%#x %s recovered=%v repanicked=%v nested=%v aborted=%v panic=%d %v recover=%d %v", pn.Addr, api.ConvertVar(pn.Value).SinglelineString(), pn.Recovered, pn.Repanicked, pn.Nested, pn.Aborted, pn.PanicFrame, pn.PanicLoc, pn.RecoverFrame, pn.RecoverLoc)
+		}
+		if len(panics) != 2 {
+			t.Fatalf("wrong number of panics %d", len(panics))
+		}
+		first, second := panics[0], panics[1]
+		if got := api.ConvertVar(first.Value).SinglelineString(); got != `interface {}(string) "first"` {
+			t.Errorf("wrong value of first panic %q", got)
+		}
+		if first.Recovered || first.Nested || !first.Aborted {
+			t.Errorf("wrong state of first panic %#v", first)
+		}
+		if first.PanicLoc == nil || first.PanicLoc.Fn.Name != "main.f" || first.RecoverLoc != nil {
+			t.Errorf("wrong frames of first panic %#v", first)
+		}
+		if got := api.ConvertVar(second.Value).SinglelineString(); !strings.Contains(got, `"second"`) {
+			t.Errorf("wrong value of second panic %q", got)
+		}
+		if !second.Recovered || !second.Nested || second.Aborted {
+			t.Errorf("wrong state of second panic %#v", second)
+		}
+		if second.PanicLoc == nil || second.PanicLoc.Fn.Name != "main.f.func2" || second.RecoverLoc == nil || second.RecoverLoc.Fn.Name != "main.f.func1" {
+			t.Errorf("wrong frames of second panic %#v", second)
+		}
+	})
+}
+
+func TestDataRaceBreakpoint(t *testing.T) {
+	skipUnlessOn(t, "race detector only supported on linux/amd64 and linux/arm64", "linux")
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skip("race detector only supported on linux/amd64 and linux/arm64")
+	}
+	protest.AllowRecording(t)
+	withTestProcessArgs("datarace", t, ".", []string{}, protest.EnableRaceDetector, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		bp := p.CurrentThread().Breakpoint()
+		if bp.Breakpoint == nil || bp.Logical == nil || bp.Logical.Name != proc.DataRaceBreakpoint {
+			t.Fatalf("not stopped on the data race breakpoint: %#v", bp.Breakpoint)
+		}
+		r := p.DataRace()
+		if r == nil {
+			t.Fatal("no data race")
+		}
+		for _, acc := range r.Accesses {
+			t.Logf("%#x size=%d write=%v atomic=%v racegoroutine=%d goroutine=%d", acc.Addr, acc.Size, acc.Write, acc.Atomic, acc.RaceGoroutine, acc.GoroutineID)
+			for _, loc := range acc.Stack {
+				t.Logf("\t%#x %s:%d", loc.PC, loc.File, loc.Line)
+			}
+		}
+		assertLineNumber(p, t, 19, "wrong line after stopping on data race")
+		if len(r.Accesses) != 2 {
+			t.Fatalf("wrong number of accesses %d", len(r.Accesses))
+		}
+		cur, prev := r.Accesses[0], r.Accesses[1]
+		if cur.Addr != prev.Addr || !cur.Write || !prev.Write {
+			t.Errorf("wrong accesses %#v %#v", cur, prev)
+		}
+		if len(cur.Stack) == 0 || cur.Stack[0].Fn == nil || cur.Stack[0].Fn.Name != "main.main" || cur.Stack[0].Line != 19 {
+			t.Errorf("wrong stack for current access %v", cur.Stack)
+		}
+		if len(prev.Stack) == 0 || prev.Stack[0].Fn == nil || prev.Stack[0].Fn.Name != "main.writer" || prev.Stack[0].Line != 11 {
+			t.Errorf("wrong stack for previous access %v", prev.Stack)
+		}
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		if cur.GoroutineID != g.ID {
+			t.Errorf("wrong goroutine for current access %d (expected %d)", cur.GoroutineID, g.ID)
+		}
+		if prev.GoroutineID != 0 {
+			t.Errorf("goroutine of previous access should be unknown, got %d", prev.GoroutineID)
+		}
+		if prev.RaceGoroutine == cur.RaceGoroutine {
+			t.Errorf("same race detector goroutine for both accesses %d", cur.RaceGoroutine)
+		}
+
+		// the data race breakpoint is removed when the target is resumed
+		err = grp.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit, got %v", err)
+		}
+	})
+}
+
+func TestReturnsErrorBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("errorigin", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		lbp := &proc.LogicalBreakpoint{LogicalID: 1, Set: proc.SetBreakpoint{ReturnsError: `^main\.(lookup|load)$`}, HitCount: make(map[int64]uint64)}
+		grp.LogicalBreakpoints[1] = lbp
+		assertNoError(grp.SetBreakpointEnabled(lbp, true), t, "EnableBreakpoint(-returns-error)")
+
+		assertReturnedError := func(fnname, typ string) {
+			t.Helper()
+			assertNoError(grp.Continue(), t, "Continue()")
+			bp := p.CurrentThread().Breakpoint()
+			if bp.Breakpoint == nil || bp.Logical != lbp {
+				t.Fatalf("not stopped on the -returns-error breakpoint")
+			}
+			assertFunctionName(p, t, fnname, "wrong function after stopping on -returns-error breakpoint")
+			v, err := proc.ReturnedError(p, p.CurrentThread(), normalLoadConfig)
+			assertNoError(err, t, "ReturnedError()")
+			if v.Kind != reflect.Interface || len(v.Children) == 0 || v.Children[0].TypeString() != typ {
+				t.Fatalf("wrong returned error %v", v)
+			}
+		}
+
+		assertReturnedError("main.lookup", "*main.NotFoundError")
+		assertNoError(grp.Continue(), t, "Continue()")
+		assertLineNumber(p, t, 35, "wrong line after runtime.Breakpoint")
+		assertReturnedError("main.lookup", "*main.NotFoundError")
+		assertReturnedError("main.load", "*fmt.wrapError")
+
+		err := grp.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit, got %v", err)
+		}
+		if lbp.TotalHitCount != 3 {
+			t.Errorf("wrong hit count %d", lbp.TotalHitCount)
+		}
+	})
+}
+
+func TestMethodSetAndImplements(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("ifaceimpl", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+
+		methodNames := func(typename string) string {
+			t.Helper()
+			_, methods, err := proc.MethodSet(p, typename)
+			assertNoError(err, t, fmt.Sprintf("MethodSet(%s)", typename))
+			var v []string
+			for _, m := range methods {
+				s := m.Name
+				if m.PtrRecv {
+					s = "*" + s
+				}
+				if m.Fn != nil {
+					s += "=" + m.Fn.Name
+				}
+				v = append(v, s)
+			}
+			return strings.Join(v, " ")
+		}
+
+		for _, tc := range []struct{ typename, tgt string }{
+			{"main.Shape", "Area Perimeter"},
+			{"main.Square", "Area=main.Square.Area Perimeter=main.Square.Perimeter *Scale=main.(*Square).Scale"},
+			{"*main.Square", "Area=main.Square.Area Perimeter=main.Square.Perimeter *Scale=main.(*Square).Scale"},
+			{"main.Circle", "*Area=main.(*Circle).Area *Perimeter=main.(*Circle).Perimeter"},
+			{"main.Named", "Area=main.Named.Area Perimeter=main.Named.Perimeter *Scale"}, // (*Named).Scale is removed by the linker
+		} {
+			if out := methodNames(tc.typename); out != tc.tgt {
+				t.Errorf("wrong methods for %s:\ngot:\t%s\nexpected:\t%s", tc.typename, out, tc.tgt)
+			}
+		}
+
+		impls, err := proc.Implements(p, "main.Shape")
+		assertNoError(err, t, "Implements()")
+		var v []string
+		for _, impl := range impls {
+			s := impl.Type
+			if impl.Itab {
+				s += "(itab)"
+			}
+			v = append(v, s)
+		}
+		if out, tgt := strings.Join(v, " "), "*main.Circle(itab) main.Grid main.Named(itab) main.Square(itab)"; out != tgt {
+			t.Errorf("wrong implementations of main.Shape:\ngot:\t%s\nexpected:\t%s", out, tgt)
+		}
+
+		impls, err = proc.Implements(p, "main.grower")
+		assertNoError(err, t, "Implements()")
+		if len(impls) != 1 || impls[0].Type != "*main.counter" {
+			t.Errorf("wrong implementations of main.grower: %v", impls)
+		}
+
+		if _, err := proc.Implements(p, "main.Square"); err == nil {
+			t.Errorf("Implements did not fail on a non-interface type")
+		}
+	})
+}
+
+func TestAllocBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("allocprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		// allocCaller returns the first function on the stack outside of the runtime.
+		allocCaller := func() *proc.Stackframe {
+			frames, err := proc.ThreadStacktrace(p, p.CurrentThread(), 20)
+			assertNoError(err, t, "ThreadStacktrace()")
+			for i := range frames {
+				if frames[i].Current.Fn != nil && !strings.HasPrefix(frames[i].Current.Fn.Name, "runtime.") {
+					return &frames[i]
+				}
+			}
+			t.Fatal("could not find caller of the allocation")
+			return nil
+		}
+
+		grp.LogicalBreakpoints[1] = &proc.LogicalBreakpoint{LogicalID: 1, Set: proc.SetBreakpoint{AllocType: "*main.Request"}, HitCount: make(map[int64]uint64)}
+
+		// An allocation breakpoint can not share runtime.mallocgc with another
+		// breakpoint.
+		bp := setFunctionBreakpoint(p, t, "runtime.mallocgc")
+		if err := grp.SetBreakpointEnabled(grp.LogicalBreakpoints[1], true); err == nil {
+			t.Fatal("allocation breakpoint enabled over an existing breakpoint")
+		}
+		for _, bp2 := range p.Breakpoints().M {
+			if bp2.LogicalID() == 1 {
+				t.Fatalf("allocation breakpoint not rolled back: %v", bp2)
+			}
+		}
+		assertNoError(p.ClearBreakpoint(bp.Addr), t, "ClearBreakpoint()")
+		assertNoError(grp.SetBreakpointEnabled(grp.LogicalBreakpoints[1], false), t, "DisableBreakpoint(-alloc)")
+
+		assertNoError(grp.SetBreakpointEnabled(grp.LogicalBreakpoints[1], true), t, "EnableBreakpoint(-alloc)")
+		for i := 0; i < 3; i++ {
+			assertNoError(grp.Continue(), t, "Continue()")
+			if frame := allocCaller(); frame.Current.Fn.Name != "main.newRequest" || frame.Call.Line != 11 {
+				t.Fatalf("wrong allocation %s:%d", frame.Current.Fn.Name, frame.Call.Line)
+			}
+		}
+		assertNoError(grp.SetBreakpointEnabled(grp.LogicalBreakpoints[1], false), t, "DisableBreakpoint(-alloc)")
+
+		grp.LogicalBreakpoints[2] = &proc.LogicalBreakpoint{LogicalID: 2, Set: proc.SetBreakpoint{AllocSize: ">1MB"}, HitCount: make(map[int64]uint64)}
+		assertNoError(grp.SetBreakpointEnabled(grp.LogicalBreakpoints[2], true), t, "EnableBreakpoint(-alloc-size)")
+		assertNoError(grp.Continue(), t, "Continue()")
+		if frame := allocCaller(); frame.Current.Fn.Name != "main.main" || frame.Call.Line != 19 {
+			t.Fatalf("wrong allocation %s:%d", frame.Current.Fn.Name, frame.Call.Line)
+		}
+
+		err := grp.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit, got %v", err)
+		}
+	})
+}
+
+func TestAllocAddrBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("allocprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		setFileBreakpoint(p, t, fixture.Source, 20)
+		assertNoError(grp.Continue(), t, "Continue()")
+		req := evalVariable(p, t, "reqs[1]")
+		addr := req.Children[0].Addr
+
+		lbp := &proc.LogicalBreakpoint{LogicalID: 2, Set: proc.SetBreakpoint{AllocAddr: addr}, HitCount: make(map[int64]uint64)}
+		grp.LogicalBreakpoints[2] = lbp
+		assertNoError(grp.SetBreakpointEnabled(lbp, true), t, "EnableBreakpoint(AllocAddr)")
+
+		if recorded, _ := grp.Recorded(); !recorded {
+			// The object is still alive, its address can not be returned by
+			// runtime.mallocgc again.
+			err := grp.Continue()
+			if _, exited := err.(proc.ErrProcessExited); !exited {
+				t.Fatalf("expected process to exit, got %v", err)
+			}
+			if lbp.TotalHitCount != 0 {
+				t.Errorf("wrong hit count %d", lbp.TotalHitCount)
+			}
+			return
+		}
+
+		grp.ChangeDirection(proc.Backward)
+		assertNoError(grp.Continue(), t, "Continue()")
+		if bp := p.CurrentThread().Breakpoint(); bp.Breakpoint == nil || bp.Logical != lbp {
+			t.Fatalf("not stopped on the AllocAddr breakpoint")
+		}
+		frames, err := proc.ThreadStacktrace(p, p.CurrentThread(), 20)
+		assertNoError(err, t, "ThreadStacktrace()")
+		for i := range frames {
+			if frames[i].Current.Fn != nil && frames[i].Current.Fn.Name == "main.main" {
+				scope, err := proc.ConvertEvalScope(p, -1, i, 0)
+				assertNoError(err, t, "ConvertEvalScope()")
+				v, err := scope.EvalExpression("i", normalLoadConfig)
+				assertNoError(err, t, "EvalExpression(i)")
+				if n, _ := constant.Int64Val(v.Value); n != 1 {
+					t.Errorf("wrong allocation, i = %d", n)
+				}
+				return
+			}
+		}
+		t.Fatalf("main.main not found on the stack")
+	})
+}
+
+func TestHeapHistogram(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		stats, err := proc.HeapHistogram(p)
+		assertNoError(err, t, "HeapHistogram()")
+		counts := make(map[string]int64)
+		for _, st := range stats {
+			t.Logf("%8d %10d %s", st.Count, st.Bytes, st.Type)
+			counts[st.Type] = st.Count
+		}
+		for typ, n := range map[string]int64{"main.small": 1000, "main.medium": 100, "main.large": 3} {
+			if counts[typ] != n {
+				t.Errorf("wrong count for %s: got %d expected %d", typ, counts[typ], n)
+			}
+		}
+		if counts["[]*main.small"] == 0 {
+			t.Errorf("backing array of smalls not found")
+		}
+	})
+}
+
+func TestHeapReferences(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		refs, err := scope.HeapReferences("list.next.next", 0)
+		assertNoError(err, t, "HeapReferences()")
+		if refs.Type != "main.node" {
+			t.Errorf("wrong type of target %q", refs.Type)
+		}
+		var chains []string
+		for _, chain := range refs.Chains {
+			var nodes []string
+			for _, n := range chain {
+				if n.Name != "" {
+					nodes = append(nodes, n.Name+n.Path)
+				} else {
+					nodes = append(nodes, n.Type+n.Path)
+				}
+			}
+			chains = append(chains, strings.Join(nodes, " -> "))
+		}
+		sort.Strings(chains)
+		if len(chains) != 2 || chains[0] != "h -> main.holder.target" || chains[1] != "main.list -> main.node.next -> main.node.next" {
+			t.Errorf("wrong chains: %q", chains)
+		}
+	})
+}
+
+func TestRetainedSize(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		smalls, err := scope.EvalExpression("smalls", normalLoadConfig)
+		assertNoError(err, t, "EvalExpression(smalls)")
+
+		for _, tc := range []struct {
+			maxDepth, maxObjects int
+			objects              int64
+			truncated            bool
+		}{
+			{0, 0, 1001, false},
+			{1, 0, 1, true},
+			{0, 10, 10, true},
+		} {
+			r, err := proc.RetainedSizeOf(p, smalls, tc.maxDepth, tc.maxObjects)
+			assertNoError(err, t, "RetainedSizeOf(smalls)")
+			if r.Shallow != 24 || r.Objects != tc.objects || r.Truncated != tc.truncated {
+				t.Errorf("depth=%d budget=%d: wrong result shallow=%d objects=%d truncated=%v", tc.maxDepth, tc.maxObjects, r.Shallow, r.Objects, r.Truncated)
+			}
+			if tc.maxDepth == 0 && tc.maxObjects == 0 {
+				if len(r.Types) != 2 || r.Types[0].Type != "main.small" || r.Types[0].Count != 1000 || r.Types[1].Type != "[]*main.small" {
+					t.Errorf("wrong types %v", r.Types)
+				}
+				if r.Retained != r.Shallow+r.Types[0].Bytes+r.Types[1].Bytes {
+					t.Errorf("wrong retained size %d", r.Retained)
+				}
+			}
+		}
+
+		list, err := scope.EvalExpression("list", normalLoadConfig)
+		assertNoError(err, t, "EvalExpression(list)")
+		r, err := proc.RetainedSizeOf(p, list, 0, 0)
+		assertNoError(err, t, "RetainedSizeOf(list)")
+		if r.Objects != 3 || len(r.Types) != 1 || r.Types[0].Type != "main.node" {
+			t.Errorf("wrong result for list: %#v", r)
+		}
+	})
+}
+
+func TestMemoryMappings(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory map not supported")
+	}
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		maps, err := proc.MemoryMappings(p)
+		assertNoError(err, t, "MemoryMappings()")
+		mainFn := p.BinInfo().LookupFunc()["main.main"][0]
+		regs, err := p.CurrentThread().Registers()
+		assertNoError(err, t, "Registers()")
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+
+		kindAt := func(addr uint64) (proc.MemoryMappingKind, int64) {
+			for _, m := range maps {
+				if addr >= m.Addr && addr < m.Addr+m.Size {
+					return m.Kind, m.GoroutineID
+				}
+			}
+			return proc.MappingOther, 0
+		}
+		if kind, _ := kindAt(mainFn.Entry); kind != proc.MappingText {
+			t.Errorf("main.main is in a %q mapping", kind)
+		}
+		if kind, goid := kindAt(regs.SP()); kind != proc.MappingStack || goid != g.ID {
+			t.Errorf("stack pointer is in a %q mapping of goroutine %d", kind, goid)
+		}
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		list, err := scope.EvalExpression("uintptr(list)", normalLoadConfig)
+		assertNoError(err, t, "EvalExpression(list)")
+		addr, _ := constant.Uint64Val(list.Value)
+		if kind, _ := kindAt(addr); kind != proc.MappingHeap {
+			t.Errorf("heap object is in a %q mapping", kind)
+		}
+		listVar, err := scope.EvalExpression("uintptr(&list)", normalLoadConfig)
+		assertNoError(err, t, "EvalExpression(&list)")
+		addr, _ = constant.Uint64Val(listVar.Value)
+		if kind, _ := kindAt(addr); kind != proc.MappingData {
+			t.Errorf("package variable is in a %q mapping", kind)
+		}
+	})
+}
+
+func TestDescribeAddress(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		evalAddr := func(expr string) uint64 {
+			t.Helper()
+			v, err := scope.EvalExpression(expr, normalLoadConfig)
+			assertNoError(err, t, "EvalExpression("+expr+")")
+			addr, _ := constant.Uint64Val(v.Value)
+			return addr
+		}
+
+		mainFn := p.BinInfo().LookupFunc()["main.main"][0]
+		info, err := proc.DescribeAddress(p, mainFn.Entry+1)
+		assertNoError(err, t, "DescribeAddress(main.main)")
+		if info.Function != mainFn || info.FunctionOffset != 1 || !strings.HasSuffix(info.File, "heapprog.go") || info.Line == 0 {
+			t.Errorf("wrong description of main.main+1: %#v", info)
+		}
+
+		info, err = proc.DescribeAddress(p, evalAddr("uintptr(&list)"))
+		assertNoError(err, t, "DescribeAddress(&list)")
+		if info.Variable != "main.list" || info.VariableOffset != 0 {
+			t.Errorf("wrong description of &list: %#v", info)
+		}
+
+		list := evalAddr("uintptr(list)")
+		info, err = proc.DescribeAddress(p, list+8)
+		assertNoError(err, t, "DescribeAddress(list)")
+		if info.HeapObject != list || info.HeapType != "main.node" || info.HeapObjectSize != 16 || info.HeapSpanBase > list || info.HeapSpanLimit <= list {
+			t.Errorf("wrong description of list+8: %#v", info)
+		}
+
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		info, err = proc.DescribeAddress(p, evalAddr("uintptr(&larges)"))
+		assertNoError(err, t, "DescribeAddress(&larges)")
+		if info.Goroutine == nil || info.Goroutine.ID != g.ID || info.FrameFunction == nil || info.FrameFunction.Name != "main.main" {
+			t.Errorf("wrong description of &larges: %#v", info)
+		}
+	})
+}
+
+func TestFindMemory(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("heapprog", t, func(p *proc.Target, grp *proc.TargetGroup, fixture protest.Fixture) {
+		assertNoError(grp.Continue(), t, "Continue()")
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+
+		ptrPattern := func(expr string) ([]byte, uint64) {
+			t.Helper()
+			v, err := scope.EvalExpression(expr, normalLoadConfig)
+			assertNoError(err, t, "EvalExpression("+expr+")")
+			addr, _ := constant.Uint64Val(v.Value)
+			pattern := make([]byte, 8)
+			binary.LittleEndian.PutUint64(pattern, addr)
+			return pattern, addr
+		}
+
+		// list is referenced by the package variable main.list
+		pattern, _ := ptrPattern("uintptr(list)")
+		matches, _, err := proc.FindMemory(p, 0, 0, pattern, 0)
+		assertNoError(err, t, "FindMemory(list)")
+		found := false
+		for _, m := range matches {
+			if m.Symbol == "main.list" && m.SymbolOffset == 0 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("main.list not found in %#v", matches)
+		}
+
+		// list.next.next is referenced by the next field of list.next
+		pattern, _ = ptrPattern("uintptr(list.next.next)")
+		_, next := ptrPattern("uintptr(list.next)")
+		matches, _, err = proc.FindMemory(p, 0, 0, pattern, 0)
+		assertNoError(err, t, "FindMemory(list.next.next)")
+		found = false
+		for _, m := range matches {
+			if m.Addr == next && m.HeapObject == next && m.HeapType == "main.node" && m.Mapping != nil {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("list.next not found in %#v", matches)
+		}
+
+		// explicit range and maximum number of matches
+		matches, truncated, err := proc.FindMemory(p, next, next+16, pattern, 1)
+		assertNoError(err, t, "FindMemory(range)")
+		if len(matches) != 1 || matches[0].Addr != next || truncated {
+			t.Errorf("wrong matches in range: %#v %v", matches, truncated)
+		}
+	})
+}
+
 func TestStepOut(t *testing.T) {
 	testseq2(t, "testnextprog", "main.helloworld", []seqTest{{contContinue, 13}, {contStepout, 35}})
 }
@@ -4663,20 +5520,29 @@ func TestDump(t *testing.T) {
 		return fmt.Sprintf("currentPC=%#x callPC=%#x frameOff=%#x\n", frame.Current.PC, frame.Call.PC, frame.FrameOffset())
 	}
 
-	makeDump := func(p *proc.Target, corePath, exePath string, flags proc.DumpFlags) *proc.Target {
+	makeDumpState := func(p *proc.Target, corePath, exePath string, flags proc.DumpFlags, state *proc.DumpState) *proc.Target {
 		fh, err := os.Create(corePath)
 		assertNoError(err, t, "Create()")
-		var state proc.DumpState
-		p.Dump(fh, flags, &state)
+		var out elfwriter.WriteCloserSeeker = fh
+		if strings.HasSuffix(corePath, ".gz") {
+			out, err = elfwriter.NewGzipWriter(fh)
+			assertNoError(err, t, "NewGzipWriter()")
+		}
+		p.Dump(out, proc.DumpOptions{Flags: flags}, state)
 		assertNoError(state.Err, t, "Dump()")
 		if state.ThreadsDone != state.ThreadsTotal || state.MemDone != state.MemTotal || !state.AllDone || state.Dumping || state.Canceled {
-			t.Fatalf("bad DumpState %#v", &state)
+			t.Fatalf("bad DumpState %#v", state)
 		}
 		c, err := core.OpenCore(corePath, exePath, nil)
 		assertNoError(err, t, "OpenCore()")
+		t.Cleanup(func() { c.Detach(false) })
 		return c.Selected
 	}
 
+	makeDump := func(p *proc.Target, corePath, exePath string, flags proc.DumpFlags) *proc.Target {
+		return makeDumpState(p, corePath, exePath, flags, &proc.DumpState{})
+	}
+
 	testDump := func(p, c *proc.Target) {
 		if p.Pid() != c.Pid() {
 			t.Errorf("Pid mismatch %x %x", p.Pid(), c.Pid())
@@ -4772,6 +5638,29 @@ func TestDump(t *testing.T) {
 			defer os.Remove(corePathPlatIndep)
 			testDump(p, c2)
 		}
+
+		t.Logf("testing snapshot dump")
+		corePathSnapshot := filepath.Join(fixture.BuildDir, "coredump-snapshot")
+		snapshotDone := false
+		c4 := makeDumpState(p, corePathSnapshot, fixture.Path, proc.DumpSnapshot, &proc.DumpState{SnapshotDone: func() { snapshotDone = true }})
+		defer os.Remove(corePathSnapshot)
+		testDump(p, c4)
+		if !snapshotDone {
+			t.Errorf("SnapshotDone not called")
+		}
+
+		t.Logf("testing filtered and compressed dump")
+		corePathFiltered := filepath.Join(fixture.BuildDir, "coredump-filtered.gz")
+		c3 := makeDump(p, corePathFiltered, fixture.Path, proc.DumpExcludeFileBacked|proc.DumpHeapInUse)
+		defer os.Remove(corePathFiltered)
+		testDump(p, c3)
+		fi, err := os.Stat(corePath)
+		assertNoError(err, t, "Stat(corePath)")
+		fi2, err := os.Stat(corePathFiltered)
+		assertNoError(err, t, "Stat(corePathFiltered)")
+		if fi2.Size() >= fi.Size() {
+			t.Errorf("filtered and compressed dump is not smaller than the normal dump: %d %d", fi2.Size(), fi.Size())
+		}
 	})
 }
 
@@ -0,0 +1,302 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"go/parser"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// HeapRefNode is a step in a chain of references leading to a memory
+// location.
+type HeapRefNode struct {
+	// Name is the name of the variable for package variables and local
+	// variables, it is empty for heap objects.
+	Name string
+	// GoroutineID and Frame identify the stack frame of local variables,
+	// GoroutineID is 0 for package variables and heap objects.
+	GoroutineID int64
+	Frame       int
+	Addr        uint64
+	Type        string
+	// Path is the path, relative to the variable or heap object, of the
+	// pointer to the next step of the chain, for example ".next" or
+	// "[3].data".
+	Path string
+}
+
+// HeapReferences is the result of (*EvalScope).HeapReferences.
+type HeapReferences struct {
+	// Addr and Size describe the memory being referenced, when the target
+	// address is inside a heap object they describe the whole object.
+	Addr, Size uint64
+	// Type is the type of the heap object, empty if the target address
+	// isn't inside a heap object.
+	Type string
+	// Chains contains a chain of references for each variable or heap
+	// object that directly references the target. Each chain ends with the
+	// direct referrer and starts with a package or local variable or, if
+	// the referrer can not be reached from any variable, with the referrer
+	// itself.
+	Chains [][]HeapRefNode
+	// Truncated is true if more than the requested number of referrers
+	// exist.
+	Truncated bool
+}
+
+// heapRefSrc is a variable, a heap object or a value outside of the heap
+// (for example a variable on the stack that doesn't have a name)
+// containing pointers.
+type heapRefSrc struct {
+	root int // index of the variable in heapInfo.roots, -1 otherwise
+	s    *heapSpan
+	idx  uint64
+	addr uint64       // address of values outside of the heap
+	typ  godwarf.Type // type of values outside of the heap
+}
+
+// key returns the address of the heap object or value.
+func (src heapRefSrc) key() uint64 {
+	if src.s != nil {
+		return src.s.base + src.idx*src.s.elemsize
+	}
+	return src.addr
+}
+
+type heapRefEdge struct {
+	src heapRefSrc
+	off int64 // offset of the pointer inside src
+}
+
+// HeapReferences finds the package variables, local variables and heap
+// objects that contain pointers to the memory expr refers to and the
+// chains of references that lead to them from variables.
+//
+// If expr is a pointer, slice, string, map or channel the memory it points
+// to is searched, if it is an integer constant it is interpreted as an
+// address, otherwise references to the memory storing expr are searched.
+// If the address is inside a heap object references to any part of the
+// object are searched.
+//
+// At most maxChains referrers are returned, if maxChains is 0 all of them
+// are returned.
+func (scope *EvalScope) HeapReferences(expr string, maxChains int) (*HeapReferences, error) {
+	if _, err := scope.target.Valid(); err != nil {
+		return nil, err
+	}
+	addr, size, err := scope.refTarget(expr)
+	if err != nil {
+		return nil, err
+	}
+	h, err := scope.target.loadHeap(true)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &HeapReferences{Addr: addr, Size: size}
+	tgt, tgtIdx, inHeap := h.findObject(addr)
+	if inHeap {
+		r.Addr = tgt.objectAddr(tgtIdx)
+		r.Size = tgt.elemsize - tgt.header
+		r.Type = h.typeName(tgt, tgtIdx)
+	}
+	if r.Size == 0 {
+		r.Size = 1
+	}
+
+	visited := make(map[uint64]bool)
+	parent := make(map[uint64]heapRefEdge)
+	if inHeap {
+		// References going through the target do not keep it alive.
+		visited[heapRefSrc{s: tgt, idx: tgtIdx}.key()] = true
+	}
+
+	var referrers []heapRefEdge
+	isReferrer := make(map[heapRefSrc]bool)
+	var queue []heapRefSrc
+
+	scan := func(src heapRefSrc, reach bool) {
+		fn := func(off int64, ptr uint64, typ godwarf.Type) {
+			if ptr >= r.Addr && ptr < r.Addr+r.Size && !isReferrer[src] {
+				isReferrer[src] = true
+				referrers = append(referrers, heapRefEdge{src, off})
+			}
+			if !reach || ptr == 0 {
+				return
+			}
+			next := heapRefSrc{root: -1, addr: ptr, typ: typ}
+			if s, idx, ok := h.findObject(ptr); ok {
+				next = heapRefSrc{root: -1, s: s, idx: idx}
+			} else if typ == nil || typ.Size() <= 0 || !h.hasPointers(typ) {
+				return
+			}
+			if visited[next.key()] {
+				return
+			}
+			visited[next.key()] = true
+			parent[next.key()] = heapRefEdge{src, off}
+			queue = append(queue, next)
+		}
+		switch {
+		case src.root >= 0:
+			v := h.roots[src.root].v
+			if v.Flags&VariableFakeAddress == 0 {
+				if s, idx, ok := h.findObject(v.Addr); ok {
+					// The pointers contained in escaped variables are attributed
+					// to the variable rather than to the heap object storing it.
+					visited[heapRefSrc{s: s, idx: idx}.key()] = true
+				} else {
+					visited[v.Addr] = true
+				}
+			}
+			h.pointers(v.mem, v.Addr, v.DwarfType, 1, fn)
+			return
+		case src.s == nil:
+			h.pointers(h.mem, src.addr, src.typ, 1, fn)
+			return
+		}
+		if src.s.noscan {
+			return
+		}
+		if typ := src.s.types[src.idx]; typ != nil {
+			h.pointers(h.mem, src.s.objectAddr(src.idx), typ, src.s.objectCount(src.idx), fn)
+		} else {
+			h.conservativePointers(src.s.objectAddr(src.idx), src.s.elemsize-src.s.header, fn)
+		}
+	}
+
+	for i := range h.roots {
+		scan(heapRefSrc{root: i}, true)
+	}
+	for len(queue) > 0 {
+		src := queue[0]
+		queue = queue[1:]
+		scan(src, true)
+	}
+	// Objects that can not be reached from any variable, they will be freed
+	// by the next garbage collection.
+	for _, s := range h.spans {
+		if s.noscan {
+			continue
+		}
+		for i := range s.alloc {
+			if src := (heapRefSrc{root: -1, s: s, idx: uint64(i)}); s.alloc[i] && !visited[src.key()] {
+				scan(src, false)
+			}
+		}
+	}
+
+	if maxChains > 0 && len(referrers) > maxChains {
+		referrers = referrers[:maxChains]
+		r.Truncated = true
+	}
+	for _, e := range referrers {
+		chain := []HeapRefNode{h.refNode(e)}
+		for cur := e.src; cur.root < 0; {
+			pe, ok := parent[cur.key()]
+			if !ok {
+				break
+			}
+			chain = append(chain, h.refNode(pe))
+			cur = pe.src
+		}
+		for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+			chain[i], chain[j] = chain[j], chain[i]
+		}
+		r.Chains = append(r.Chains, chain)
+	}
+	return r, nil
+}
+
+// refTarget returns the address and size of the memory expr refers to,
+// see HeapReferences.
+func (scope *EvalScope) refTarget(expr string) (uint64, uint64, error) {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return 0, 0, err
+	}
+	v, err := scope.evalAST(t)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v.Unreadable != nil {
+		return 0, 0, v.Unreadable
+	}
+	if v.Value != nil && v.Addr == 0 && v.Value.Kind() == constant.Int {
+		addr, ok := constant.Uint64Val(v.Value)
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid address %s", v.Value)
+		}
+		return addr, 1, nil
+	}
+	switch v.Kind {
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Slice, reflect.String, reflect.Map, reflect.Chan:
+		ptrSize := int64(scope.BinInfo.Arch.PtrSize())
+		addr, err := readUintRaw(v.mem, v.Addr, ptrSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		if addr == 0 {
+			return 0, 0, errors.New("nil pointer")
+		}
+		size := uint64(1)
+		if ptyp, ok := v.RealType.(*godwarf.PtrType); ok && ptyp.Type.Size() > 0 {
+			size = uint64(ptyp.Type.Size())
+		}
+		return addr, size, nil
+	}
+	if v.Addr == 0 || v.Flags&VariableFakeAddress != 0 {
+		return 0, 0, errors.New("expression does not have an address")
+	}
+	return v.Addr, uint64(v.RealType.Size()), nil
+}
+
+// refNode describes the source of e.
+func (h *heapInfo) refNode(e heapRefEdge) HeapRefNode {
+	if e.src.root >= 0 {
+		root := h.roots[e.src.root]
+		return HeapRefNode{
+			Name:        root.v.Name,
+			GoroutineID: root.goroutineID,
+			Frame:       root.frame,
+			Addr:        root.v.Addr,
+			Type:        heapTypeName(root.v.DwarfType),
+			Path:        fieldPath(root.v.DwarfType, e.off),
+		}
+	}
+	if e.src.s == nil {
+		return HeapRefNode{Addr: e.src.addr, Type: heapTypeName(e.src.typ), Path: fieldPath(e.src.typ, e.off)}
+	}
+	n := HeapRefNode{Addr: e.src.s.objectAddr(e.src.idx), Type: h.typeName(e.src.s, e.src.idx)}
+	typ := e.src.s.types[e.src.idx]
+	switch {
+	case typ == nil || typ.Size() <= 0:
+		n.Path = fmt.Sprintf("+%#x", e.off)
+	case e.src.s.objectCount(e.src.idx) > 1:
+		n.Path = fmt.Sprintf("[%d]%s", e.off/typ.Size(), fieldPath(typ, e.off%typ.Size()))
+	default:
+		n.Path = fieldPath(typ, e.off)
+	}
+	return n
+}
+
+// fieldPath returns the path of the field at offset off of a value of type
+// typ.
+func fieldPath(typ godwarf.Type, off int64) string {
+	switch typ := godwarf.ResolveTypedef(typ).(type) {
+	case *godwarf.StructType:
+		for _, f := range typ.Field {
+			if off >= f.ByteOffset && off < f.ByteOffset+f.Type.Size() {
+				return "." + f.Name + fieldPath(f.Type, off-f.ByteOffset)
+			}
+		}
+	case *godwarf.ArrayType:
+		if sz := typ.Type.Size(); sz > 0 {
+			return fmt.Sprintf("[%d]%s", off/sz, fieldPath(typ.Type, off%sz))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package proc
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// RetainedSize is the result of RetainedSizeOf.
+type RetainedSize struct {
+	// Shallow is the size of the value itself.
+	Shallow int64
+	// Retained is Shallow plus the size of all the heap objects reachable
+	// from the value, each object is counted once.
+	Retained int64
+	// Objects is the number of heap objects reachable from the value.
+	Objects int64
+	// Types contains the heap objects reachable from the value grouped by
+	// type, sorted by decreasing size.
+	Types []HeapTypeStats
+	// Truncated is true if the walk stopped because the depth or the
+	// object budget were exhausted, the sizes are then lower bounds.
+	Truncated bool
+}
+
+// RetainedSizeOf walks the heap objects reachable from v by following
+// pointers, slices, strings, maps, channels and interfaces and returns
+// their total size, broken down by type.
+//
+// Objects reachable from v are counted even if they are also reachable
+// from other variables. Pointers are followed at most maxDepth times and at
+// most maxObjects objects are visited, if either limit is 0 the
+// corresponding limit is disabled. If maxDepth is negative only the shallow
+// size is computed.
+func RetainedSizeOf(t *Target, v *Variable, maxDepth, maxObjects int) (*RetainedSize, error) {
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	r := &RetainedSize{Shallow: v.RealType.Size(), Retained: v.RealType.Size()}
+	if maxDepth < 0 {
+		return r, nil
+	}
+	h, err := t.loadHeap(false)
+	if err != nil {
+		return nil, err
+	}
+
+	type retainedObj struct {
+		s     *heapSpan
+		idx   uint64
+		addr  uint64       // address of values outside of the heap
+		typ   godwarf.Type // type of values outside of the heap
+		depth int
+	}
+	visited := make(map[uint64]bool)
+	stats := make(map[string]*HeapTypeStats)
+	var queue []retainedObj
+
+	walk := func(depth int) heapPointerFunc {
+		return func(_ int64, ptr uint64, typ godwarf.Type) {
+			if ptr == 0 {
+				return
+			}
+			s, idx, ok := h.findObject(ptr)
+			if !ok {
+				// Values outside of the heap (for example arrays backing
+				// slices that do not escape) are not counted but the heap
+				// objects they reference are.
+				if typ == nil || typ.Size() <= 0 || !h.hasPointers(typ) || visited[ptr] {
+					return
+				}
+				visited[ptr] = true
+				queue = append(queue, retainedObj{addr: ptr, typ: typ, depth: depth})
+				return
+			}
+			key := s.base + idx*s.elemsize
+			if visited[key] {
+				return
+			}
+			if (maxDepth > 0 && depth >= maxDepth) || (maxObjects > 0 && r.Objects >= int64(maxObjects)) {
+				r.Truncated = true
+				return
+			}
+			visited[key] = true
+			s.setType(idx, ptr, typ)
+			r.Objects++
+			r.Retained += int64(s.elemsize)
+			name := h.typeName(s, idx)
+			st := stats[name]
+			if st == nil {
+				st = &HeapTypeStats{Type: name}
+				stats[name] = st
+			}
+			st.Count++
+			st.Bytes += int64(s.elemsize)
+			if !s.noscan {
+				queue = append(queue, retainedObj{s: s, idx: idx, depth: depth + 1})
+			}
+		}
+	}
+
+	if v.Flags&VariableFakeAddress == 0 {
+		if s, idx, ok := h.findObject(v.Addr); ok {
+			// The object storing v is not part of the retained size.
+			visited[s.base+idx*s.elemsize] = true
+		}
+	}
+	if v.Kind == reflect.Slice && v.Base != 0 && v.Cap > 0 && v.fieldType != nil {
+		if _, _, ok := h.findObject(v.Base); !ok {
+			// The type of the pointer to the backing array only describes its
+			// first element.
+			visited[v.Base] = true
+			queue = append(queue, retainedObj{addr: v.Base, typ: fakeArrayType(uint64(v.Cap), v.fieldType)})
+		}
+	}
+	if v.DwarfType != nil {
+		h.pointers(v.mem, v.Addr, v.DwarfType, 1, walk(0))
+	}
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		fn := walk(obj.depth)
+		if obj.s == nil {
+			h.pointers(h.mem, obj.addr, obj.typ, 1, fn)
+			continue
+		}
+		if typ := obj.s.types[obj.idx]; typ != nil {
+			h.pointers(h.mem, obj.s.objectAddr(obj.idx), typ, obj.s.objectCount(obj.idx), fn)
+		} else {
+			h.conservativePointers(obj.s.objectAddr(obj.idx), obj.s.elemsize-obj.s.header, fn)
+		}
+	}
+
+	for _, st := range stats {
+		r.Types = append(r.Types, *st)
+	}
+	sort.Slice(r.Types, func(i, j int) bool {
+		if r.Types[i].Bytes != r.Types[j].Bytes {
+			return r.Types[i].Bytes > r.Types[j].Bytes
+		}
+		return r.Types[i].Type < r.Types[j].Type
+	})
+	return r, nil
+}
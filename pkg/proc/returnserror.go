@@ -0,0 +1,146 @@
+package proc
+
+import (
+	"debug/dwarf"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// returnsErrorLocations returns the addresses of the return instructions
+// of all functions matching the regular expression lbp.Set.ReturnsError
+// that return an error as their last result (or a value of type
+// lbp.Set.ErrorType, if set).
+// Inlined calls are not included.
+func returnsErrorLocations(t *Target, lbp *LogicalBreakpoint) ([]uint64, error) {
+	re, err := regexp.Compile(lbp.Set.ReturnsError)
+	if err != nil {
+		return nil, fmt.Errorf("invalid function regular expression %q: %v", lbp.Set.ReturnsError, err)
+	}
+	bi := t.BinInfo()
+	var addrs []uint64
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.Entry == 0 || fn.cu == nil || !re.MatchString(fn.Name) {
+			continue
+		}
+		typ, err := lastResultType(bi, fn)
+		if err != nil || typ == nil {
+			continue
+		}
+		if typ.String() != "error" && (lbp.Set.ErrorType == "" || typ.String() != lbp.Set.ErrorType) {
+			continue
+		}
+		rets, err := returnInstructions(t, fn)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, rets...)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no function matching %q returns an error", lbp.Set.ReturnsError)
+	}
+	return addrs, nil
+}
+
+// returnInstructions returns the addresses of the return instructions of
+// fn.
+func returnInstructions(t *Target, fn *Function) ([]uint64, error) {
+	text, err := Disassemble(t.Memory(), nil, t.Breakpoints(), t.BinInfo(), fn.Entry, fn.End)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []uint64
+	for _, instr := range text {
+		if instr.IsRet() {
+			addrs = append(addrs, instr.Loc.PC)
+		}
+	}
+	return addrs, nil
+}
+
+// lastResultType returns the type of the last result of fn, or nil if fn
+// has no results.
+func lastResultType(bi *BinaryInfo, fn *Function) (godwarf.Type, error) {
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return nil, err
+	}
+	var typ godwarf.Type
+	for _, entry := range dwarfTree.Children {
+		if entry.Tag != dwarf.TagFormalParameter {
+			continue
+		}
+		if isret, _ := entry.Val(dwarf.AttrVarParam).(bool); !isret {
+			continue
+		}
+		_, typ, err = readVarEntry(entry, fn.cu.image)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return typ, nil
+}
+
+// returnsErrorCallback returns the callback of the breaklets of a
+// breakpoint created with -returns-error, which only stops if the function
+// is returning a non-nil error (of type lbp.Set.ErrorType, if set).
+func returnsErrorCallback(lbp *LogicalBreakpoint) func(Thread, *Target) (bool, error) {
+	return func(th Thread, t *Target) (bool, error) {
+		v, err := ReturnedError(t, th, loadSingleValue)
+		if err == nil && !v.isNil() && (lbp.Set.ErrorType == "" || errorDynamicType(v) == lbp.Set.ErrorType) {
+			return true, nil
+		}
+		// The breakpoint isn't hit if the function returns nil, don't count it.
+		uncountHit(lbp, th)
+		if err != nil {
+			return false, fmt.Errorf("could not read returned error: %v", err)
+		}
+		return false, nil
+	}
+}
+
+// errorDynamicType returns the name of the dynamic type of v if it is an
+// interface, or the type of v otherwise.
+func errorDynamicType(v *Variable) string {
+	if v.Kind == reflect.Interface && len(v.Children) > 0 && v.Children[0].DwarfType != nil {
+		return v.Children[0].DwarfType.String()
+	}
+	if v.DwarfType == nil {
+		return ""
+	}
+	return v.DwarfType.String()
+}
+
+// ReturnedError returns the last return value of the function containing
+// the return instruction thread th is stopped at.
+func ReturnedError(t *Target, th Thread, cfg LoadConfig) (*Variable, error) {
+	scope, err := GoroutineScope(t, th)
+	if err != nil {
+		scope, err = ThreadScope(t, th)
+		if err != nil {
+			return nil, err
+		}
+	}
+	vars, err := scope.Locals(0, "")
+	if err != nil {
+		return nil, err
+	}
+	var ret *Variable
+	for _, v := range vars {
+		if v.Flags&VariableReturnArgument != 0 {
+			ret = v
+		}
+	}
+	if ret == nil {
+		return nil, errors.New("could not find return value")
+	}
+	ret.loadValue(cfg)
+	if ret.Unreadable != nil {
+		return nil, ret.Unreadable
+	}
+	return ret, nil
+}
@@ -0,0 +1,280 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SchedP is a P of the Go scheduler, the resource an M needs to execute Go
+// code.
+type SchedP struct {
+	ID     int
+	Status string
+	// M is the ID of the M the P is attached to, or -1.
+	M int64
+	// CurG is the ID of the goroutine running on M, or 0.
+	CurG int64
+	// RunQ contains the IDs of the goroutines in the local run queue of
+	// the P, starting with the goroutine in runnext, if any.
+	RunQ        []int64
+	SchedTick   uint64
+	SyscallTick uint64
+}
+
+// SchedM is an M of the Go scheduler, an OS thread.
+type SchedM struct {
+	ID       int64
+	ThreadID int
+	// P is the ID of the P attached to the M, or -1.
+	P int
+	// CurG is the ID of the goroutine running on the M, or 0.
+	CurG int64
+	// LockedG is the ID of the goroutine locked to the M by
+	// runtime.LockOSThread, or 0.
+	LockedG  int64
+	Spinning bool
+	Blocked  bool
+}
+
+// SchedInfo is the state of the Go scheduler, see SchedulerInfo.
+type SchedInfo struct {
+	GOMAXPROCS int
+	// GCPhase is one of "off", "mark" or "mark termination".
+	GCPhase string
+	// GCWaiting is true if the garbage collector is waiting to stop the
+	// world.
+	GCWaiting bool
+	// GlobalRunQSize is the number of goroutines in the global run queue.
+	GlobalRunQSize int
+	IdlePs         int
+	SpinningMs     int
+	Ps             []SchedP
+	Ms             []SchedM
+}
+
+// schedMaxMs is the maximum number of Ms read from runtime.allm.
+const schedMaxMs = 1 << 16
+
+// SchedulerInfo reads the state of the Go scheduler from the runtime
+// globals runtime.allp, runtime.allm, runtime.sched, runtime.gomaxprocs and
+// runtime.gcphase.
+func SchedulerInfo(t *Target) (*SchedInfo, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	scope := globalScope(t, bi, bi.Images[0], t.Memory())
+	gType, err := bi.findType("runtime.g")
+	if err != nil {
+		return nil, err
+	}
+	goid := func(gaddr uint64) int64 {
+		if gaddr == 0 {
+			return 0
+		}
+		// +rtype -field g.goid int64|uint64
+		id, ok := schedUint(newVariable("", gaddr, gType, bi, t.Memory()), "goid")
+		if !ok {
+			return 0
+		}
+		return int64(id)
+	}
+
+	r := &SchedInfo{}
+
+	// +rtype -var gomaxprocs int32
+	if v, err := scope.findGlobal("runtime", "gomaxprocs"); err == nil {
+		n, _ := schedUint(v)
+		r.GOMAXPROCS = int(n)
+	}
+
+	// +rtype -var gcphase uint32
+	if v, err := scope.findGlobal("runtime", "gcphase"); err == nil {
+		phase, _ := schedUint(v)
+		switch phase {
+		case 0:
+			r.GCPhase = "off"
+		case 1:
+			r.GCPhase = "mark"
+		case 2:
+			r.GCPhase = "mark termination"
+		default:
+			r.GCPhase = fmt.Sprintf("unknown (%d)", phase)
+		}
+	}
+
+	// +rtype -var sched schedt
+	// +rtype -field schedt.npidle int32|internal/runtime/atomic.Int32
+	// +rtype -field schedt.nmspinning int32|internal/runtime/atomic.Int32
+	// +rtype -field schedt.gcwaiting uint32|internal/runtime/atomic.Bool
+	if sched, err := scope.findGlobal("runtime", "sched"); err == nil {
+		if n, ok := schedUint(sched, "runq", "size"); ok {
+			r.GlobalRunQSize = int(n)
+		} else {
+			n, _ := schedUint(sched, "runqsize")
+			r.GlobalRunQSize = int(n)
+		}
+		n, _ := schedUint(sched, "npidle")
+		r.IdlePs = int(n)
+		n, _ = schedUint(sched, "nmspinning")
+		r.SpinningMs = int(n)
+		n, _ = schedUint(sched, "gcwaiting")
+		r.GCWaiting = n != 0
+	}
+
+	// +rtype -var allm *m
+	// +rtype -field m.id int64
+	// +rtype -field m.procid uint64
+	// +rtype -field m.curg *g
+	// +rtype -field m.lockedg guintptr
+	// +rtype -field m.p puintptr
+	// +rtype -field m.spinning bool
+	// +rtype -field m.blocked bool
+	// +rtype -field m.alllink *m
+	allm, err := scope.findGlobal("runtime", "allm")
+	if err != nil {
+		return nil, err
+	}
+	var mAddrs, mPAddrs []uint64
+	for m := allm.maybeDereference(); m.Addr != 0 && m.Unreadable == nil && len(r.Ms) < schedMaxMs; {
+		sm := SchedM{P: -1}
+		id, _ := schedUint(m, "id")
+		sm.ID = int64(id)
+		procid, _ := schedUint(m, "procid")
+		sm.ThreadID = int(procid)
+		curg, _ := schedUint(m, "curg")
+		sm.CurG = goid(curg)
+		lockedg, _ := schedUint(m, "lockedg")
+		sm.LockedG = goid(lockedg)
+		spinning, _ := schedUint(m, "spinning")
+		sm.Spinning = spinning != 0
+		blocked, _ := schedUint(m, "blocked")
+		sm.Blocked = blocked != 0
+		paddr, _ := schedUint(m, "p")
+		r.Ms = append(r.Ms, sm)
+		mAddrs = append(mAddrs, m.Addr)
+		mPAddrs = append(mPAddrs, paddr)
+
+		next, err := m.structMember("alllink")
+		if err != nil {
+			break
+		}
+		m = next.maybeDereference()
+	}
+	mByAddr := make(map[uint64]*SchedM) // M by address of its runtime.m struct
+	mByP := make(map[uint64]*SchedM)    // M by address of the runtime.p struct attached to it
+	for i := range r.Ms {
+		mByAddr[mAddrs[i]] = &r.Ms[i]
+		if mPAddrs[i] != 0 {
+			mByP[mPAddrs[i]] = &r.Ms[i]
+		}
+	}
+
+	// +rtype -var allp []*p
+	// +rtype -field p.id int32
+	// +rtype -field p.status uint32
+	// +rtype -field p.m muintptr
+	// +rtype -field p.runqhead uint32
+	// +rtype -field p.runqtail uint32
+	// +rtype -field p.runq [256]guintptr
+	// +rtype -field p.runnext guintptr
+	// +rtype -field p.schedtick uint32
+	// +rtype -field p.syscalltick uint32
+	allp, err := scope.findGlobal("runtime", "allp")
+	if err != nil {
+		return nil, err
+	}
+	if allp.Kind != reflect.Slice {
+		return nil, errors.New("unexpected type for runtime.allp")
+	}
+	allp.loadValue(LoadConfig{MaxArrayValues: int(allp.Len)})
+	if allp.Unreadable != nil {
+		return nil, allp.Unreadable
+	}
+	for i := range allp.Children {
+		p := allp.Children[i].maybeDereference()
+		if p.Addr == 0 || p.Unreadable != nil {
+			continue
+		}
+		sp := SchedP{M: -1}
+		id, _ := schedUint(p, "id")
+		sp.ID = int(id)
+		status, _ := schedUint(p, "status")
+		sp.Status = pStatusString(status)
+		maddr, _ := schedUint(p, "m")
+		if sm := mByAddr[maddr]; sm != nil {
+			sp.M = sm.ID
+			sp.CurG = sm.CurG
+		}
+		if sm := mByP[p.Addr]; sm != nil {
+			sm.P = sp.ID
+		}
+		sp.SchedTick, _ = schedUint(p, "schedtick")
+		sp.SyscallTick, _ = schedUint(p, "syscalltick")
+		if runnext, _ := schedUint(p, "runnext"); runnext != 0 {
+			sp.RunQ = append(sp.RunQ, goid(runnext))
+		}
+		sp.RunQ = append(sp.RunQ, pRunQ(p, goid)...)
+		r.Ps = append(r.Ps, sp)
+	}
+
+	return r, nil
+}
+
+// pRunQ returns the IDs of the goroutines in the local run queue of p.
+func pRunQ(p *Variable, goid func(uint64) int64) []int64 {
+	head, ok1 := schedUint(p, "runqhead")
+	tail, ok2 := schedUint(p, "runqtail")
+	runq, err := p.structMember("runq")
+	if !ok1 || !ok2 || err != nil || runq.Kind != reflect.Array || runq.Len == 0 {
+		return nil
+	}
+	var r []int64
+	for i := uint32(head); i != uint32(tail) && len(r) < int(runq.Len); i++ {
+		g, err := runq.sliceAccess(int(i % uint32(runq.Len)))
+		if err != nil {
+			break
+		}
+		gaddr, ok := schedUint(g)
+		if !ok {
+			break
+		}
+		r = append(r, goid(gaddr))
+	}
+	return r
+}
+
+func pStatusString(status uint64) string {
+	switch status {
+	case 0:
+		return "idle"
+	case 1:
+		return "running"
+	case 2:
+		return "syscall"
+	case 3:
+		return "gcstop"
+	case 4:
+		return "dead"
+	default:
+		return fmt.Sprintf("unknown (%d)", status)
+	}
+}
+
+// schedUint reads the integer (or pointer, or atomic integer) field of v
+// at the specified path. If no path is specified the value of v itself is
+// read.
+func schedUint(v *Variable, path ...string) (uint64, bool) {
+	if len(path) > 0 {
+		v = structMemberMulti(v, path...)
+		if v == nil {
+			return 0, false
+		}
+	}
+	if v.RealType == nil || v.RealType.Size() > 8 {
+		return 0, false
+	}
+	n, err := readUintRaw(v.mem, v.Addr, v.RealType.Size())
+	return n, err == nil
+}
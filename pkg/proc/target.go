@@ -25,6 +25,10 @@ var (
 
 	// ErrProcessDetached indicates that we detached from the target process.
 	ErrProcessDetached = errors.New("detached from the process")
+
+	// ErrWriteRecorded is returned when trying to write the memory of a core
+	// file or of a recording.
+	ErrWriteRecorded = errors.New("can not write the memory of a recorded process")
 )
 
 type LaunchFlags uint8
@@ -68,6 +72,10 @@ type Target struct {
 	gcache goroutineCache
 	iscgo  *bool
 
+	// heapCache is the heap of the target, read by loadHeap.
+	// This must be cleared whenever the target is resumed.
+	heapCache *heapInfo
+
 	// exitStatus is the exit status of the process we are debugging.
 	// Saved here to relay to any future commands.
 	exitStatus int
@@ -78,6 +86,13 @@ type Target struct {
 	fakeMemoryRegistry    []*compositeMemory
 	fakeMemoryRegistryMap map[string]*compositeMemory
 
+	// dataRace is the data race that caused the target to stop, see
+	// DataRace. dataRaces are the data races reported by the race detector
+	// whose goroutines haven't yet returned to the access that triggered the
+	// report, indexed by the address of the breakpoint waiting for them.
+	dataRace  *DataRace
+	dataRaces map[uint64]*DataRace
+
 	partOfGroup bool
 }
 
@@ -199,6 +214,7 @@ func (grp *TargetGroup) newTarget(p ProcessInternal, pid int, currentThread Thre
 	t.createUnrecoveredPanicBreakpoint()
 	t.createFatalThrowBreakpoint()
 	t.createPluginOpenBreakpoint()
+	t.createDataRaceBreakpoint()
 
 	t.gcache.init(p.BinInfo())
 	t.fakeMemoryRegistryMap = make(map[string]*compositeMemory)
@@ -260,12 +276,40 @@ func (t *Target) SupportsFunctionCalls() bool {
 func (t *Target) ClearCaches() {
 	t.clearFakeMemory()
 	t.gcache.Clear()
+	t.heapCache = nil
 	t.BinInfo().moduleDataCache = nil
 	for _, thread := range t.ThreadList() {
 		thread.Common().g = nil
 	}
 }
 
+// WriteMemory writes data to the memory of the target at addr and clears
+// the caches that could contain stale values. Writing the memory of core
+// files and recordings, or over a breakpoint, is refused.
+func (t *Target) WriteMemory(addr uint64, data []byte) error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	if recorded, _ := t.recman.Recorded(); recorded {
+		return ErrWriteRecorded
+	}
+	end := addr + uint64(len(data))
+	for _, bp := range t.Breakpoints().M {
+		if len(bp.OriginalData) > 0 && addr < bp.Addr+uint64(len(bp.OriginalData)) && bp.Addr < end {
+			return fmt.Errorf("can not write over the breakpoint at %#x", bp.Addr)
+		}
+	}
+	n, err := t.Memory().WriteMemory(addr, data)
+	t.ClearCaches()
+	if err != nil {
+		return err
+	}
+	if n != len(data) {
+		return fmt.Errorf("only %d of %d bytes written", n, len(data))
+	}
+	return nil
+}
+
 // Restart will start the process group over from the location specified by the "from" locspec.
 // This is only useful for recorded targets.
 // Restarting of a normal process happens at a higher level (debugger.Restart).
@@ -69,6 +69,7 @@ func (grp *TargetGroup) Continue() error {
 		}
 		dbp.Breakpoints().WatchOutOfScope = nil
 		dbp.clearHardcodedBreakpoints()
+		dbp.clearDataRace()
 	}
 	grp.cctx.CheckAndClearManualStopRequest()
 	defer func() {
@@ -346,6 +346,12 @@ func enableBreakpointOnTarget(p *Target, lbp *LogicalBreakpoint) error {
 	var err error
 	var addrs []uint64
 	switch {
+	case lbp.Set.AllocType != "" || lbp.Set.AllocSize != "":
+		addrs, err = allocBreakpointLocations(p)
+	case lbp.Set.ReturnsError != "":
+		addrs, err = returnsErrorLocations(p, lbp)
+	case lbp.Set.AllocAddr != 0:
+		addrs, err = allocAddrLocations(p)
 	case lbp.Set.File != "":
 		addrs, err = FindFileLocation(p, lbp.Set.File, lbp.Set.Line)
 	case lbp.Set.FunctionName != "":
@@ -366,14 +372,34 @@ func enableBreakpointOnTarget(p *Target, lbp *LogicalBreakpoint) error {
 		return err
 	}
 
+	cond, err := p.breakletCond(lbp)
+	if err != nil {
+		return err
+	}
+
 	for _, addr := range addrs {
-		_, err = p.SetBreakpoint(lbp.LogicalID, addr, UserBreakpoint, nil)
+		var bp *Breakpoint
+		bp, err = p.SetBreakpoint(lbp.LogicalID, addr, UserBreakpoint, cond)
 		if err != nil {
 			if _, isexists := err.(BreakpointExistsError); isexists {
+				if lbp.Set.AllocType != "" || lbp.Set.AllocSize != "" {
+					// Skipping the location would silently miss allocations, the
+					// error is not a BreakpointExistsError so that the locations
+					// already set are rolled back.
+					return fmt.Errorf("could not set allocation breakpoint: %v", err)
+				}
 				continue
 			}
 			return err
 		}
+		switch {
+		case lbp.LogicalID == dataRaceID:
+			bp.UserBreaklet().callback = p.dataRaceReportCallback
+		case lbp.Set.ReturnsError != "":
+			bp.UserBreaklet().callback = returnsErrorCallback(lbp)
+		case lbp.Set.AllocAddr != 0:
+			bp.UserBreaklet().callback = allocAddrCallback(lbp)
+		}
 	}
 
 	return err
@@ -425,9 +451,13 @@ func (grp *TargetGroup) ChangeBreakpointCondition(lbp *LogicalBreakpoint, cond,
 
 	t := ValidTargets{Group: grp}
 	for t.Next() {
+		cond, err := t.breakletCond(lbp)
+		if err != nil {
+			return err
+		}
 		for _, bp := range t.Breakpoints().M {
 			if bp.LogicalID() == lbp.LogicalID {
-				bp.UserBreaklet().Cond = lbp.cond
+				bp.UserBreaklet().Cond = cond
 			}
 		}
 	}
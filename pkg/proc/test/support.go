@@ -91,6 +91,8 @@ const (
 	AllNonOptimized
 	// LinkDisableDWARF enables '-ldflags="-w"'.
 	LinkDisableDWARF
+	// EnableRaceDetector builds the fixture with '-race'.
+	EnableRaceDetector
 )
 
 // TempFile makes a (good enough) random temporary file name
@@ -163,7 +165,7 @@ func BuildFixture(t testing.TB, name string, flags BuildFlags) Fixture {
 		gcflags = "-gcflags=" + strings.Join(gcflagsv, " ")
 	}
 	buildFlags = append(buildFlags, gcflags, "-o", tmpfile)
-	if *EnableRace {
+	if *EnableRace || flags&EnableRaceDetector != 0 {
 		buildFlags = append(buildFlags, "-race")
 	}
 	if flags&BuildModePIE != 0 {
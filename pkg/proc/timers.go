@@ -0,0 +1,256 @@
+package proc
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Timer is a pending timer of the Go runtime, see Timers.
+type Timer struct {
+	// Addr is the address of the runtime.timer struct.
+	Addr uint64
+	// P is the ID of the P whose heap contains the timer.
+	P int
+	// When is the value of the monotonic clock of the runtime (see
+	// TimersInfo.Now) at which the timer fires.
+	When int64
+	// Period is the period of the timer in nanoseconds, for tickers, or 0.
+	Period int64
+	// Func is the function called by the runtime when the timer fires, for
+	// example time.sendTime for the timers of time.Timer and time.Ticker or
+	// time.goFunc for timers created by time.AfterFunc.
+	Func *Function
+	// ArgType is the type of the argument passed to Func.
+	ArgType string
+	// AfterFunc is the function passed to time.AfterFunc, if the timer was
+	// created by it.
+	AfterFunc *Function
+	// Goroutines lists the IDs of the goroutines waiting for the timer:
+	// goroutines blocked in time.Sleep, blocked receiving from the channel
+	// of the timer or waiting for a network deadline.
+	Goroutines []int64
+}
+
+// TimersInfo is the result of Timers.
+type TimersInfo struct {
+	// Now is an estimate of the current value of the monotonic clock used
+	// by the runtime for timers, read from the last time the runtime
+	// observed it, or 0 if it could not be determined. It is always less
+	// than or equal to the real value.
+	Now    int64
+	Timers []Timer
+}
+
+const (
+	timersMaxHeap   = 1 << 20 // maximum number of timers read from the heap of each P
+	timerZombieFlag = 4       // value of runtime.timerZombie
+)
+
+// Timers returns the timers stored in the timer heap of each P, sorted by
+// the time at which they fire.
+//
+// Since Go 1.23 the timers of time.Timer and time.Ticker are only stored
+// in a heap while a goroutine is blocked on their channel, timers whose
+// channel nobody is receiving from are not returned.
+func Timers(t *Target) (*TimersInfo, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	scope := globalScope(t, bi, bi.Images[0], t.Memory())
+
+	timerType, err := bi.findType("runtime.timer")
+	if err != nil {
+		return nil, err
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	gByAddr := make(map[uint64]*G)
+	chanWaiters := make(map[uint64][]int64) // IDs of the goroutines blocked on each channel
+	for _, g := range gs {
+		if g.variable == nil || g.Unreadable != nil {
+			continue
+		}
+		gByAddr[g.variable.Addr] = g
+		for _, c := range goroutineWaitingChans(g) {
+			chanWaiters[c] = append(chanWaiters[c], g.ID)
+		}
+	}
+
+	r := &TimersInfo{}
+
+	// +rtype -var sched schedt
+	// +rtype -field schedt.lastpoll int64|internal/runtime/atomic.Int64
+	if sched, err := scope.findGlobal("runtime", "sched"); err == nil {
+		lastpoll, _ := schedUint(sched, "lastpoll")
+		r.Now = max(r.Now, int64(lastpoll))
+	}
+
+	// +rtype -var allp []*p
+	// +rtype -field p.id int32
+	// +rtype -field p.timers timers
+	// +rtype -field p.sysmontick sysmontick
+	// +rtype -field sysmontick.schedwhen int64
+	// +rtype -field sysmontick.syscallwhen int64
+	// +rtype -field timers.heap []timerWhen
+	// +rtype -field timerWhen.timer *timer
+	allp, err := scope.findGlobal("runtime", "allp")
+	if err != nil {
+		return nil, err
+	}
+	if allp.Kind != reflect.Slice {
+		return nil, errors.New("unexpected type for runtime.allp")
+	}
+	allp.loadValue(LoadConfig{MaxArrayValues: int(allp.Len)})
+	if allp.Unreadable != nil {
+		return nil, allp.Unreadable
+	}
+	for i := range allp.Children {
+		p := allp.Children[i].maybeDereference()
+		if p.Addr == 0 || p.Unreadable != nil {
+			continue
+		}
+		id, _ := schedUint(p, "id")
+		for _, field := range []string{"schedwhen", "syscallwhen"} {
+			when, _ := schedUint(p, "sysmontick", field)
+			r.Now = max(r.Now, int64(when))
+		}
+		heap := structMemberMulti(p, "timers", "heap")
+		if heap == nil || heap.Kind != reflect.Slice {
+			continue
+		}
+		for j := 0; j < int(heap.Len) && j < timersMaxHeap; j++ {
+			tw, err := heap.sliceAccess(j)
+			if err != nil {
+				break
+			}
+			addr, ok := schedUint(tw, "timer")
+			if !ok || addr == 0 {
+				continue
+			}
+			tmr, ok := readTimer(newVariable("", addr, timerType, bi, t.Memory()), gByAddr, chanWaiters)
+			if !ok {
+				continue
+			}
+			tmr.P = int(id)
+			r.Timers = append(r.Timers, tmr)
+		}
+	}
+
+	sort.SliceStable(r.Timers, func(i, j int) bool {
+		return r.Timers[i].When < r.Timers[j].When
+	})
+	return r, nil
+}
+
+// readTimer reads the runtime.timer struct v. Returns false if the timer
+// can not be read or if it has been stopped.
+func readTimer(v *Variable, gByAddr map[uint64]*G, chanWaiters map[uint64][]int64) (Timer, bool) {
+	// +rtype -field timer.state uint8
+	// +rtype -field timer.when int64
+	// +rtype -field timer.period int64
+	// +rtype -field timer.f func(any, uintptr, int64)
+	// +rtype -field timer.arg any
+	state, ok := schedUint(v, "state")
+	if !ok || state&timerZombieFlag != 0 {
+		return Timer{}, false
+	}
+	when, ok := schedUint(v, "when")
+	if !ok {
+		return Timer{}, false
+	}
+	tmr := Timer{Addr: v.Addr, When: int64(when)}
+	period, _ := schedUint(v, "period")
+	tmr.Period = int64(period)
+	if f, err := v.structMember("f"); err == nil {
+		tmr.Func = funcvalFunction(v.bi, v.mem, f.funcvalAddr())
+	}
+
+	arg, err := v.structMember("arg")
+	if err != nil {
+		return tmr, true
+	}
+	arg.loadInterface(0, false, LoadConfig{})
+	if arg.Unreadable != nil || len(arg.Children) == 0 || arg.Children[0].Addr == 0 {
+		return tmr, true
+	}
+	data := &arg.Children[0]
+	tmr.ArgType = data.TypeString()
+	// All the arguments we are interested in are pointer shaped and stored
+	// directly in the data word of the interface.
+	ptr, err := readUintRaw(data.mem, data.Addr, int64(v.bi.Arch.PtrSize()))
+	if err != nil || ptr == 0 {
+		return tmr, true
+	}
+
+	if tmr.Func == nil {
+		return tmr, true
+	}
+	switch tmr.Func.Name {
+	case "runtime.goroutineReady":
+		// time.Sleep, the argument is the sleeping goroutine
+		if g := gByAddr[ptr]; g != nil {
+			tmr.Goroutines = []int64{g.ID}
+		}
+	case "time.goFunc":
+		// time.AfterFunc, the argument is the function to call
+		tmr.AfterFunc = funcvalFunction(v.bi, v.mem, ptr)
+	case "time.sendTime":
+		// time.Timer and time.Ticker, the argument is the channel
+		tmr.Goroutines = chanWaiters[ptr]
+	case "runtime.netpollDeadline", "runtime.netpollReadDeadline", "runtime.netpollWriteDeadline":
+		// network deadlines, the argument is a *runtime.pollDesc
+		tmr.Goroutines = pollDescWaiters(v.bi, v.mem, ptr, gByAddr)
+	}
+	return tmr, true
+}
+
+// funcvalFunction returns the function of the funcval at addr.
+func funcvalFunction(bi *BinaryInfo, mem MemoryReadWriter, addr uint64) *Function {
+	if addr == 0 {
+		return nil
+	}
+	pc, err := readUintRaw(mem, addr, int64(bi.Arch.PtrSize()))
+	if err != nil {
+		return nil
+	}
+	return bi.PCToFunc(pc)
+}
+
+// goroutineWaitingChans returns the addresses of the channels g is blocked
+// on, read from the list of sudogs in g.waiting.
+func goroutineWaitingChans(g *G) []uint64 {
+	n := &waitNode{g: g}
+	waitingChans(n, make(map[uint64]bool))
+	r := make([]uint64, 0, len(n.resources))
+	for _, res := range n.resources {
+		r = append(r, res.Addr)
+	}
+	return r
+}
+
+// pollDescWaiters returns the IDs of the goroutines waiting for the
+// runtime.pollDesc at addr to become readable or writable.
+func pollDescWaiters(bi *BinaryInfo, mem MemoryReadWriter, addr uint64, gByAddr map[uint64]*G) []int64 {
+	// +rtype -field pollDesc.rg uintptr|internal/runtime/atomic.Uintptr
+	// +rtype -field pollDesc.wg uintptr|internal/runtime/atomic.Uintptr
+	typ, err := bi.findType("runtime.pollDesc")
+	if err != nil {
+		return nil
+	}
+	pd := newVariable("", addr, typ, bi, mem)
+	var r []int64
+	for _, field := range []string{"rg", "wg"} {
+		// the field contains either a pointer to a g or one of pdNil,
+		// pdReady and pdWait, which are never valid g addresses.
+		gaddr, _ := schedUint(pd, field)
+		if g := gByAddr[gaddr]; g != nil {
+			r = append(r, g.ID)
+		}
+	}
+	return r
+}
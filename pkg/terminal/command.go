@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"bytes"
 	"cmp"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"go/parser"
@@ -138,7 +139,26 @@ Finally, you can assign a condition to the newly created breakpoint by using the
 
 Alternatively you can set a condition on a breakpoint after created by using the 'on' command.
 
-See also: "help on", "help cond" and "help clear"`},
+Allocation breakpoints stop when an object of a given type, or of a given size, is allocated:
+
+	break [name] -alloc <type> [-alloc-size <size>] [if <condition>]
+	break [name] -alloc-size <size> [if <condition>]
+
+For example:
+
+	break -alloc *mypkg.Request
+	break -alloc-size >1MB
+
+The type can be specified either as T or *T, both match new(T), &T{} and the backing arrays of []T. The size is an optional comparison operator (>=, >, <=, <, == or !=, the default is >=) followed by a number of bytes with an optional KB, MB or GB suffix (powers of 1024).
+Allocation breakpoints are set on runtime.mallocgc, when one is hit the position of the frame that caused the allocation is printed, use 'frame <m>' to inspect it. Only one allocation breakpoint can exist at a time.
+
+To stop when a function returns a non-nil error use:
+
+	break [name] -returns-error <regex> [if <condition>]
+
+The breakpoint is set on the return instructions of all functions matching regex whose last result is an error (inlined calls are not included), the returned error is printed when it stops.
+
+See also: "help on", "help cond", "help clear" and "help trace-error"`},
 		{aliases: []string{"trace", "t"}, group: breakCmds, cmdFn: tracepoint, allowedPrefixes: onPrefix, helpMsg: `Set tracepoint.
 
 	trace [name] [locspec]
@@ -164,6 +184,15 @@ will watch the address of variable 'v' and writes to an int at addr '0x1400007c0
 Note that writes that do not change the value of the watched memory address might not be reported.
 
 See also: "help print".`},
+		{aliases: []string{"trace-error"}, group: breakCmds, cmdFn: c.traceError, helpMsg: `Find where an error value was created.
+
+	trace-error [-fn <regex>] <expr>
+
+Expr must evaluate to a non-nil error. When debugging a recording the execution is rewound to where the object pointed to by the error was allocated.
+
+Otherwise, since the error already exists, breakpoints are created that stop the next time an error with the same dynamic type is created: an allocation breakpoint on its type (if it is a pointer) and, if -fn is specified, a -returns-error breakpoint, restricted to errors of that type, on all functions matching regex. Continue to stop where the error is allocated and where it is first returned. An existing allocation breakpoint on the same type is reused, since only one allocation breakpoint can exist at a time any other one must be cleared first (or, with -fn, only the -returns-error breakpoint is created).
+
+See also: "help break".`},
 		{aliases: []string{"restart", "r"}, group: runCmds, cmdFn: restart, helpMsg: `Restart process.
 
 For recorded targets the command takes the following forms:
@@ -224,6 +253,27 @@ Current limitations:
 - only supported on linux's native backend.
 `},
 		{aliases: []string{"threads"}, group: goroutineCmds, cmdFn: threads, helpMsg: "Print out info for every traced thread."},
+		{aliases: []string{"sched"}, group: goroutineCmds, cmdFn: sched, helpMsg: `Print the state of the Go scheduler.
+
+	sched
+
+Prints GOMAXPROCS, the phase of the garbage collector, the length of the global run queue, the list of Ps (with their status, the M and goroutine running on them and the goroutines in their local run queue) and the list of Ms (with their thread ID, the P and goroutine they are running and the goroutine locked to them, if any).
+
+The state is read from the variables of the Go runtime, a P is reported as "running" while it is owned by an M, even if the process is stopped.`},
+		{aliases: []string{"timers"}, group: goroutineCmds, cmdFn: timers, helpMsg: `Print the pending timers of the Go runtime.
+
+	timers
+
+Lists the timers in the timer heap of each P, sorted by the time at which they fire. For each timer prints when it fires, its period (for tickers), the function called by the runtime when it fires with the type of its argument and, when it can be determined, the goroutines waiting for it (goroutines in time.Sleep, blocked on the channel of a time.Timer or time.Ticker, or waiting for a network deadline) or the function passed to time.AfterFunc.
+
+The current time is estimated from the last time the runtime read its clock, fire times are approximate. Since Go 1.23 the timers of time.Timer and time.Ticker are only in a timer heap while a goroutine is blocked receiving from their channel.`},
+		{aliases: []string{"fds"}, group: goroutineCmds, cmdFn: fds, helpMsg: `Print the file descriptors of the target.
+
+	fds
+
+For each file descriptor prints the file or socket it refers to, the goroutines parked in the netpoller waiting for it to become readable or writable and the variables (os.File, net.Conn, net.Listener...) that hold it.
+
+On linux the file descriptors of live processes are read from /proc/<pid>/fd and sockets from /proc/<pid>/net. For core files, and other targets, only the file descriptors held by variables in goroutine stacks or package variables are listed.`},
 		{aliases: []string{"thread", "tr"}, group: goroutineCmds, cmdFn: thread, helpMsg: `Switch to the specified thread.
 
 	thread <id>`},
@@ -241,6 +291,7 @@ If called with the locspec argument it will delete all the breakpoints matching
 		{aliases: []string{"goroutines", "grs"}, group: goroutineCmds, cmdFn: c.goroutines, helpMsg: `List program goroutines.
 
 	goroutines [-u|-r|-g|-s] [-t [depth]] [-l] [-with loc expr] [-without loc expr] [-group argument] [-chan expr] [-exec command]
+	goroutines -pprof <output file>
 
 Print out info for every goroutine. The flag controls what information is shown along with each goroutine:
 
@@ -323,6 +374,12 @@ EXEC
 	goroutines -exec <command>
 
 Runs the command on every goroutine.
+
+PPROF
+
+	goroutines -pprof <output file>
+
+Writes the stacktraces of all goroutines to the output file as a pprof goroutine profile, which can be read with 'go tool pprof'. Goroutines with the same stacktrace and labels are counted together. The pprof labels of each goroutine are recorded as sample labels, along with a 'goroutine.state' label containing its wait reason (or 'running' and 'runnable' for goroutines that are not waiting).
 `},
 		{aliases: []string{"goroutine", "gr"}, group: goroutineCmds, allowedPrefixes: onPrefix, cmdFn: c.goroutine, helpMsg: `Shows or changes current goroutine
 
@@ -333,6 +390,13 @@ Runs the command on every goroutine.
 Called without arguments it will show information about the current goroutine.
 Called with a single argument it will switch to the specified goroutine.
 Called with more arguments it will execute a command on the specified goroutine.`},
+		{aliases: []string{"deadlocks"}, group: goroutineCmds, cmdFn: deadlocks, helpMsg: `Find goroutines that are blocked forever.
+
+	deadlocks
+
+Builds a wait-for graph of the goroutines blocked on channel operations, select statements, sync.Mutex, sync.RWMutex, sync.WaitGroup and other semaphores, and reports the goroutines that can never be woken up. Cycles of goroutines waiting on each other are reported first, followed by the other blocked goroutines (for example goroutines receiving from a nil channel or from a channel that no other goroutine can access).
+
+A goroutine is considered able to wake up a blocked goroutine if the channel or semaphore it is waiting on can be reached from its stack frames, or from a package variable. Since references held only by heap objects are not searched some of the reported goroutines could still be woken up.`},
 		{aliases: []string{"breakpoints", "bp"}, group: breakCmds, cmdFn: breakpoints, helpMsg: `Print out info for active breakpoints.
 	
 	breakpoints [-a]
@@ -345,6 +409,20 @@ Specifying -a prints all physical breakpoint, including internal breakpoints.`},
 See Documentation/cli/expr.md for a description of supported expressions.
 
 The optional format argument is a format specifier, like the ones used by the fmt package. For example "print %x v" will print v as an hexadecimal number.`},
+		{aliases: []string{"chan"}, group: dataCmds, allowedPrefixes: deferredPrefix, cmdFn: chanCommand, helpMsg: `Inspect a channel.
+
+	[goroutine <n>] [frame <m>] chan <expression>
+
+Prints the state of the channel the expression evaluates to: whether it is closed, the buffered elements in the order in which they will be received and the goroutines waiting to receive from it or send to it. For goroutines waiting to send the value being sent is also printed. For goroutines blocked in a select statement the other cases of the select statement are listed.
+
+The same information is printed by the print command for expressions of channel type.`},
+		{aliases: []string{"ctx"}, group: dataCmds, allowedPrefixes: deferredPrefix, cmdFn: ctxCommand, helpMsg: `Inspect a context.Context.
+
+	[goroutine <n>] [frame <m>] ctx <expression>
+
+Prints the chain of parents of the context the expression evaluates to, starting with the context itself. For each context its kind (cancel, timer, afterFunc, value, withoutCancel, background...) and dynamic type are printed, followed by its deadline, cancellation state and cause, and key/value pair, as applicable.
+
+Contexts not implemented by the context package are followed if they embed their parent context.`},
 		{aliases: []string{"whatis"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
 
 	whatis <expression>`},
@@ -368,6 +446,26 @@ If regex is specified only the functions matching it will be returned.`},
 	types [<regex>]
 
 If regex is specified only the types matching it will be returned.`},
+		{aliases: []string{"methods"}, cmdFn: methods, helpMsg: `Print the method set of a type.
+
+	methods <type>
+
+For interface types the methods of the interface are printed. For other
+types the methods of both T and *T are printed, methods with a pointer
+receiver are not in the method set of T. Methods are read from the
+runtime type metadata and from debug info, methods whose code was removed
+by the linker are listed without a location.`},
+		{aliases: []string{"implements"}, cmdFn: implements, helpMsg: `Print the types implementing an interface.
+
+	implements <interface type>
+
+Prints the types for which the runtime has an itab for the interface,
+which means that a value of the type was converted to the interface at
+some point. Then it prints, as candidates, the named types whose methods,
+found in debug info, include all the methods of the interface. For
+candidates only method names are compared, not their signatures, and
+unexported method names only match methods declared in the package of the
+interface.`},
 		{aliases: []string{"packages"}, cmdFn: packages, helpMsg: `Print list of packages.
 
 	packages [<regex>]
@@ -390,6 +488,33 @@ If regex is specified only local variables with a name matching it will be retur
 	vars [-v] [<regex>]
 
 If regex is specified only package variables with a name matching it will be returned. If -v is specified more information about each package variable will be shown.`},
+		{aliases: []string{"heap"}, cmdFn: heap, group: dataCmds, helpMsg: `Print a histogram of the objects allocated on the heap.
+
+	heap [-count] [<regex>]
+
+Walks the heap and prints, for each type, the number of allocated objects and the number of bytes they occupy, sorted by size. If -count is specified the output is sorted by number of objects instead. If regex is specified only types with a name matching it will be printed.
+
+The type of an object is read from its malloc header when it has one (Go 1.22 and later, for objects larger than 512 bytes containing pointers), otherwise it is inferred from the typed pointers that reference it, starting from package variables and from the local variables of all goroutines. Objects that can not be typed this way are grouped by size as "<unknown N bytes>". Objects backing slices, or containing multiple values of the same type, are reported as []T.
+
+Objects that are unreachable but have not been freed yet by the garbage collector are included in the output.`},
+		{aliases: []string{"refs"}, cmdFn: refsCommand, group: dataCmds, allowedPrefixes: deferredPrefix, helpMsg: `Find what references an object.
+
+	[goroutine <n>] [frame <m>] refs <expression|address>
+
+Searches package variables, the local variables of all goroutines and heap objects for pointers to the object and prints, for each variable or object that directly references it, the shortest chain of references leading to it from a variable. References that can only be reached from the object itself are not reported. Chains that start with a heap object instead of a variable are not reachable and will be freed by the next garbage collection.
+
+If the expression evaluates to a pointer, slice, string, map or channel the object it points to is searched, if it evaluates to an integer constant it is interpreted as an address, otherwise references to the memory storing the value of the expression are searched. If the address is inside a heap object references to any part of the object are searched.
+
+At most 100 chains are printed. See the heap command for how the type of heap objects is determined, objects of unknown type are scanned conservatively.`},
+		{aliases: []string{"sizeof"}, cmdFn: sizeofCommand, group: dataCmds, allowedPrefixes: deferredPrefix, helpMsg: `Print the memory used by a value.
+
+	[goroutine <n>] [frame <m>] sizeof [-deep] [-depth <n>] [-budget <n>] <expression>
+
+Prints the size of the value of the expression. If -deep is specified also walks the heap objects reachable from the value through pointers, slices, strings, maps, channels and interfaces, and prints their total size (the retained size) along with a breakdown by type. Objects reachable through multiple paths are counted only once, objects shared with other variables are counted as well.
+
+The walk follows at most -depth pointers and visits at most -budget objects, 0 means no limit. The defaults (32 and 100000) can be changed with the sizeof-max-depth and sizeof-max-objects configuration options. When one of the limits is reached the retained size is a lower bound. Specifying -depth or -budget implies -deep.
+
+See the heap command for how the type of heap objects is determined.`},
 		{aliases: []string{"regs"}, cmdFn: regs, group: dataCmds, helpMsg: `Print contents of CPU registers.
 
 	regs [-a]
@@ -426,6 +551,18 @@ For example:
 			simple	- disables automatic switch between cgo and go
 			fromg	- starts from the registers stored in the runtime.g struct
 `},
+		{aliases: []string{"panics"}, group: stackCmds, cmdFn: panicsCommand, helpMsg: `Print the panics in progress on a goroutine.
+
+	[goroutine <n>] panics
+
+Prints the panics in the panic list of the goroutine, oldest first. For each panic the value passed to panic and the frame that called panic are printed, followed by its state:
+
+	recovered	- a deferred call called recover, the frame of the deferred call is printed
+	repanicked	- the recovered value was passed to panic again
+	nested		- the panic started while the deferred calls of an earlier panic were running
+	aborted		- the panic will not resume running deferred calls, because a nested panic was recovered by a frame outside of it
+
+Recovered panics are only listed until the deferred call that recovered them returns. The frame numbers can be used with the frame command.`},
 		{aliases: []string{"frame"},
 			group: stackCmds,
 			cmdFn: func(t *Term, ctx callContext, arg string) error {
@@ -594,6 +731,51 @@ For example:
     x -fmt hex -count 20 -size 1 -x &myVar
     x -fmt hex -count 20 -size 1 -x myPtrVar`},
 
+		{aliases: []string{"info"}, group: dataCmds, cmdFn: infoCommand, helpMsg: `Describe what an address points to.
+
+	info addr <address>
+
+Prints the function, offset and source line for addresses in the text of the program, the package variable for addresses in its data, the heap object, its type and the heap span for heap addresses and the goroutine and stack frame for addresses on goroutine stacks, along with the memory mapping containing the address. See the heap command for how the type of heap objects is determined.`},
+
+		{aliases: []string{"maps"}, group: dataCmds, cmdFn: mapsCommand, helpMsg: `Print the memory map of the target process.
+
+	maps [<regex>]
+
+Prints the address range, permissions, offset and mapped file of each memory mapping, along with a classification of its contents: Go heap arena (heap), goroutine stack (stack, with the ID of the goroutine owning it), text or data of the executable (text, data) or shared library. Goroutine stacks are printed as separate mappings, splitting the mapping containing them.
+
+If regex is specified only mappings whose classification or file name match it are printed. For example 'maps stack' prints only goroutine stacks.`},
+
+		{aliases: []string{"setmem"}, group: dataCmds, cmdFn: setmemCommand, helpMsg: `Write raw memory at the given address.
+
+	setmem <address> <value> [-size <size>]
+
+The value is either a quoted string, which can contain escape sequences such as \xff to write arbitrary bytes, or an integer which is written in the byte order of the target, encoded in the number of bytes specified by -size (1, 2, 4 or 8, default 8).
+
+Writing memory is not possible when debugging core files or recordings, or over a breakpoint.
+
+For example:
+
+	setmem 0xc000012340 42
+	setmem 0xc000012340 0xff -size 1
+	setmem 0xc000012340 "\x01\x02\x03"`},
+
+		{aliases: []string{"find"}, group: dataCmds, cmdFn: findCommand, helpMsg: `Search memory for a pattern.
+
+	find [-size <size>] [-max <n>] [<start> <end>|+<length>] <pattern>
+
+The pattern is either a quoted string, which can contain escape sequences such as \xff to search for arbitrary bytes, or an integer which is searched in the byte order of the target, encoded in the number of bytes specified by -size (1, 2, 4 or 8, default 8).
+
+If the start and end addresses, or the start address and a length, are specified only that range is searched, otherwise all the readable memory mappings of the target are searched.
+
+Each match is printed along with the function or package variable containing it, or the heap object and its type, and the memory mapping containing it. At most 100 matches are printed, this can be changed with -max, 0 means no limit.
+
+For example:
+
+	find "hello world"
+	find "\xde\xad\xbe\xef"
+	find -size 4 0xc000010000 +0x1000 1234
+	find 0xc000012340`},
+
 		{aliases: []string{"display"}, group: dataCmds, cmdFn: display, helpMsg: `Print value of an expression every time the program stops.
 
 	display -a [%format] <expression>
@@ -603,11 +785,27 @@ The '-a' option adds an expression to the list of expression printed every time
 
 If display is called without arguments it will print the value of all expression in the list.`},
 
-		{aliases: []string{"dump"}, cmdFn: dump, helpMsg: `Creates a core dump from the current process state
+		{aliases: []string{"dump"}, cmdFn: dump, allowedPrefixes: deferredPrefix, helpMsg: `Creates a core dump from the current process state, or writes memory to a file.
+
+	dump [-exclude-file-backed] [-heap-inuse] [-max-mapping-size <size>] <output file>
+	dump memory <output file> <start> <end>|+<length>
+	[goroutine <n>] [frame <m>] dump value <output file> <expression>
+
+The core dump is always written in ELF, even on systems (windows, macOS) where this is not customary. For environments other than linux/amd64 threads and registers are dumped in a format that only Delve can read back. If the name of the output file ends in '.gz' the core dump is compressed with gzip, 'dlv core' can read compressed core dumps directly.
+
+The options of the core dump reduce its size by leaving out parts of the memory of the target:
 
-	dump <output file>
+	-exclude-file-backed	read-only mappings backed by a file (shared libraries, memory mapped files), their contents will be unavailable when examining the core dump.
+	-heap-inuse		only write the Go heap spans that are in use and the goroutine stacks, free spans and the unused parts of the heap arenas are skipped.
+	-max-mapping-size <size>	write at most <size> bytes of each mapping, the size can be followed by K, M or G.
 
-The core dump is always written in ELF, even on systems (windows, macOS) where this is not customary. For environments other than linux/amd64 threads and registers are dumped in a format that only Delve can read back.`},
+'dump memory' writes the raw bytes between the start and end addresses to the output file. 'dump value' writes the raw bytes of the value of the expression: the backing array of slices, the contents of strings, the value pointed to by pointers, and the memory storing the value for all other types. Both also work on core files. The output file is written on the machine running the client. See also the restore command.`},
+
+		{aliases: []string{"restore"}, cmdFn: restore, helpMsg: `Writes the contents of a file to memory.
+
+	restore <input file> <address>
+
+Writes the contents of the input file, read on the machine running the client, to the memory of the target at the specified address. This can be used to load back the data written by 'dump memory' and 'dump value'. Writing memory is not possible when debugging core files or recordings.`},
 
 		{aliases: []string{"transcript"}, cmdFn: transcript, helpMsg: `Appends command output to a file.
 
@@ -838,6 +1036,146 @@ func threads(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func sched(t *Term, ctx callContext, args string) error {
+	si, err := t.client.SchedulerInfo()
+	if err != nil {
+		return err
+	}
+	gcPhase := si.GCPhase
+	if si.GCWaiting {
+		gcPhase += " (waiting to stop the world)"
+	}
+	fmt.Fprintf(t.stdout, "GOMAXPROCS: %d\n", si.GOMAXPROCS)
+	fmt.Fprintf(t.stdout, "GC phase: %s\n", gcPhase)
+	fmt.Fprintf(t.stdout, "Global run queue: %d goroutines\n", si.GlobalRunQSize)
+	fmt.Fprintf(t.stdout, "Idle Ps: %d, spinning Ms: %d\n", si.IdlePs, si.SpinningMs)
+
+	fmt.Fprintf(t.stdout, "Ps (%d):\n", len(si.Ps))
+	for _, p := range si.Ps {
+		fmt.Fprintf(t.stdout, "\tP %d %s", p.ID, p.Status)
+		if p.M >= 0 {
+			fmt.Fprintf(t.stdout, " M %d", p.M)
+		}
+		if p.CurG != 0 {
+			fmt.Fprintf(t.stdout, " goroutine %d", p.CurG)
+		}
+		fmt.Fprintf(t.stdout, " schedtick=%d syscalltick=%d runq=%d", p.SchedTick, p.SyscallTick, len(p.RunQ))
+		if len(p.RunQ) > 0 {
+			fmt.Fprintf(t.stdout, " [%s]", formatGoroutineIDs(p.RunQ))
+		}
+		fmt.Fprintln(t.stdout)
+	}
+
+	fmt.Fprintf(t.stdout, "Ms (%d):\n", len(si.Ms))
+	for _, m := range si.Ms {
+		fmt.Fprintf(t.stdout, "\tM %d thread %d", m.ID, m.ThreadID)
+		if m.P >= 0 {
+			fmt.Fprintf(t.stdout, " P %d", m.P)
+		}
+		if m.CurG != 0 {
+			fmt.Fprintf(t.stdout, " goroutine %d", m.CurG)
+		}
+		if m.LockedG != 0 {
+			fmt.Fprintf(t.stdout, " locked to goroutine %d", m.LockedG)
+		}
+		if m.Spinning {
+			fmt.Fprint(t.stdout, " spinning")
+		}
+		if m.Blocked {
+			fmt.Fprint(t.stdout, " blocked")
+		}
+		fmt.Fprintln(t.stdout)
+	}
+	return nil
+}
+
+func fds(t *Term, ctx callContext, args string) error {
+	fds, err := t.client.FileDescriptors()
+	if err != nil {
+		return err
+	}
+	for _, fd := range fds {
+		fmt.Fprintf(t.stdout, "fd %d", fd.FD)
+		if fd.Path != "" {
+			fmt.Fprintf(t.stdout, " %s", fd.Path)
+		}
+		if sock := fd.Socket; sock != nil {
+			fmt.Fprintf(t.stdout, " %s %s", sock.Proto, sock.LocalAddr)
+			if sock.RemoteAddr != "" && sock.State != "LISTEN" {
+				fmt.Fprintf(t.stdout, " -> %s", sock.RemoteAddr)
+			}
+			if sock.State != "" {
+				fmt.Fprintf(t.stdout, " %s", sock.State)
+			}
+		}
+		fmt.Fprintln(t.stdout)
+		if fd.Name != "" {
+			fmt.Fprintf(t.stdout, "\tos.File %s\n", fd.Name)
+		}
+		if fd.Net != "" {
+			fmt.Fprintf(t.stdout, "\tnet.netFD %s %s", fd.Net, fd.LocalAddr)
+			if fd.RemoteAddr != "" {
+				fmt.Fprintf(t.stdout, " -> %s", fd.RemoteAddr)
+			}
+			fmt.Fprintln(t.stdout)
+		}
+		if fd.PollDesc != 0 {
+			fmt.Fprintf(t.stdout, "\tpollDesc %#x", fd.PollDesc)
+			if fd.Closing {
+				fmt.Fprint(t.stdout, " closing")
+			}
+			if fd.ReadG != 0 {
+				fmt.Fprintf(t.stdout, " read: goroutine %d", fd.ReadG)
+			}
+			if fd.WriteG != 0 {
+				fmt.Fprintf(t.stdout, " write: goroutine %d", fd.WriteG)
+			}
+			fmt.Fprintln(t.stdout)
+		}
+		for _, ref := range fd.Refs {
+			if ref.GoroutineID == 0 {
+				fmt.Fprintf(t.stdout, "\tpackage variable %s %s\n", ref.Name, ref.Type)
+			} else {
+				fmt.Fprintf(t.stdout, "\tgoroutine %d frame %d %s: %s %s\n", ref.GoroutineID, ref.Frame, ref.Function, ref.Name, ref.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func timers(t *Term, ctx callContext, args string) error {
+	ti, err := t.client.Timers()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Timers (%d):\n", len(ti.Timers))
+	for _, tmr := range ti.Timers {
+		fmt.Fprintf(t.stdout, "\tTimer %#x P %d", tmr.Addr, tmr.P)
+		switch {
+		case ti.Now == 0:
+			fmt.Fprintf(t.stdout, " when=%d", tmr.When)
+		case tmr.When >= ti.Now:
+			fmt.Fprintf(t.stdout, " fires in ~%v", time.Duration(tmr.When-ti.Now).Round(time.Millisecond))
+		default:
+			fmt.Fprintf(t.stdout, " expired ~%v ago", time.Duration(ti.Now-tmr.When).Round(time.Millisecond))
+		}
+		if tmr.Period != 0 {
+			fmt.Fprintf(t.stdout, " period %v", time.Duration(tmr.Period))
+		}
+		if tmr.Func != nil {
+			fmt.Fprintf(t.stdout, " func %s(%s)", tmr.Func.Name(), tmr.ArgType)
+		}
+		if tmr.AfterFunc != nil {
+			fmt.Fprintf(t.stdout, " afterfunc %s at %s:%d", tmr.AfterFunc.Function.Name(), t.formatPath(tmr.AfterFunc.File), tmr.AfterFunc.Line)
+		}
+		if len(tmr.Goroutines) > 0 {
+			fmt.Fprintf(t.stdout, " [%s]", formatGoroutineIDs(tmr.Goroutines))
+		}
+		fmt.Fprintln(t.stdout)
+	}
+	return nil
+}
+
 func thread(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return errors.New("you must specify a thread")
@@ -898,6 +1236,10 @@ func (c *Commands) printGoroutines(t *Term, ctx callContext, indent string, gs [
 }
 
 func (c *Commands) goroutines(t *Term, ctx callContext, argstr string) error {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(argstr), "-pprof"); ok && (rest == "" || rest[0] == ' ') {
+		return goroutinesProfile(t, strings.TrimSpace(rest))
+	}
+
 	filters, group, fgl, flags, depth, batchSize, cmd, err := api.ParseGoroutineArgs(argstr)
 	if err != nil {
 		return err
@@ -956,6 +1298,83 @@ func (c *Commands) goroutines(t *Term, ctx callContext, argstr string) error {
 	return nil
 }
 
+func goroutinesProfile(t *Term, outfile string) error {
+	if outfile == "" {
+		return errors.New("not enough arguments to -pprof")
+	}
+	prof, err := t.client.GoroutineProfile(0)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outfile, prof, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Goroutine profile written to %s\n", outfile)
+	return nil
+}
+
+func deadlocks(t *Term, ctx callContext, args string) error {
+	r, err := t.client.Deadlocks()
+	if err != nil {
+		return err
+	}
+	if len(r.Goroutines) == 0 {
+		fmt.Fprintln(t.stdout, "No deadlocked goroutines found")
+		return nil
+	}
+
+	byID := make(map[int64]*api.DeadlockedGoroutine, len(r.Goroutines))
+	for i := range r.Goroutines {
+		byID[r.Goroutines[i].Goroutine.ID] = &r.Goroutines[i]
+	}
+	printed := make(map[int64]bool)
+
+	printDeadlocked := func(dg *api.DeadlockedGoroutine) {
+		printed[dg.Goroutine.ID] = true
+		fmt.Fprintf(t.stdout, "\tGoroutine %d - User: %s [%s]\n", dg.Goroutine.ID, t.formatLocation(dg.Goroutine.UserCurrentLoc), dg.WaitReason)
+		for _, res := range dg.Resources {
+			fmt.Fprintf(t.stdout, "\t\twaiting on %s %#x\n", res.Kind, res.Addr)
+		}
+		switch {
+		case len(dg.Resources) == 0:
+			fmt.Fprintf(t.stdout, "\t\tno other goroutine can wake it up\n")
+		case len(dg.WaitsFor) == 0:
+			fmt.Fprintf(t.stdout, "\t\tnot referenced by any other goroutine\n")
+		default:
+			fmt.Fprintf(t.stdout, "\t\treferenced by %s\n", formatGoroutineIDs(dg.WaitsFor))
+		}
+	}
+
+	for i, cycle := range r.Cycles {
+		fmt.Fprintf(t.stdout, "Deadlock cycle %d (%s):\n", i+1, formatGoroutineIDs(cycle))
+		for _, id := range cycle {
+			if dg := byID[id]; dg != nil {
+				printDeadlocked(dg)
+			}
+		}
+	}
+	if len(printed) < len(r.Goroutines) {
+		fmt.Fprintln(t.stdout, "Goroutines that can never be woken up:")
+		for i := range r.Goroutines {
+			if !printed[r.Goroutines[i].Goroutine.ID] {
+				printDeadlocked(&r.Goroutines[i])
+			}
+		}
+	}
+	return nil
+}
+
+func formatGoroutineIDs(ids []int64) string {
+	s := make([]string, len(ids))
+	for i := range ids {
+		s[i] = strconv.FormatInt(ids[i], 10)
+	}
+	if len(ids) == 1 {
+		return "goroutine " + s[0]
+	}
+	return "goroutines " + strings.Join(s, ", ")
+}
+
 func selectedGID(state *api.DebuggerState) int64 {
 	if state.SelectedGoroutine == nil {
 		return 0
@@ -1128,13 +1547,7 @@ func (t *Term) formatGoroutine(g *api.Goroutine, fgl api.FormatGoroutineLoc) str
 	}
 
 	if (g.Status == api.GoroutineWaiting || g.Status == api.GoroutineSyscall) && g.WaitReason != 0 {
-		var wr string
-		if g.WaitReason > 0 && g.WaitReason < int64(len(waitReasonStrings)) {
-			wr = waitReasonStrings[g.WaitReason]
-		} else {
-			wr = fmt.Sprintf("unknown wait reason %d", g.WaitReason)
-		}
-		fmt.Fprintf(buf, " [%s", wr)
+		fmt.Fprintf(buf, " [%s", g.WaitReasonString())
 		if g.WaitSince > 0 {
 			fmt.Fprintf(buf, " %d", g.WaitSince)
 		}
@@ -1144,43 +1557,6 @@ func (t *Term) formatGoroutine(g *api.Goroutine, fgl api.FormatGoroutineLoc) str
 	return buf.String()
 }
 
-var waitReasonStrings = [...]string{
-	"",
-	"GC assist marking",
-	"IO wait",
-	"chan receive (nil chan)",
-	"chan send (nil chan)",
-	"dumping heap",
-	"garbage collection",
-	"garbage collection scan",
-	"panicwait",
-	"select",
-	"select (no cases)",
-	"GC assist wait",
-	"GC sweep wait",
-	"GC scavenge wait",
-	"chan receive",
-	"chan send",
-	"finalizer wait",
-	"force gc (idle)",
-	"semacquire",
-	"sleep",
-	"sync.Cond.Wait",
-	"timer goroutine (idle)",
-	"trace reader (blocked)",
-	"wait for GC cycle",
-	"GC worker (idle)",
-	"preempted",
-	"debug call",
-	"GC mark termination",
-	"stopping the world",
-	"flushing proc caches",
-	"trace goroutine status",
-	"trace proc status",
-	"page trace flush",
-	"coroutine",
-}
-
 func writeGoroutineLong(t *Term, w io.Writer, g *api.Goroutine, prefix string) {
 	fmt.Fprintf(w, "%sGoroutine %d:\n%s\tRuntime: %s\n%s\tUser: %s\n%s\tGo: %s\n%s\tStart: %s\n",
 		prefix, g.ID,
@@ -1189,6 +1565,10 @@ func writeGoroutineLong(t *Term, w io.Writer, g *api.Goroutine, prefix string) {
 		prefix, t.formatLocation(g.GoStatementLoc),
 		prefix, t.formatLocation(g.StartLoc))
 	writeGoroutineLabels(w, g, prefix+"\t")
+	if len(g.Panics) > 0 {
+		fmt.Fprintf(w, "%s\tPanics:\n", prefix)
+		printPanics(t, w, g.Panics, prefix+"\t\t")
+	}
 }
 
 func writeGoroutineLabels(w io.Writer, g *api.Goroutine, prefix string) {
@@ -1415,7 +1795,9 @@ func (c *Commands) cont(t *Term, ctx callContext, args string) error {
 		}
 		printcontext(t, state)
 	}
-	printPos(t, state.CurrentThread, printPosShowArrow)
+	if !printAllocFrame(t, state.CurrentThread) {
+		printPos(t, state.CurrentThread, printPosShowArrow)
+	}
 	return nil
 }
 
@@ -1799,6 +2181,17 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) ([]
 		requestedBp = &api.Breakpoint{}
 	)
 
+	if !tracepoint {
+		args := config.Split2PartsBySpace(argstr)
+		switch {
+		case isBreakpointFlag(argstr):
+			return setFlagsBreakpoint(t, requestedBp, argstr)
+		case len(args) == 2 && isBreakpointFlag(args[1]):
+			requestedBp.Name = args[0]
+			return setFlagsBreakpoint(t, requestedBp, args[1])
+		}
+	}
+
 	parseSpec := func(args []string) error {
 		switch len(args) {
 		case 1:
@@ -1944,6 +2337,110 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) ([]
 	return created, nil
 }
 
+// isBreakpointFlag returns true if argstr starts with one of the flags of
+// the break command that specify a breakpoint without a location.
+func isBreakpointFlag(argstr string) bool {
+	return strings.HasPrefix(argstr, "-alloc") || strings.HasPrefix(argstr, "-returns-error")
+}
+
+// setFlagsBreakpoint sets an allocation breakpoint or a -returns-error
+// breakpoint, argstr is the part of the arguments of the break command
+// after the breakpoint name.
+func setFlagsBreakpoint(t *Term, requestedBp *api.Breakpoint, argstr string) ([]*api.Breakpoint, error) {
+	if i := strings.Index(argstr, " if "); i >= 0 {
+		requestedBp.Cond = strings.TrimSpace(argstr[i+len(" if "):])
+		argstr = argstr[:i]
+	}
+	args := strings.Fields(argstr)
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("%s requires an argument", args[i])
+		}
+		switch args[i] {
+		case "-alloc":
+			requestedBp.AllocType = args[i+1]
+		case "-alloc-size":
+			requestedBp.AllocSize = args[i+1]
+		case "-returns-error":
+			requestedBp.ReturnsError = args[i+1]
+		default:
+			return nil, fmt.Errorf("unknown argument %q", args[i])
+		}
+		i++
+	}
+	if requestedBp.ReturnsError != "" && (requestedBp.AllocType != "" || requestedBp.AllocSize != "") {
+		return nil, errors.New("-returns-error can not be used with -alloc or -alloc-size")
+	}
+	bp, err := t.client.CreateBreakpoint(requestedBp)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(t.stdout, "%s set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+	return []*api.Breakpoint{bp}, nil
+}
+
+// formatFlagsBreakpoint describes what an allocation breakpoint or a
+// -returns-error breakpoint stops on, it returns the empty string for
+// other breakpoints.
+func formatFlagsBreakpoint(bp *api.Breakpoint) string {
+	var v []string
+	if bp.AllocType != "" {
+		v = append(v, "alloc "+bp.AllocType)
+	}
+	if bp.AllocSize != "" {
+		v = append(v, "alloc-size "+bp.AllocSize)
+	}
+	if bp.ReturnsError != "" {
+		v = append(v, "returns-error "+bp.ReturnsError)
+	}
+	if bp.ErrorType != "" {
+		v = append(v, "error-type "+bp.ErrorType)
+	}
+	if bp.AllocAddr != 0 {
+		v = append(v, fmt.Sprintf("alloc-addr %#x", bp.AllocAddr))
+	}
+	return strings.Join(v, " ")
+}
+
+// printAllocFrame prints the position of the frame that caused the
+// allocation after stopping on an allocation breakpoint. It returns false
+// if th isn't stopped on an allocation breakpoint.
+// The frame is not selected, commands like next and stepout still operate
+// on the frame where the thread is stopped.
+func printAllocFrame(t *Term, th *api.Thread) bool {
+	if th == nil || th.Breakpoint == nil || (th.Breakpoint.AllocType == "" && th.Breakpoint.AllocSize == "") {
+		return false
+	}
+	return printUserFrame(t, th)
+}
+
+// printUserFrame prints the position of the first frame of th outside of
+// the runtime.
+func printUserFrame(t *Term, th *api.Thread) bool {
+	stack, err := t.client.Stacktrace(th.GoroutineID, 50, 0, nil)
+	if err != nil {
+		return false
+	}
+	for i, frame := range stack {
+		if frame.Function == nil {
+			continue
+		}
+		pkg := frame.Function.Name()
+		if dot := strings.LastIndex(pkg, "/"); dot >= 0 {
+			pkg = pkg[:dot] + strings.SplitN(pkg[dot:], ".", 2)[0]
+		} else {
+			pkg = strings.SplitN(pkg, ".", 2)[0]
+		}
+		if pkg == "runtime" || strings.HasPrefix(pkg, "internal/runtime/") {
+			continue
+		}
+		fmt.Fprintf(t.stdout, "Frame %d: %s:%d (PC: %x)\n", i, t.formatPath(frame.File), frame.Line, frame.PC)
+		printfile(t, frame.File, frame.Line, true)
+		return true
+	}
+	return false
+}
+
 func breakpoint(t *Term, ctx callContext, args string) error {
 	_, err := setBreakpoint(t, ctx, false, args)
 	return err
@@ -2184,44 +2681,339 @@ loop:
 	return nil
 }
 
-func parseFormatArg(args string) (fmtstr, argsOut string) {
-	if len(args) < 1 || args[0] != '%' {
-		return "", args
-	}
-	v := strings.SplitN(args, " ", 2)
-	if len(v) == 1 {
-		return v[0], ""
-	}
-	return v[0], v[1]
-}
-
-const maxPrintVarChanGoroutines = 100
+const maxFindMatches = 100
 
-func (c *Commands) printVar(t *Term, ctx callContext, args string) error {
-	if len(args) == 0 {
-		return errors.New("not enough arguments")
-	}
-	if ctx.Prefix == onPrefix {
-		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
-		return nil
+func findCommand(t *Term, ctx callContext, args string) error {
+	size, maxMatches := 8, maxFindMatches
+	for {
+		flag, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+		switch flag {
+		case "-size", "-max":
+			val, rest, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid argument to %s: %q", flag, val)
+			}
+			if flag == "-size" {
+				if n != 1 && n != 2 && n != 4 && n != 8 {
+					return errors.New("size must be 1, 2, 4 or 8")
+				}
+				size = n
+			} else {
+				maxMatches = n
+			}
+			args = rest
+			continue
+		}
+		break
 	}
-	fmtstr, args := parseFormatArg(args)
-	val, err := t.client.EvalVariable(ctx.Scope, args, t.loadConfig())
+	rangeArgs, pattern, err := parseMemoryPattern(strings.TrimSpace(args), size)
 	if err != nil {
 		return err
 	}
-
-	t.stdout.pw.PageMaybe(nil)
-
-	fmt.Fprintln(t.stdout, val.MultilineString("", fmtstr))
-
-	if val.Kind == reflect.Chan {
-		fmt.Fprintln(t.stdout)
-		gs, _, _, _, err := t.client.ListGoroutinesWithFilter(0, maxPrintVarChanGoroutines, []api.ListGoroutinesFilter{{Kind: api.GoroutineWaitingOnChannel, Arg: fmt.Sprintf("*(*%q)(%#x)", val.Type, val.Addr)}}, nil, &ctx.Scope)
+	var start, end uint64
+	switch len(rangeArgs) {
+	case 0:
+	case 2:
+		start, end, err = parseAddrRange(rangeArgs[0], rangeArgs[1])
 		if err != nil {
-			fmt.Fprintf(t.stdout, "Error reading channel wait queue: %v", err)
-		} else {
-			fmt.Fprintln(t.stdout, "Goroutines waiting on this channel:")
+			return err
+		}
+	default:
+		return errors.New("wrong number of arguments")
+	}
+
+	matches, truncated, err := t.client.FindMemory(start, end, pattern, maxMatches)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Fprintln(t.stdout, "Pattern not found")
+		return nil
+	}
+	for _, m := range matches {
+		fmt.Fprintf(t.stdout, "%#x", m.Addr)
+		if m.Symbol != "" {
+			fmt.Fprintf(t.stdout, " %s", m.Symbol)
+			if m.SymbolOffset != 0 {
+				fmt.Fprintf(t.stdout, "+%#x", m.SymbolOffset)
+			}
+		}
+		if m.HeapObject != 0 {
+			fmt.Fprintf(t.stdout, " (*%s)(%#x)", m.HeapType, m.HeapObject)
+			if m.Addr != m.HeapObject {
+				fmt.Fprintf(t.stdout, "+%#x", m.Addr-m.HeapObject)
+			}
+		}
+		if m.Mapping != nil {
+			fmt.Fprintf(t.stdout, " [%s]", formatMemoryMapEntry(m.Mapping))
+		}
+		fmt.Fprintln(t.stdout)
+	}
+	if truncated {
+		fmt.Fprintf(t.stdout, "(only the first %d matches are shown)\n", maxMatches)
+	}
+	return nil
+}
+
+// parseAddrRange parses a range of addresses specified either as a start
+// and an end address or as a start address and a length prefixed by '+'.
+func parseAddrRange(startArg, endArg string) (uint64, uint64, error) {
+	start, err := strconv.ParseUint(startArg, 0, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start address %q", startArg)
+	}
+	var end uint64
+	if n, ok := strings.CutPrefix(endArg, "+"); ok {
+		end, err = strconv.ParseUint(n, 0, 64)
+		end += start
+	} else {
+		end, err = strconv.ParseUint(endArg, 0, 64)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end address %q", endArg)
+	}
+	if end <= start {
+		return 0, 0, errors.New("invalid range")
+	}
+	return start, end, nil
+}
+
+// parseMemoryPattern splits the arguments of the find and setmem commands
+// into the leading addresses and the pattern. The pattern is either a
+// quoted string or an integer encoded in size bytes.
+func parseMemoryPattern(args string, size int) ([]string, []byte, error) {
+	if args == "" {
+		return nil, nil, errors.New("not enough arguments")
+	}
+	if quote := args[len(args)-1]; quote == '"' || quote == '`' {
+		for i := range args {
+			if args[i] != quote || (i > 0 && args[i-1] != ' ') {
+				continue
+			}
+			if s, err := strconv.Unquote(args[i:]); err == nil {
+				if s == "" {
+					return nil, nil, errors.New("empty pattern")
+				}
+				return strings.Fields(args[:i]), []byte(s), nil
+			}
+		}
+		return nil, nil, fmt.Errorf("invalid string pattern %s", args)
+	}
+	fields := strings.Fields(args)
+	val := fields[len(fields)-1]
+	n, err := strconv.ParseUint(val, 0, 64)
+	if err != nil {
+		m, err := strconv.ParseInt(val, 0, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid pattern %q", val)
+		}
+		n = uint64(m)
+		if size < 8 && m < -(1<<(8*size-1)) {
+			return nil, nil, fmt.Errorf("%s does not fit in %d bytes", val, size)
+		}
+		n &= 1<<(8*size) - 1
+	} else if size < 8 && n >= 1<<(8*size) {
+		return nil, nil, fmt.Errorf("%s does not fit in %d bytes", val, size)
+	}
+	pattern := make([]byte, 8)
+	binary.LittleEndian.PutUint64(pattern, n)
+	return fields[:len(fields)-1], pattern[:size], nil
+}
+
+func setmemCommand(t *Term, ctx callContext, args string) error {
+	size := 8
+	args = strings.TrimSpace(args)
+	// -size can be specified before or after the value
+	parseSize := func(val string) error {
+		n, err := strconv.Atoi(val)
+		if err != nil || (n != 1 && n != 2 && n != 4 && n != 8) {
+			return errors.New("size must be 1, 2, 4 or 8")
+		}
+		size = n
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(args, "-size "); ok {
+		val, rest, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if err := parseSize(val); err != nil {
+			return err
+		}
+		args = strings.TrimSpace(rest)
+	} else if fields := strings.Fields(args); len(fields) > 2 && fields[len(fields)-2] == "-size" {
+		if err := parseSize(fields[len(fields)-1]); err != nil {
+			return err
+		}
+		args = strings.TrimSpace(args[:strings.LastIndex(args, "-size")])
+	}
+	addrArgs, data, err := parseMemoryPattern(args, size)
+	if err != nil {
+		return err
+	}
+	if len(addrArgs) != 1 {
+		return errors.New("wrong number of arguments")
+	}
+	addr, err := strconv.ParseUint(addrArgs[0], 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid address %q", addrArgs[0])
+	}
+	return t.client.WriteMemory(addr, data)
+}
+
+func mapsCommand(t *Term, ctx callContext, args string) error {
+	var re *regexp.Regexp
+	if args = strings.TrimSpace(args); args != "" {
+		var err error
+		re, err = regexp.Compile(args)
+		if err != nil {
+			return fmt.Errorf("invalid filter argument: %s", err.Error())
+		}
+	}
+	mappings, err := t.client.MemoryMap()
+	if err != nil {
+		return err
+	}
+	w := new(tabwriter.Writer)
+	w.Init(t.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Start\tEnd\tPerms\tOffset\tKind\tFile")
+	for _, m := range mappings {
+		kind := m.Kind
+		if m.GoroutineID != 0 {
+			kind = fmt.Sprintf("%s goroutine %d", kind, m.GoroutineID)
+		}
+		if re != nil && !re.MatchString(kind) && !re.MatchString(m.Filename) {
+			continue
+		}
+		fmt.Fprintf(w, "%#x\t%#x\t%s\t%#x\t%s\t%s\n", m.Addr, m.Addr+m.Size, formatMemoryMapPerms(&m.MemoryMapEntry), m.Offset, kind, m.Filename)
+	}
+	return w.Flush()
+}
+
+func infoCommand(t *Term, ctx callContext, args string) error {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch sub {
+	case "addr":
+		return infoAddr(t, strings.TrimSpace(rest))
+	case "":
+		return errors.New("not enough arguments")
+	default:
+		return fmt.Errorf("unknown info subcommand %q", sub)
+	}
+}
+
+func infoAddr(t *Term, arg string) error {
+	if arg == "" {
+		return errors.New("not enough arguments")
+	}
+	addr, err := strconv.ParseUint(arg, 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid address %q", arg)
+	}
+	info, err := t.client.DescribeAddress(addr)
+	if err != nil {
+		return err
+	}
+	withOffset := func(name string, off uint64) string {
+		if off == 0 {
+			return name
+		}
+		return fmt.Sprintf("%s+%#x", name, off)
+	}
+	switch {
+	case info.Function != nil:
+		fmt.Fprintf(t.stdout, "%#x is in function %s at %s:%d\n", addr, withOffset(info.Function.Name(), info.FunctionOffset), t.formatPath(info.File), info.Line)
+	case info.Variable != "":
+		fmt.Fprintf(t.stdout, "%#x is in package variable %s\n", addr, withOffset(info.Variable, info.VariableOffset))
+	case info.HeapObject != 0:
+		fmt.Fprintf(t.stdout, "%#x is in heap object %s (%d bytes)\n", addr, withOffset(fmt.Sprintf("(*%s)(%#x)", info.HeapType, info.HeapObject), addr-info.HeapObject), info.HeapObjectSize)
+	case info.HeapSpanBase != 0:
+		fmt.Fprintf(t.stdout, "%#x is in a free slot of a heap span\n", addr)
+	case info.Goroutine != nil && info.Frame >= 0:
+		fmt.Fprintf(t.stdout, "%#x is on the stack of goroutine %d, in frame %d", addr, info.Goroutine.ID, info.Frame)
+		if info.FrameFunction != nil {
+			fmt.Fprintf(t.stdout, " (%s)", info.FrameFunction.Name())
+		}
+		fmt.Fprintln(t.stdout)
+	case info.Goroutine != nil:
+		fmt.Fprintf(t.stdout, "%#x is on the stack of goroutine %d, outside of the active frames\n", addr, info.Goroutine.ID)
+	default:
+		fmt.Fprintf(t.stdout, "%#x is not in a known function, variable, heap object or goroutine stack\n", addr)
+	}
+	if info.HeapSpanBase != 0 {
+		fmt.Fprintf(t.stdout, "Heap span: %#x-%#x, element size %d\n", info.HeapSpanBase, info.HeapSpanLimit, info.HeapElemSize)
+	}
+	if info.Image != "" {
+		fmt.Fprintf(t.stdout, "Image: %s\n", info.Image)
+	}
+	if info.Mapping != nil {
+		fmt.Fprintf(t.stdout, "Mapping: %s\n", formatMemoryMapEntry(info.Mapping))
+	}
+	return nil
+}
+
+// formatMemoryMapPerms returns the permissions of a memory mapping in the
+// format used by /proc/pid/maps.
+func formatMemoryMapPerms(mme *api.MemoryMapEntry) string {
+	perms := []byte("---")
+	if mme.Read {
+		perms[0] = 'r'
+	}
+	if mme.Write {
+		perms[1] = 'w'
+	}
+	if mme.Exec {
+		perms[2] = 'x'
+	}
+	return string(perms)
+}
+
+// formatMemoryMapEntry returns a description of a memory mapping, with its
+// address range, its permissions and the mapped file.
+func formatMemoryMapEntry(mme *api.MemoryMapEntry) string {
+	s := fmt.Sprintf("%#x-%#x %s", mme.Addr, mme.Addr+mme.Size, formatMemoryMapPerms(mme))
+	if mme.Filename != "" {
+		s += " " + mme.Filename
+	}
+	return s
+}
+
+func parseFormatArg(args string) (fmtstr, argsOut string) {
+	if len(args) < 1 || args[0] != '%' {
+		return "", args
+	}
+	v := strings.SplitN(args, " ", 2)
+	if len(v) == 1 {
+		return v[0], ""
+	}
+	return v[0], v[1]
+}
+
+const maxPrintVarChanGoroutines = 100
+
+func (c *Commands) printVar(t *Term, ctx callContext, args string) error {
+	if len(args) == 0 {
+		return errors.New("not enough arguments")
+	}
+	if ctx.Prefix == onPrefix {
+		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
+		return nil
+	}
+	fmtstr, args := parseFormatArg(args)
+	val, err := t.client.EvalVariable(ctx.Scope, args, t.loadConfig())
+	if err != nil {
+		return err
+	}
+
+	t.stdout.pw.PageMaybe(nil)
+
+	fmt.Fprintln(t.stdout, val.MultilineString("", fmtstr))
+
+	if val.Kind == reflect.Chan {
+		fmt.Fprintln(t.stdout)
+		gs, _, _, _, err := t.client.ListGoroutinesWithFilter(0, maxPrintVarChanGoroutines, []api.ListGoroutinesFilter{{Kind: api.GoroutineWaitingOnChannel, Arg: fmt.Sprintf("*(*%q)(%#x)", val.Type, val.Addr)}}, nil, &ctx.Scope)
+		if err != nil {
+			fmt.Fprintf(t.stdout, "Error reading channel wait queue: %v", err)
+		} else {
+			fmt.Fprintln(t.stdout, "Goroutines waiting on this channel:")
 			state, err := t.client.GetState()
 			if err != nil {
 				fmt.Fprintf(t.stdout, "Error printing channel wait queue: %v", err)
@@ -2229,10 +3021,130 @@ func (c *Commands) printVar(t *Term, ctx callContext, args string) error {
 			var done bool
 			c.printGoroutines(t, ctx, "", gs, api.FglUserCurrent, 0, 0, "", &done, state)
 		}
+		if val.Base != 0 {
+			fmt.Fprintln(t.stdout)
+			cs, err := t.client.ChanState(ctx.Scope, fmt.Sprintf("*(*%q)(%#x)", val.Type, val.Addr), t.loadConfig())
+			if err != nil {
+				fmt.Fprintf(t.stdout, "Error reading channel state: %v\n", err)
+			} else {
+				printChanState(t, cs, fmtstr)
+			}
+		}
 	}
 	return nil
 }
 
+func chanCommand(t *Term, ctx callContext, args string) error {
+	if len(args) == 0 {
+		return errors.New("not enough arguments")
+	}
+	cs, err := t.client.ChanState(ctx.Scope, args, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	if cs.Addr == 0 {
+		fmt.Fprintln(t.stdout, "nil channel")
+		return nil
+	}
+	fmt.Fprintf(t.stdout, "channel at %#x\n", cs.Addr)
+	printChanState(t, cs, "")
+	return nil
+}
+
+func ctxCommand(t *Term, ctx callContext, args string) error {
+	if len(args) == 0 {
+		return errors.New("not enough arguments")
+	}
+	layers, err := t.client.ContextChain(ctx.Scope, args, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	if len(layers) == 0 {
+		fmt.Fprintln(t.stdout, "nil context")
+		return nil
+	}
+	for i, layer := range layers {
+		kind := layer.Kind
+		if kind == "" {
+			kind = "custom"
+		}
+		fmt.Fprintf(t.stdout, "[%d] %s %s", i, kind, layer.Type)
+		if layer.Addr != 0 {
+			fmt.Fprintf(t.stdout, " at %#x", layer.Addr)
+		}
+		fmt.Fprintln(t.stdout)
+		if layer.Deadline != nil {
+			deadline := layer.Deadline.Value
+			if deadline == "" {
+				deadline = layer.Deadline.SinglelineString()
+			}
+			fmt.Fprintf(t.stdout, "\tdeadline: %s\n", deadline)
+		}
+		if layer.AfterFunc != nil {
+			fmt.Fprintf(t.stdout, "\tafterFunc: %s\n", layer.AfterFunc.SinglelineString())
+		}
+		if layer.Err != nil {
+			if layer.Canceled {
+				fmt.Fprintf(t.stdout, "\tcanceled: %s\n", layer.Err.SinglelineString())
+				if layer.Cause != nil {
+					fmt.Fprintf(t.stdout, "\tcause: %s\n", layer.Cause.SinglelineString())
+				}
+			} else {
+				fmt.Fprintln(t.stdout, "\tnot canceled")
+			}
+		}
+		if layer.Key != nil {
+			fmt.Fprintf(t.stdout, "\tkey: %s\n", layer.Key.MultilineString("\t", ""))
+		}
+		if layer.Value != nil {
+			fmt.Fprintf(t.stdout, "\tvalue: %s\n", layer.Value.MultilineString("\t", ""))
+		}
+	}
+	return nil
+}
+
+func printChanState(t *Term, cs *api.ChanState, fmtstr string) {
+	fmt.Fprintf(t.stdout, "Closed: %v\n", cs.Closed)
+	fmt.Fprintf(t.stdout, "Buffer: %d/%d\n", cs.Len, cs.Cap)
+	if cs.Queue != nil {
+		for i := range cs.Queue.Children {
+			fmt.Fprintf(t.stdout, "\t[%d] %s\n", i, cs.Queue.Children[i].MultilineString("\t", fmtstr))
+		}
+		if int64(len(cs.Queue.Children)) < cs.Len {
+			fmt.Fprintf(t.stdout, "\t...+%d more\n", cs.Len-int64(len(cs.Queue.Children)))
+		}
+	}
+
+	printWaiters := func(name string, ws []api.ChanWaiter) {
+		fmt.Fprintf(t.stdout, "%s: %d\n", name, len(ws))
+		for _, w := range ws {
+			fmt.Fprintf(t.stdout, "\tGoroutine %s\n", t.formatGoroutine(w.Goroutine, api.FglUserCurrent))
+			if w.Value != nil {
+				fmt.Fprintf(t.stdout, "\t\tsending: %s\n", w.Value.MultilineString("\t\t", fmtstr))
+			}
+			if w.Select {
+				fmt.Fprintf(t.stdout, "\t\tin select statement")
+				if len(w.SelectCases) > 0 {
+					fmt.Fprintf(t.stdout, ", also waiting to")
+					for i, c := range w.SelectCases {
+						if i > 0 {
+							fmt.Fprintf(t.stdout, ",")
+						}
+						if c.Send {
+							fmt.Fprintf(t.stdout, " send to %#x", c.Chan)
+						} else {
+							fmt.Fprintf(t.stdout, " receive from %#x", c.Chan)
+						}
+					}
+				}
+				fmt.Fprintln(t.stdout)
+			}
+		}
+	}
+	printWaiters("Receivers waiting", cs.Recvq)
+	printWaiters("Senders waiting", cs.Sendq)
+}
+
 func whatisCommand(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return errors.New("not enough arguments")
@@ -2344,6 +3256,64 @@ func types(t *Term, ctx callContext, args string) error {
 	return t.printSortedStrings(t.client.ListTypes(args))
 }
 
+func methods(t *Term, ctx callContext, args string) error {
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	ms, err := t.client.MethodSet(args)
+	if err != nil {
+		return err
+	}
+	kind := ""
+	if ms.Interface {
+		kind = "interface "
+	}
+	fmt.Fprintf(t.stdout, "Methods of %s%s (%d):\n", kind, ms.Type, len(ms.Methods))
+	for _, m := range ms.Methods {
+		fmt.Fprintf(t.stdout, "\t%s", m.Name)
+		if m.PtrRecv {
+			fmt.Fprint(t.stdout, " (pointer receiver)")
+		}
+		switch {
+		case m.Location != nil:
+			fmt.Fprintf(t.stdout, " %s at %s:%d", m.Location.Function.Name(), t.formatPath(m.Location.File), m.Location.Line)
+		case !ms.Interface:
+			fmt.Fprint(t.stdout, " (removed by the linker)")
+		}
+		fmt.Fprintln(t.stdout)
+	}
+	return nil
+}
+
+func implements(t *Term, ctx callContext, args string) error {
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	impls, err := t.client.Implements(args)
+	if err != nil {
+		return err
+	}
+	var itab, candidates []string
+	for _, impl := range impls {
+		if impl.Itab {
+			itab = append(itab, impl.Type)
+		} else {
+			candidates = append(candidates, impl.Type)
+		}
+	}
+	fmt.Fprintf(t.stdout, "Types implementing %s (%d):\n", args, len(itab))
+	for _, typ := range itab {
+		fmt.Fprintf(t.stdout, "\t%s\n", typ)
+	}
+	if len(candidates) > 0 {
+		fmt.Fprintf(t.stdout, "Candidates, method signatures not checked (%d):\n", len(candidates))
+		for _, typ := range candidates {
+			fmt.Fprintf(t.stdout, "\t%s\n", typ)
+		}
+	}
+	return nil
+}
+
 func parseVarArguments(args string, t *Term) (filter string, cfg api.LoadConfig) {
 	if v := config.Split2PartsBySpace(args); len(v) >= 1 && v[0] == "-v" {
 		if len(v) == 2 {
@@ -2396,6 +3366,145 @@ func vars(t *Term, ctx callContext, args string) error {
 	return t.printFilteredVariables("vars", vars, filter, cfg)
 }
 
+func heap(t *Term, ctx callContext, args string) error {
+	byCount := false
+	if rest, ok := strings.CutPrefix(args, "-count"); ok && (rest == "" || rest[0] == ' ') {
+		byCount = true
+		args = strings.TrimSpace(rest)
+	}
+	stats, err := t.client.HeapHistogram(args)
+	if err != nil {
+		return err
+	}
+	if byCount {
+		sort.SliceStable(stats, func(i, j int) bool { return stats[i].Count > stats[j].Count })
+	}
+	count, bytes := printHeapTypeStats(t, stats)
+	fmt.Fprintf(t.stdout, "Total: %d objects, %d bytes\n", count, bytes)
+	return nil
+}
+
+// printHeapTypeStats prints stats as a table and returns the total number
+// of objects and bytes.
+func printHeapTypeStats(t *Term, stats []api.HeapTypeStats) (count, bytes int64) {
+	w := new(tabwriter.Writer)
+	w.Init(t.stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Count\tBytes\t\tType")
+	for _, st := range stats {
+		fmt.Fprintf(w, "%d\t%d\t\t%s\n", st.Count, st.Bytes, st.Type)
+		count += st.Count
+		bytes += st.Bytes
+	}
+	w.Flush()
+	return count, bytes
+}
+
+const (
+	defaultSizeofMaxDepth   = 32
+	defaultSizeofMaxObjects = 100000
+)
+
+func sizeofCommand(t *Term, ctx callContext, args string) error {
+	deep := false
+	maxDepth, maxObjects := defaultSizeofMaxDepth, defaultSizeofMaxObjects
+	if t.conf != nil && t.conf.SizeofMaxDepth != nil {
+		maxDepth = *t.conf.SizeofMaxDepth
+	}
+	if t.conf != nil && t.conf.SizeofMaxObjects != nil {
+		maxObjects = *t.conf.SizeofMaxObjects
+	}
+	for {
+		flag, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+		switch flag {
+		case "-deep":
+			deep = true
+			args = rest
+			continue
+		case "-depth", "-budget":
+			val, rest, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid argument to %s: %q", flag, val)
+			}
+			if flag == "-depth" {
+				maxDepth = n
+			} else {
+				maxObjects = n
+			}
+			deep = true
+			args = rest
+			continue
+		}
+		break
+	}
+	if strings.TrimSpace(args) == "" {
+		return errors.New("not enough arguments")
+	}
+	if !deep {
+		maxDepth = -1
+	}
+	r, err := t.client.RetainedSize(ctx.Scope, args, maxDepth, maxObjects)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Shallow size: %d bytes\n", r.Shallow)
+	if !deep {
+		return nil
+	}
+	fmt.Fprintf(t.stdout, "Retained size: %d bytes in %d heap objects\n", r.Retained, r.Objects)
+	if len(r.Types) > 0 {
+		printHeapTypeStats(t, r.Types)
+	}
+	if r.Truncated {
+		fmt.Fprintf(t.stdout, "Walk stopped at depth %d or after %d objects, retained size is a lower bound\n", maxDepth, maxObjects)
+	}
+	return nil
+}
+
+const maxRefsChains = 100
+
+func refsCommand(t *Term, ctx callContext, args string) error {
+	if len(args) == 0 {
+		return errors.New("not enough arguments")
+	}
+	refs, err := t.client.HeapReferences(ctx.Scope, args, maxRefsChains)
+	if err != nil {
+		return err
+	}
+	if refs.Type != "" {
+		fmt.Fprintf(t.stdout, "Heap object %#x %s (%d bytes)", refs.Addr, refs.Type, refs.Size)
+	} else {
+		fmt.Fprintf(t.stdout, "Memory at %#x (%d bytes)", refs.Addr, refs.Size)
+	}
+	if len(refs.Chains) == 0 {
+		fmt.Fprintln(t.stdout, " is not referenced by any variable or object")
+		return nil
+	}
+	fmt.Fprintln(t.stdout, " is referenced by:")
+	for _, chain := range refs.Chains {
+		nodes := make([]string, len(chain))
+		for i, n := range chain {
+			switch {
+			case n.Name != "" && n.GoroutineID != 0:
+				nodes[i] = fmt.Sprintf("goroutine %d frame %d %s%s", n.GoroutineID, n.Frame, n.Name, n.Path)
+			case n.Name != "":
+				nodes[i] = n.Name + n.Path
+			default:
+				nodes[i] = fmt.Sprintf("(*%s)(%#x)%s", n.Type, n.Addr, n.Path)
+			}
+		}
+		prefix := ""
+		if chain[0].Name == "" {
+			prefix = "unreachable: "
+		}
+		fmt.Fprintf(t.stdout, "\t%s%s\n", prefix, strings.Join(nodes, " -> "))
+	}
+	if refs.Truncated {
+		fmt.Fprintf(t.stdout, "(only the first %d references are shown)\n", maxRefsChains)
+	}
+	return nil
+}
+
 func regs(t *Term, ctx callContext, args string) error {
 	includeFp := false
 	if args == "-a" {
@@ -2415,6 +3524,154 @@ func regs(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func (c *Commands) traceError(t *Term, ctx callContext, args string) error {
+	fnre := ""
+	if rest, ok := strings.CutPrefix(args, "-fn "); ok {
+		v := config.Split2PartsBySpace(strings.TrimSpace(rest))
+		if len(v) != 2 {
+			return errors.New("not enough arguments")
+		}
+		fnre, args = v[0], v[1]
+	}
+	expr := strings.TrimSpace(args)
+	if expr == "" {
+		return errors.New("not enough arguments")
+	}
+	v, err := t.client.EvalVariable(ctx.Scope, expr, api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1})
+	if err != nil {
+		return err
+	}
+	if v.Kind != reflect.Interface {
+		return fmt.Errorf("%s is not an error", expr)
+	}
+	if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid {
+		return fmt.Errorf("%s is nil", expr)
+	}
+	val := v.Children[0]
+	isptr := val.Kind == reflect.Ptr && len(val.Children) > 0 && val.Children[0].Addr != 0
+
+	if t.client.Recorded() {
+		if !isptr {
+			return fmt.Errorf("can not find where %s was created, its dynamic type %s is not a pointer", expr, val.Type)
+		}
+		// Rewind to the call of runtime.mallocgc that returned the object.
+		bp, err := t.client.CreateBreakpoint(&api.Breakpoint{AllocAddr: val.Children[0].Addr})
+		if err != nil {
+			return err
+		}
+		defer t.client.ClearBreakpoint(bp.ID)
+		c.frame = 0
+		var state *api.DebuggerState
+		for state = range t.client.Rewind() {
+			if state.Err != nil {
+				return state.Err
+			}
+		}
+		th := state.CurrentThread
+		if th == nil || th.Breakpoint == nil || th.Breakpoint.ID != bp.ID {
+			printcontext(t, state)
+			printPos(t, th, printPosShowArrow)
+			return fmt.Errorf("could not find where %s was allocated", expr)
+		}
+		fmt.Fprintf(t.stdout, "%s (%s) allocated by goroutine %d\n", expr, val.Type, th.GoroutineID)
+		if !printUserFrame(t, th) {
+			printPos(t, th, printPosShowArrow)
+		}
+		return nil
+	}
+
+	if isptr {
+		err := traceErrorAlloc(t, val.Type)
+		switch {
+		case err == nil:
+		case fnre == "":
+			return err
+		default:
+			fmt.Fprintf(t.stdout, "Could not set allocation breakpoint: %v\n", err)
+		}
+	}
+	if fnre == "" {
+		if !isptr {
+			return fmt.Errorf("the dynamic type of %s, %s, is not a pointer, use -fn to stop where it is returned", expr, val.Type)
+		}
+		return nil
+	}
+	bp, err := t.client.CreateBreakpoint(&api.Breakpoint{ReturnsError: fnre, ErrorType: val.Type})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "%s set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+	return nil
+}
+
+// traceErrorAlloc sets the allocation breakpoint of trace-error on typ.
+// Since only one allocation breakpoint can exist at a time an existing
+// enabled one for the same type is reused, any other one is an error.
+func traceErrorAlloc(t *Term, typ string) error {
+	bps, err := t.client.ListBreakpoints(false)
+	if err != nil {
+		return err
+	}
+	for _, bp := range bps {
+		if bp.AllocType == "" && bp.AllocSize == "" {
+			continue
+		}
+		if bp.AllocType != typ || bp.AllocSize != "" || bp.Disabled {
+			return fmt.Errorf("%s already exists, only one allocation breakpoint can be set at a time", formatBreakpointName(bp, false))
+		}
+		fmt.Fprintf(t.stdout, "%s already set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+		return nil
+	}
+	bp, err := t.client.CreateBreakpoint(&api.Breakpoint{AllocType: typ})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "%s set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+	return nil
+}
+
+func panicsCommand(t *Term, ctx callContext, args string) error {
+	panics, err := t.client.Panics(ctx.Scope.GoroutineID)
+	if err != nil {
+		return err
+	}
+	if len(panics) == 0 {
+		fmt.Fprintln(t.stdout, "No panics in progress")
+		return nil
+	}
+	printPanics(t, t.stdout, panics, "")
+	return nil
+}
+
+func printPanics(t *Term, w io.Writer, panics []api.Panic, prefix string) {
+	for i, p := range panics {
+		if p.Goexit {
+			fmt.Fprintf(w, "%s[%d] runtime.Goexit", prefix, i)
+		} else {
+			fmt.Fprintf(w, "%s[%d] panic: %s", prefix, i, p.Value.SinglelineString())
+		}
+		var state []string
+		for _, s := range []struct {
+			set  bool
+			name string
+		}{{p.Recovered, "recovered"}, {p.Repanicked, "repanicked"}, {p.Nested, "nested"}, {p.Aborted, "aborted"}} {
+			if s.set {
+				state = append(state, s.name)
+			}
+		}
+		if len(state) > 0 {
+			fmt.Fprintf(w, " [%s]", strings.Join(state, ", "))
+		}
+		fmt.Fprintln(w)
+		if p.PanicLoc != nil {
+			fmt.Fprintf(w, "%s\tpanicked at frame %d: %s\n", prefix, p.PanicFrame, t.formatLocation(*p.PanicLoc))
+		}
+		if p.RecoverLoc != nil {
+			fmt.Fprintf(w, "%s\trecovered by frame %d: %s\n", prefix, p.RecoverFrame, t.formatLocation(*p.RecoverLoc))
+		}
+	}
+}
+
 func stackCommand(t *Term, ctx callContext, args string) error {
 	sa, err := parseStackArgs(args)
 	if err != nil {
@@ -2909,6 +4166,11 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 		writeGoroutineLong(t, t.stdout, bpi.Goroutine, "\t")
 	}
 
+	if bpi.DataRace != nil {
+		tracepointnl()
+		printDataRace(t, bpi.DataRace)
+	}
+
 	for _, v := range bpi.Variables {
 		tracepointnl()
 		fmt.Fprintf(t.stdout, "\t%s: %s\n", v.Name, v.MultilineString("\t", ""))
@@ -2939,6 +4201,32 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 	}
 }
 
+// printDataRace prints the accesses of a data race, like the race
+// detector does.
+func printDataRace(t *Term, r *api.DataRace) {
+	for i, acc := range r.Accesses {
+		kind := "read"
+		if acc.Write {
+			kind = "write"
+		}
+		if acc.Atomic {
+			kind = "atomic " + kind
+		}
+		if i > 0 {
+			kind = "previous " + kind
+		}
+		kind = strings.ToUpper(kind[:1]) + kind[1:]
+		g := "unknown goroutine"
+		if acc.GoroutineID != 0 {
+			g = fmt.Sprintf("goroutine %d", acc.GoroutineID)
+		}
+		fmt.Fprintf(t.stdout, "\t%s at %#x by %s (race detector goroutine %d):\n", kind, acc.Addr, g, acc.RaceGoroutine)
+		for _, loc := range acc.Stack {
+			fmt.Fprintf(t.stdout, "\t\t%s() %s:%d\n", loc.Function.Name(), t.formatPath(loc.File), loc.Line)
+		}
+	}
+}
+
 func printTracepoint(t *Term, th *api.Thread, bpname string, fn *api.Function, args string, hasReturnValue bool) {
 	if t.conf.TraceShowTimestamp {
 		fmt.Fprintf(t.stdout, "%s ", time.Now().Format(time.RFC3339Nano))
@@ -3289,7 +4577,9 @@ func (c *Commands) rewind(t *Term, ctx callContext, args string) error {
 		}
 		printcontext(t, state)
 	}
-	printPos(t, state.CurrentThread, printPosShowArrow)
+	if !printAllocFrame(t, state.CurrentThread) {
+		printPos(t, state.CurrentThread, printPosShowArrow)
+	}
 	return nil
 }
 
@@ -3380,7 +4670,38 @@ func dump(t *Term, ctx callContext, args string) error {
 	if args == "" {
 		return errors.New("not enough arguments")
 	}
-	dumpState, err := t.client.CoreDumpStart(args)
+	if kind, rest, _ := strings.Cut(args, " "); (kind == "memory" || kind == "value") && strings.TrimSpace(rest) != "" {
+		return dumpMemory(t, ctx, kind, strings.TrimSpace(rest))
+	}
+	var opts api.DumpOptions
+	for {
+		flag, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+		if !strings.HasPrefix(flag, "-") {
+			break
+		}
+		switch flag {
+		case "-exclude-file-backed":
+			opts.ExcludeFileBacked = true
+		case "-heap-inuse":
+			opts.HeapInUse = true
+		case "-max-mapping-size":
+			var val string
+			val, rest, _ = strings.Cut(strings.TrimSpace(rest), " ")
+			n, err := parseByteSize(val)
+			if err != nil || n == 0 {
+				return fmt.Errorf("invalid argument to %s: %q", flag, val)
+			}
+			opts.MaxMappingSize = n
+		default:
+			return fmt.Errorf("unknown option %s", flag)
+		}
+		args = rest
+	}
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return errors.New("not enough arguments")
+	}
+	dumpState, err := t.client.CoreDumpStartWithOptions(args, opts)
 	if err != nil {
 		return err
 	}
@@ -3406,6 +4727,100 @@ func dump(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+// parseByteSize parses a number of bytes, optionally followed by one of
+// the suffixes K, M or G.
+func parseByteSize(s string) (uint64, error) {
+	mult := uint64(1)
+	switch strings.ToUpper(s[max(len(s)-1, 0):]) {
+	case "K":
+		mult = 1 << 10
+	case "M":
+		mult = 1 << 20
+	case "G":
+		mult = 1 << 30
+	}
+	if mult != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxUint64/mult {
+		return 0, fmt.Errorf("size out of range: %s", s)
+	}
+	return n * mult, nil
+}
+
+// dumpMemory implements the 'dump memory' and 'dump value' commands.
+func dumpMemory(t *Term, ctx callContext, kind, args string) error {
+	path, rest, _ := strings.Cut(args, " ")
+	rest = strings.TrimSpace(rest)
+	var addr, size uint64
+	if kind == "value" {
+		if rest == "" {
+			return errors.New("not enough arguments")
+		}
+		var err error
+		addr, size, err = t.client.ValueMemory(ctx.Scope, rest)
+		if err != nil {
+			return err
+		}
+	} else {
+		fields := strings.Fields(rest)
+		if len(fields) != 2 {
+			return errors.New("wrong number of arguments")
+		}
+		start, end, err := parseAddrRange(fields[0], fields[1])
+		if err != nil {
+			return err
+		}
+		addr, size = start, end-start
+	}
+
+	fh, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	for done := uint64(0); done < size; {
+		reqsz := min(uint64(rpc2.ExamineMemoryLengthLimit), size-done)
+		mem, _, err := t.client.ExamineMemory(addr+done, int(reqsz))
+		if err != nil {
+			return err
+		}
+		if _, err := fh.Write(mem); err != nil {
+			return err
+		}
+		done += reqsz
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Wrote %d bytes at %#x to %s\n", size, addr, path)
+	return nil
+}
+
+func restore(t *Term, ctx callContext, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return errors.New("wrong number of arguments")
+	}
+	addr, err := strconv.ParseUint(fields[1], 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid address %q", fields[1])
+	}
+	data, err := os.ReadFile(fields[0])
+	if err != nil {
+		return err
+	}
+	if err := t.client.WriteMemory(addr, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Wrote %d bytes from %s at %#x\n", len(data), fields[0], addr)
+	return nil
+}
+
 func transcript(t *Term, ctx callContext, args string) error {
 	argv := strings.SplitN(args, " ", -1)
 	truncate := false
@@ -3547,11 +4962,18 @@ func formatBreakpointName(bp *api.Breakpoint, upcase bool) string {
 	if bp.WatchExpr != "" && bp.WatchExpr != bp.Name {
 		return fmt.Sprintf("%s %s on [%s]", thing, id, bp.WatchExpr)
 	}
+	if flags := formatFlagsBreakpoint(bp); flags != "" {
+		return fmt.Sprintf("%s %s on [%s]", thing, id, flags)
+	}
 	return fmt.Sprintf("%s %s", thing, id)
 }
 
 func (t *Term) formatBreakpointLocation(bp *api.Breakpoint) string {
 	var out bytes.Buffer
+	if len(bp.Addrs) > 1 && formatFlagsBreakpoint(bp) != "" {
+		// allocation and -returns-error breakpoints can have thousands of addresses
+		return fmt.Sprintf("%d locations", len(bp.Addrs))
+	}
 	if len(bp.Addrs) > 0 {
 		for i, addr := range bp.Addrs {
 			if i == 0 {
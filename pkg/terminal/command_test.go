@@ -2,8 +2,10 @@ package terminal
 
 import (
 	"bytes"
+	"compress/gzip"
 	"flag"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -459,6 +461,576 @@ func TestScopePrefix(t *testing.T) {
 	})
 }
 
+func TestGoroutinesPprof(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("goroutinestackprog", t, func(term *FakeTerminal) {
+		term.MustExec("b stacktraceme")
+		term.MustExec("continue")
+
+		outfile := filepath.Join(t.TempDir(), "goroutines.pb.gz")
+		out := term.MustExec("goroutines -pprof " + outfile)
+		if !strings.Contains(out, "Goroutine profile written to") {
+			t.Fatalf("unexpected output %q", out)
+		}
+
+		fh, err := os.Open(outfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fh.Close()
+		zr, err := gzip.NewReader(fh)
+		if err != nil {
+			t.Fatalf("profile is not gzip compressed: %v", err)
+		}
+		buf, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{"main.agoroutine", "main.stacktraceme", "goroutine.state"} {
+			if !bytes.Contains(buf, []byte(s)) {
+				t.Errorf("%q not found in profile", s)
+			}
+		}
+
+		term.AssertExecError("goroutines -pprof", "not enough arguments to -pprof")
+	})
+}
+
+func TestDeadlocksCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("deadlockprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("deadlocks")
+		for _, tgt := range []string{"Deadlock cycle 1", "Deadlock cycle 2", "Goroutines that can never be woken up:", "main.leak", "main.nilchan", "[chan send (nil chan)]", "not referenced by any other goroutine"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		if strings.Contains(out, "main.waiter") {
+			t.Errorf("main.waiter should not be reported")
+		}
+	})
+}
+
+func TestSchedCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("schedprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("sched")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"GOMAXPROCS: 1\n", "GC phase: off\n", "Ps (1):\n", "\tP 0 running M ", "runq=5 [goroutines ", "locked to goroutine "} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+	})
+}
+
+func TestPanicsCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("panicsprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("panics")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"[0] panic: interface {}(string) \"first\" [aborted]\n", "\tpanicked at frame ", "[1] panic: interface {}(*errors.errorString) *{s: \"second\"} [recovered, nested]\n", "\trecovered by frame 0: ", "main.f.func1 ("} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		out = term.MustExec("goroutine")
+		if !strings.Contains(out, "\tPanics:\n\t\t[0] panic: ") {
+			t.Errorf("goroutine output does not list panics: %q", out)
+		}
+	})
+}
+
+func TestDataRaceBreakpoint(t *testing.T) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("race detector only supported on linux/amd64 and linux/arm64")
+	}
+	test.AllowRecording(t)
+	withTestTerminalBuildFlags("datarace", t, test.EnableRaceDetector, func(term *FakeTerminal) {
+		out := term.MustExec("continue")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"> [data-race] main.main() ", "\tWrite at 0x", "\tPrevious write at 0x", "\t\tmain.writer() ", "datarace.go:11\n"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+	})
+}
+
+func TestAllocBreakpoint(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("allocprog", t, func(term *FakeTerminal) {
+		out := term.MustExec("break -alloc *main.Request")
+		if !strings.Contains(out, "Breakpoint 1 on [alloc *main.Request] set at ") {
+			t.Errorf("wrong output for break: %q", out)
+		}
+		out = term.MustExec("continue")
+		t.Logf("%s", out)
+		if !strings.Contains(out, "allocprog.go:11 (PC: ") {
+			t.Errorf("allocation frame not printed")
+		}
+		m := regexp.MustCompile(`Frame (\d+): `).FindStringSubmatch(out)
+		if m == nil {
+			t.Fatalf("allocation frame number not printed")
+		}
+		if out := term.MustExec("frame " + m[1] + " print id"); out != "0\n" {
+			t.Errorf("wrong value of id: %q", out)
+		}
+		_, err := term.Exec("break -alloc-size >1MB")
+		if err == nil || !strings.Contains(err.Error(), "only one allocation breakpoint") {
+			t.Errorf("expected error setting a second allocation breakpoint, got %v", err)
+		}
+
+		term.MustExec("clear 1")
+		term.MustExec("break big -alloc-size >1MB")
+		out = term.MustExec("continue")
+		t.Logf("%s", out)
+		if !strings.Contains(out, "allocprog.go:19 (PC: ") {
+			t.Errorf("allocation frame not printed")
+		}
+		// The frame where the thread is stopped is still selected.
+		term.MustExec("stepout")
+	})
+}
+
+func TestMethodsAndImplements(t *testing.T) {
+	withTestTerminal("ifaceimpl", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("methods main.Square")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"Methods of main.Square (3):\n", "\tArea main.Square.Area at ", "\tScale (pointer receiver) main.(*Square).Scale at "} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		out = term.MustExec("methods main.Shape")
+		if out != "Methods of interface main.Shape (2):\n\tArea\n\tPerimeter\n" {
+			t.Errorf("wrong output for methods main.Shape: %q", out)
+		}
+		out = term.MustExec("implements main.Shape")
+		if out != "Types implementing main.Shape (3):\n\t*main.Circle\n\tmain.Named\n\tmain.Square\nCandidates, method signatures not checked (1):\n\tmain.Grid\n" {
+			t.Errorf("wrong output for implements main.Shape: %q", out)
+		}
+		_, err := term.Exec("implements main.Square")
+		if err == nil {
+			t.Errorf("implements on a non-interface type did not fail")
+		}
+	})
+}
+
+func TestTraceError(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("errorigin", t, func(term *FakeTerminal) {
+		out := term.MustExec("break -returns-error ^main\\.lookup$")
+		if !strings.Contains(out, "Breakpoint 1 on [returns-error ^main\\.lookup$] set at ") {
+			t.Errorf("wrong output for break: %q", out)
+		}
+		out = term.MustExec("continue")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"> [Breakpoint 1] main.lookup() ", "Values returned:\n\t~r1: error(*main.NotFoundError) *{Name: \"missing\"}"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		term.MustExec("clear 1")
+		term.MustExec("continue")
+
+		if term.client.Recorded() {
+			out = term.MustExec("trace-error err")
+			t.Logf("%s", out)
+			if !strings.Contains(out, "errorigin.go:18 (PC: ") {
+				t.Errorf("creation of the error not found")
+			}
+			return
+		}
+
+		out = term.MustExec("trace-error err")
+		if !strings.Contains(out, "on [alloc *main.NotFoundError] set at ") || strings.Contains(out, "returns-error") {
+			t.Errorf("wrong output for trace-error without -fn: %q", out)
+		}
+		term.MustExec("clearall")
+
+		// An existing allocation breakpoint on the same type is reused, any
+		// other one prevents setting the allocation breakpoint.
+		term.MustExec("break -alloc *main.NotFoundError")
+		out = term.MustExec("trace-error err")
+		if !strings.Contains(out, "on [alloc *main.NotFoundError] already set at ") {
+			t.Errorf("wrong output for trace-error with an existing allocation breakpoint: %q", out)
+		}
+		term.MustExec("clearall")
+		term.MustExec("break -alloc-size >1MB")
+		if _, err := term.Exec("trace-error err"); err == nil || !strings.Contains(err.Error(), "only one allocation breakpoint can be set at a time") {
+			t.Errorf("wrong error for trace-error with another allocation breakpoint: %v", err)
+		}
+		out = term.MustExec("trace-error -fn ^main\\. err")
+		if !strings.Contains(out, "Could not set allocation breakpoint: ") || !strings.Contains(out, "on [returns-error ^main\\. error-type *main.NotFoundError] set at ") {
+			t.Errorf("wrong output for trace-error -fn with another allocation breakpoint: %q", out)
+		}
+		term.MustExec("clearall")
+
+		out = term.MustExec("trace-error -fn ^main\\. err")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"on [alloc *main.NotFoundError] set at ", "on [returns-error ^main\\. error-type *main.NotFoundError] set at "} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		out = term.MustExec("continue")
+		t.Logf("%s", out)
+		if !strings.Contains(out, "errorigin.go:18 (PC: ") {
+			t.Errorf("allocation of the error not found")
+		}
+		out = term.MustExec("continue")
+		t.Logf("%s", out)
+		if !strings.Contains(out, "main.lookup() ") || !strings.Contains(out, "error(*main.NotFoundError)") {
+			t.Errorf("return of the error not found")
+		}
+	})
+}
+
+func TestCtxCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("ctxchain", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("ctx my")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"[0] custom main.myCtx", "[1] withoutCancel ", "\tkey: interface {}(main.ctxKey) \"user\"\n", "\tvalue: interface {}(string) \"alice\"\n", "\tdeadline: ", "[4] timer *context.timerCtx", "\tcause: error(*errors.errorString) *{s: \"shutting down\"}\n", "[6] background "} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+		out = term.MustExec("ctx nilctx")
+		if out != "nil context\n" {
+			t.Errorf("unexpected output for nil context: %q", out)
+		}
+	})
+}
+
+func TestFdsCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("fdsprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("fds")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"\tos.File /dev/null\n", "\tnet.netFD tcp 127.0.0.1:", " read: goroutine ", "main.main: f *os.File\n", "main.reader: conn net.Conn\n", "main.acceptor: ln net.Listener\n", "\tpackage variable os.Stdout *os.File\n"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+	})
+}
+
+func TestTimersCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("timersprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("timers")
+		t.Logf("%s", out)
+		for _, tgt := range []string{"period 1m0s func time.sendTime(chan time.Time) [goroutine ", "func runtime.goroutineReady(*runtime.g) [goroutine ", "func time.goFunc(func()) afterfunc main.afterFuncCallback at ", "timersprog.go:18"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output does not contain %q", tgt)
+			}
+		}
+	})
+}
+
+func TestHeapCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		out := term.MustExec("heap ^main\\.(small|medium|large)$")
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 5 || !strings.HasSuffix(lines[1], "main.large") || !strings.HasSuffix(lines[2], "main.medium") || !strings.HasSuffix(lines[3], "main.small") {
+			t.Errorf("wrong output for 'heap':\n%s", out)
+		}
+		if fields := strings.Fields(lines[3]); len(fields) != 3 || fields[0] != "1000" {
+			t.Errorf("wrong count for main.small: %q", lines[3])
+		}
+
+		out = term.MustExec("heap -count ^main\\.(small|medium|large)$")
+		lines = strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 5 || !strings.HasSuffix(lines[1], "main.small") || !strings.HasSuffix(lines[3], "main.large") || !strings.HasPrefix(lines[4], "Total: 1103 objects") {
+			t.Errorf("wrong output for 'heap -count':\n%s", out)
+		}
+	})
+}
+
+func TestRefsCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		out := term.MustExec("refs list.next.next")
+		for _, tgt := range []string{" main.node (16 bytes) is referenced by:\n", "\tmain.list -> (*main.node)(0x", ").next -> (*main.node)(0x", "\tgoroutine 1 frame 0 h -> (*main.holder)(0x", ").target\n"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of 'refs list.next.next' does not contain %q:\n%s", tgt, out)
+			}
+		}
+
+		out = term.MustExec("refs h")
+		if !strings.HasPrefix(out, "Memory at 0x") || !strings.HasSuffix(out, " (24 bytes) is referenced by:\n\tgoroutine 1 frame 0 h\n") {
+			t.Errorf("wrong output for 'refs h':\n%s", out)
+		}
+
+		out = term.MustExec("refs 0x10")
+		if out != "Memory at 0x10 (1 bytes) is not referenced by any variable or object\n" {
+			t.Errorf("wrong output for 'refs 0x10':\n%s", out)
+		}
+	})
+}
+
+func TestSizeofCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		if out := term.MustExec("sizeof smalls"); out != "Shallow size: 24 bytes\n" {
+			t.Errorf("wrong output for 'sizeof smalls': %q", out)
+		}
+
+		out := term.MustExec("sizeof -deep smalls")
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 5 || !strings.HasPrefix(lines[1], "Retained size: ") || !strings.HasSuffix(lines[1], " bytes in 1001 heap objects") || !strings.HasSuffix(lines[3], "main.small") || !strings.HasSuffix(lines[4], "[]*main.small") {
+			t.Errorf("wrong output for 'sizeof -deep smalls':\n%s", out)
+		}
+
+		out = term.MustExec("sizeof -budget 10 smalls")
+		if !strings.Contains(out, " bytes in 10 heap objects\n") || !strings.HasSuffix(out, "retained size is a lower bound\n") {
+			t.Errorf("wrong output for 'sizeof -budget 10 smalls':\n%s", out)
+		}
+
+		term.AssertExecError("sizeof -depth x smalls", `invalid argument to -depth: "x"`)
+	})
+}
+
+func TestFindCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		list := strings.TrimSpace(term.MustExec("print uintptr(list)"))
+		out := term.MustExec("find " + list)
+		if !strings.Contains(out, " main.list [0x") {
+			t.Errorf("output of 'find %s' does not contain main.list:\n%s", list, out)
+		}
+
+		next := strings.TrimSpace(term.MustExec("print uintptr(list.next)"))
+		nextnext := strings.TrimSpace(term.MustExec("print uintptr(list.next.next)"))
+		out = term.MustExec(fmt.Sprintf("find %s +16 %s", next, nextnext))
+		if n, _ := strconv.ParseUint(next, 0, 64); !strings.HasPrefix(out, fmt.Sprintf("%#x (*main.node)(%#x) [", n, n)) || strings.Count(out, "\n") != 1 {
+			t.Errorf("wrong output for 'find %s +16 %s':\n%s", next, nextnext, out)
+		}
+
+		out = term.MustExec(`find -max 1 "holder"`)
+		if !strings.HasPrefix(out, "0x") {
+			t.Errorf("wrong output for 'find \"holder\"':\n%s", out)
+		}
+
+		out = term.MustExec(fmt.Sprintf("find %s +16 \"not there\"", next))
+		if out != "Pattern not found\n" {
+			t.Errorf("wrong output for missing pattern: %q", out)
+		}
+
+		for _, cmd := range []string{"find -size 3 1", "find -size 1 256", "find 0x1000 1", "find"} {
+			if _, err := term.Exec(cmd); err == nil {
+				t.Errorf("%q did not return an error", cmd)
+			}
+		}
+	})
+}
+
+func TestMapsCommand(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory map not supported")
+	}
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		out := term.MustExec("maps")
+		for _, tgt := range []string{" r-x ", " text ", " data ", " heap ", " stack goroutine 1 "} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of 'maps' does not contain %q:\n%s", tgt, out)
+			}
+		}
+
+		lines := strings.Split(strings.TrimSpace(term.MustExec("maps stack")), "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[0], "Start ") {
+			t.Fatalf("wrong output of 'maps stack': %q", lines)
+		}
+		for _, line := range lines[1:] {
+			if !strings.Contains(line, " stack goroutine ") {
+				t.Errorf("unexpected line in the output of 'maps stack': %q", line)
+			}
+		}
+	})
+}
+
+func TestInfoAddrCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		for _, tc := range []struct{ expr, tgt string }{
+			{"uintptr(&list)", " is in package variable main.list\n"},
+			{"uintptr(list)", " is in heap object (*main.node)("},
+			{"uintptr(&larges)", " (main.main)\n"},
+		} {
+			addr := strings.TrimSpace(term.MustExec("print " + tc.expr))
+			out := term.MustExec("info addr " + addr)
+			if !strings.Contains(out, tc.tgt) {
+				t.Errorf("output of 'info addr %s' (%s) does not contain %q:\n%s", addr, tc.expr, tc.tgt, out)
+			}
+		}
+
+		for _, cmd := range []string{"info", "info addr", "info addr main.main", "info frame"} {
+			if _, err := term.Exec(cmd); err == nil {
+				t.Errorf("%q did not return an error", cmd)
+			}
+		}
+	})
+}
+
+func TestSetmemCommand(t *testing.T) {
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		addr := strings.TrimSpace(term.MustExec("print uintptr(&list.n)"))
+		term.MustExec("setmem " + addr + " 42")
+		if out := term.MustExec("print list.n"); out != "42\n" {
+			t.Errorf("wrong value after setmem: %q", out)
+		}
+		term.MustExec("setmem " + addr + " 0x1ff -size 2")
+		if out := term.MustExec("print list.n"); out != "511\n" {
+			t.Errorf("wrong value after setmem -size 2: %q", out)
+		}
+		term.MustExec("setmem -size 1 " + addr + ` "\x07"`)
+		if out := term.MustExec("print list.n"); out != "263\n" {
+			t.Errorf("wrong value after setmem with a string: %q", out)
+		}
+
+		for _, cmd := range []string{"setmem " + addr, "setmem " + addr + " 1 -size 3", "setmem -size 1 " + addr + " 256"} {
+			if _, err := term.Exec(cmd); err == nil {
+				t.Errorf("%q did not return an error", cmd)
+			}
+		}
+	})
+}
+
+func TestDumpMemoryCommand(t *testing.T) {
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		dir := t.TempDir()
+
+		name := filepath.Join(dir, "name")
+		term.MustExec("dump value " + name + " h.name")
+		if b, err := os.ReadFile(name); err != nil || string(b) != "holder" {
+			t.Errorf("wrong contents of %s: %q %v", name, b, err)
+		}
+
+		list := strings.TrimSpace(term.MustExec("print uintptr(list)"))
+		value, memory := filepath.Join(dir, "value"), filepath.Join(dir, "memory")
+		term.MustExec("dump value " + value + " list")
+		out := term.MustExec("dump memory " + memory + " " + list + " +16")
+		if !strings.HasPrefix(out, "Wrote 16 bytes at ") {
+			t.Errorf("wrong output of dump memory: %q", out)
+		}
+		b1, err1 := os.ReadFile(value)
+		b2, err2 := os.ReadFile(memory)
+		if err1 != nil || err2 != nil || len(b1) != 16 || !bytes.Equal(b1, b2) {
+			t.Errorf("wrong contents: %x %v %x %v", b1, err1, b2, err2)
+		}
+
+		b1[8] = 99
+		assertNoError(t, os.WriteFile(value, b1, 0o644), "WriteFile")
+		term.MustExec("restore " + value + " " + list)
+		if out := term.MustExec("print list.n"); out != "99\n" {
+			t.Errorf("wrong value after restore: %q", out)
+		}
+
+		if _, err := term.Exec("dump memory " + memory + " 0x20 0x10"); err == nil {
+			t.Errorf("dump memory with an invalid range did not return an error")
+		}
+	})
+}
+
+func TestDumpCoreOptions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("not supported")
+	}
+	withTestTerminal("heapprog", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		corePath := filepath.Join(t.TempDir(), "core.gz")
+
+		out := term.MustExec("dump -exclude-file-backed -heap-inuse -max-mapping-size 64M " + corePath)
+		if strings.Contains(out, "error dumping") || strings.Contains(out, "canceled") {
+			t.Fatalf("wrong output of dump: %q", out)
+		}
+		b, err := os.ReadFile(corePath)
+		if err != nil || len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+			t.Fatalf("core dump is not a gzip file: %v", err)
+		}
+
+		for _, cmd := range []string{"dump -heap-inuse", "dump -max-mapping-size 0 " + corePath, "dump -max-mapping-size 17179869184G " + corePath, "dump -unknown " + corePath} {
+			if _, err := term.Exec(cmd); err == nil {
+				t.Errorf("%q did not return an error", cmd)
+			}
+		}
+	})
+}
+
+func TestChanCommand(t *testing.T) {
+	test.AllowRecording(t)
+	withTestTerminal("chanstate", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		out := term.MustExec("chan buffered")
+		for _, tgt := range []string{"Closed: false", "Buffer: 4/4", "[0] 2\n", "[3] 5\n", "Receivers waiting: 0", "Senders waiting: 0"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of 'chan buffered' does not contain %q:\n%s", tgt, out)
+			}
+		}
+
+		out = term.MustExec("chan closed")
+		if !strings.Contains(out, "Closed: true") {
+			t.Errorf("output of 'chan closed' does not contain 'Closed: true':\n%s", out)
+		}
+
+		out = term.MustExec("chan unbuffered")
+		for _, tgt := range []string{"Senders waiting: 3", `sending: main.item {name: "first", n: 1}`, `sending: main.item {name: "select", n: 3}`, "in select statement, also waiting to receive from 0x"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of 'chan unbuffered' does not contain %q:\n%s", tgt, out)
+			}
+		}
+
+		out = term.MustExec("chan other")
+		if !strings.Contains(out, "Receivers waiting: 1") || !strings.Contains(out, "in select statement, also waiting to send to 0x") {
+			t.Errorf("wrong output for 'chan other':\n%s", out)
+		}
+
+		out = term.MustExec("print buffered")
+		for _, tgt := range []string{"Goroutines waiting on this channel:", "Closed: false", "Buffer: 4/4", "[3] 5\n", "Receivers waiting: 0"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of 'print buffered' does not contain %q:\n%s", tgt, out)
+			}
+		}
+
+		out = term.MustExec("print other")
+		if !strings.Contains(out, "Receivers waiting: 1") || !strings.Contains(out, "in select statement, also waiting to send to 0x") {
+			t.Errorf("wrong output for 'print other':\n%s", out)
+		}
+
+		if out := term.MustExec("chan nilchan"); out != "nil channel\n" {
+			t.Errorf("wrong output for 'chan nilchan': %q", out)
+		}
+		term.AssertExecError("chan", "not enough arguments")
+	})
+}
+
 func TestOnPrefix(t *testing.T) {
 	const prefix = "\ti: "
 	test.AllowRecording(t)
@@ -130,6 +130,58 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["cancel_next"] = "builtin cancel_next()"
+	r["chan_state"] = starlark.NewBuiltin("chan_state", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ChanStateIn
+		var rpcRet rpc2.ChanStateOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ChanState", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["chan_state"] = "builtin chan_state(Scope, Expr, Cfg)\n\nchan_state returns the state of the channel arg.Expr evaluates to: its\nbuffered elements in the order in which they will be received, whether\nit is closed and the goroutines waiting to send to or receive from it,\nalong with the values being sent and the other cases of the select\nstatements they are blocked in."
 	r["checkpoint"] = starlark.NewBuiltin("checkpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -313,6 +365,58 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["raw_command"] = "builtin raw_command(Name, ThreadID, GoroutineID, ReturnInfoLoadConfig, Expr, WithEvents, UnsafeCall)\n\nraw_command interrupts, continues and steps through the program."
+	r["context_chain"] = starlark.NewBuiltin("context_chain", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ContextChainIn
+		var rpcRet rpc2.ContextChainOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ContextChain", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["context_chain"] = "builtin context_chain(Scope, Expr, Cfg)\n\ncontext_chain returns the chain of parents of the context.Context\narg.Expr evaluates to, starting with the context itself. For each\ncontext the kind, deadline, cancellation state and cause, and key/value\npair are returned."
 	r["create_breakpoint"] = starlark.NewBuiltin("create_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -448,6 +552,19 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["create_watchpoint"] = "builtin create_watchpoint(Scope, Expr, Type)"
+	r["deadlocks"] = starlark.NewBuiltin("deadlocks", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DeadlocksIn
+		var rpcRet rpc2.DeadlocksOut
+		err := env.ctx.Client().CallAPI("Deadlocks", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["deadlocks"] = "builtin deadlocks()\n\ndeadlocks builds a wait-for graph of the goroutines blocked on channel\noperations, select statements and semaphores (sync.Mutex,\nsync.WaitGroup, etc) and returns the goroutines that can never be woken\nup, along with the cycles of goroutines waiting on each other."
 	r["debug_info_directories"] = starlark.NewBuiltin("debug_info_directories", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -487,6 +604,37 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["debug_info_directories"] = "builtin debug_info_directories(Set, List)"
+	r["describe_address"] = starlark.NewBuiltin("describe_address", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DescribeAddressIn
+		var rpcRet rpc2.DescribeAddressOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Addr, "Addr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Addr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Addr, "Addr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("DescribeAddress", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["describe_address"] = "builtin describe_address(Addr)\n\ndescribe_address returns a description of what arg.Addr points to: the\nfunction, offset and source line for text addresses, the package\nvariable for data addresses, the heap span, object and type for heap\naddresses and the goroutine and stack frame for stack addresses."
 	r["detach"] = starlark.NewBuiltin("detach", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -600,11 +748,19 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Options, "Options")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
 			case "Destination":
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Destination, "Destination")
+			case "Options":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Options, "Options")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -618,7 +774,7 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		}
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
-	doc["dump_start"] = "builtin dump_start(Destination)\n\ndump_start starts a core dump to arg.Destination."
+	doc["dump_start"] = "builtin dump_start(Destination, Options)\n\ndump_start starts a core dump to arg.Destination, if arg.Destination ends\nin \".gz\" the core dump is compressed with gzip. Arg.Options selects the\nmemory written to the core dump."
 	r["dump_wait"] = starlark.NewBuiltin("dump_wait", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -741,6 +897,19 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["examine_memory"] = "builtin examine_memory(Address, Length)"
+	r["file_descriptors"] = starlark.NewBuiltin("file_descriptors", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.FileDescriptorsIn
+		var rpcRet rpc2.FileDescriptorsOut
+		err := env.ctx.Client().CallAPI("FileDescriptors", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["file_descriptors"] = "builtin file_descriptors()\n\nfile_descriptors returns the file descriptors of the target.\nFor live processes on linux the descriptors are read from /proc,\notherwise only the descriptors held by os.File, net.Conn and similar\nvariables can be found. Each descriptor is associated to the goroutines\nparked in the netpoller waiting for it and to the variables that hold\nit."
 	r["find_location"] = starlark.NewBuiltin("find_location", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -798,6 +967,61 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["find_location"] = "builtin find_location(Scope, Loc, IncludeNonExecutableLines, SubstitutePathRules)\n\nfind_location returns concrete location information described by a location expression.\n\n\tloc ::= <filename>:<line> | <function>[:<line>] | /<regex>/ | (+|-)<offset> | <line> | *<address>\n\t* <filename> can be the full path of a file or just a suffix\n\t* <function> ::= <package>.<receiver type>.<name> | <package>.(*<receiver type>).<name> | <receiver type>.<name> | <package>.<name> | (*<receiver type>).<name> | <name>\n\t  <function> must be unambiguous\n\t* /<regex>/ will return a location for each function matched by regex\n\t* +<offset> returns a location for the line that is <offset> lines after the current line\n\t* -<offset> returns a location for the line that is <offset> lines before the current line\n\t* <line> returns a location for a line in the current file\n\t* *<address> returns the location corresponding to the specified address\n\nNOTE: this function does not actually set breakpoints."
+	r["find_memory"] = starlark.NewBuiltin("find_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.FindMemoryIn
+		var rpcRet rpc2.FindMemoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Start, "Start")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.End, "End")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Pattern, "Pattern")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.MaxMatches, "MaxMatches")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Start":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Start, "Start")
+			case "End":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.End, "End")
+			case "Pattern":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Pattern, "Pattern")
+			case "MaxMatches":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxMatches, "MaxMatches")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("FindMemory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["find_memory"] = "builtin find_memory(Start, End, Pattern, MaxMatches)\n\nfind_memory searches the memory between arg.Start and arg.End for\narg.Pattern. If arg.Start and arg.End are both 0 all the readable\nmappings of the target process are searched.\n\nEach match is annotated with the memory mapping, the function or package\nvariable and the heap object containing it, when known.\nAt most arg.MaxMatches matches are returned, out.Truncated is set if more\nexist. If arg.MaxMatches is 0 all matches are returned."
 	r["follow_exec"] = starlark.NewBuiltin("follow_exec", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -964,14 +1188,14 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["get_thread"] = "builtin get_thread(Id)\n\nget_thread gets a thread by its ID."
-	r["guess_substitute_path"] = starlark.NewBuiltin("guess_substitute_path", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["goroutine_profile"] = starlark.NewBuiltin("goroutine_profile", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.GuessSubstitutePathIn
-		var rpcRet rpc2.GuessSubstitutePathOut
+		var rpcArgs rpc2.GoroutineProfileIn
+		var rpcRet rpc2.GoroutineProfileOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Args, "Args")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Depth, "Depth")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -979,8 +1203,8 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Args":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Args, "Args")
+			case "Depth":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Depth, "Depth")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -988,47 +1212,52 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("GuessSubstitutePath", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GoroutineProfile", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
-	doc["guess_substitute_path"] = "builtin guess_substitute_path(Args)"
-	r["is_multiclient"] = starlark.NewBuiltin("is_multiclient", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	doc["goroutine_profile"] = "builtin goroutine_profile(Depth)\n\ngoroutine_profile returns a pprof goroutine profile of the current state\nof the target, stacktraces are collected up to the specified Depth (if\nDepth is 0 a default depth is used).\nGoroutines with identical stacktraces and labels are counted together,\nthe state of each goroutine, or its wait reason, is recorded in the\n\"goroutine.state\" label."
+	r["guess_substitute_path"] = starlark.NewBuiltin("guess_substitute_path", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.IsMulticlientIn
-		var rpcRet rpc2.IsMulticlientOut
-		err := env.ctx.Client().CallAPI("IsMulticlient", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
+		var rpcArgs rpc2.GuessSubstitutePathIn
+		var rpcRet rpc2.GuessSubstitutePathOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Args, "Args")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
 		}
-		return env.interfaceToStarlarkValue(&rpcRet), nil
-	})
-	doc["is_multiclient"] = "builtin is_multiclient()"
-	r["last_modified"] = starlark.NewBuiltin("last_modified", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Args":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Args, "Args")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
 		}
-		var rpcArgs rpc2.LastModifiedIn
-		var rpcRet rpc2.LastModifiedOut
-		err := env.ctx.Client().CallAPI("LastModified", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GuessSubstitutePath", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
-	doc["last_modified"] = "builtin last_modified()"
-	r["breakpoints"] = starlark.NewBuiltin("breakpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	doc["guess_substitute_path"] = "builtin guess_substitute_path(Args)"
+	r["heap_histogram"] = starlark.NewBuiltin("heap_histogram", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListBreakpointsIn
-		var rpcRet rpc2.ListBreakpointsOut
+		var rpcArgs rpc2.HeapHistogramIn
+		var rpcRet rpc2.HeapHistogramOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.All, "All")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1036,8 +1265,8 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "All":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.All, "All")
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1045,29 +1274,166 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListBreakpoints", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("HeapHistogram", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
-	doc["breakpoints"] = "builtin breakpoints(All)\n\nbreakpoints gets all breakpoints."
-	r["checkpoints"] = starlark.NewBuiltin("checkpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	doc["heap_histogram"] = "builtin heap_histogram(Filter)\n\nheap_histogram walks the heap of the target and returns the number of\nallocated objects and the number of bytes they occupy for each type\nmatching the regular expression Filter, sorted by decreasing size.\n\nThe type of objects that are not reachable from a typed pointer and do\nnot have a malloc header is unknown, these objects are grouped by size."
+	r["heap_references"] = starlark.NewBuiltin("heap_references", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListCheckpointsIn
-		var rpcRet rpc2.ListCheckpointsOut
-		err := env.ctx.Client().CallAPI("ListCheckpoints", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
+		var rpcArgs rpc2.HeapReferencesIn
+		var rpcRet rpc2.HeapReferencesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
 		}
-		return env.interfaceToStarlarkValue(&rpcRet), nil
-	})
-	doc["checkpoints"] = "builtin checkpoints()"
-	r["dynamic_libraries"] = starlark.NewBuiltin("dynamic_libraries", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.MaxChains, "MaxChains")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "MaxChains":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxChains, "MaxChains")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("HeapReferences", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["heap_references"] = "builtin heap_references(Scope, Expr, MaxChains)\n\nheap_references searches package variables, local variables and heap\nobjects for pointers to the memory arg.Expr refers to and returns, for\neach of them, the chain of references leading to it from a variable.\n\nIf arg.Expr evaluates to a pointer, slice, string, map or channel the\nmemory it points to is searched, if it is an integer constant it is\ninterpreted as an address, otherwise references to the memory storing\nthe value of the expression are searched. When the address is inside a\nheap object references to any part of the object are searched.\n\nAt most arg.MaxChains chains are returned, 0 means no limit."
+	r["implements"] = starlark.NewBuiltin("implements", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ImplementsIn
+		var rpcRet rpc2.ImplementsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Type, "Type")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Type":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Type, "Type")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("Implements", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["implements"] = "builtin implements(Type)\n\nimplements returns the types implementing the interface Type: the types\nfor which the runtime has an itab for the interface and the named types\nwhose methods, found in debug info, have the names of all the methods of\nthe interface."
+	r["is_multiclient"] = starlark.NewBuiltin("is_multiclient", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.IsMulticlientIn
+		var rpcRet rpc2.IsMulticlientOut
+		err := env.ctx.Client().CallAPI("IsMulticlient", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["is_multiclient"] = "builtin is_multiclient()"
+	r["last_modified"] = starlark.NewBuiltin("last_modified", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.LastModifiedIn
+		var rpcRet rpc2.LastModifiedOut
+		err := env.ctx.Client().CallAPI("LastModified", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["last_modified"] = "builtin last_modified()"
+	r["breakpoints"] = starlark.NewBuiltin("breakpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListBreakpointsIn
+		var rpcRet rpc2.ListBreakpointsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.All, "All")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "All":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.All, "All")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListBreakpoints", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["breakpoints"] = "builtin breakpoints(All)\n\nbreakpoints gets all breakpoints."
+	r["checkpoints"] = starlark.NewBuiltin("checkpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListCheckpointsIn
+		var rpcRet rpc2.ListCheckpointsOut
+		err := env.ctx.Client().CallAPI("ListCheckpoints", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["checkpoints"] = "builtin checkpoints()"
+	r["dynamic_libraries"] = starlark.NewBuiltin("dynamic_libraries", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
 		}
 		var rpcArgs rpc2.ListDynamicLibrariesIn
 		var rpcRet rpc2.ListDynamicLibrariesOut
@@ -1160,6 +1526,45 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["functions"] = "builtin functions(Filter, FollowCalls)\n\nfunctions lists all functions in the process matching filter."
+	r["goroutine_stacks"] = starlark.NewBuiltin("goroutine_stacks", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListGoroutineStacksIn
+		var rpcRet rpc2.ListGoroutineStacksOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Depth, "Depth")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Opts, "Opts")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Depth":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Depth, "Depth")
+			case "Opts":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Opts, "Opts")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListGoroutineStacks", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["goroutine_stacks"] = "builtin goroutine_stacks(Depth, Opts)\n\ngoroutine_stacks returns all goroutines along with their stacktraces\nup to the specified Depth.\nLocal variables and function arguments are never loaded."
 	r["goroutines"] = starlark.NewBuiltin("goroutines", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -1487,6 +1892,81 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["types"] = "builtin types(Filter)\n\ntypes lists all types in the process matching filter."
+	r["memory_map"] = starlark.NewBuiltin("memory_map", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.MemoryMapIn
+		var rpcRet rpc2.MemoryMapOut
+		err := env.ctx.Client().CallAPI("MemoryMap", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["memory_map"] = "builtin memory_map()\n\nmemory_map returns the memory mappings of the target process, with their\naddress range, permissions, mapped file and offset. Each mapping is\nclassified as Go heap arena, goroutine stack, text or data of a Go\nmodule, or shared library. Goroutine stacks are returned as separate\nmappings, splitting the mapping that contains them."
+	r["method_set"] = starlark.NewBuiltin("method_set", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.MethodSetIn
+		var rpcRet rpc2.MethodSetOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Type, "Type")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Type":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Type, "Type")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("MethodSet", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["method_set"] = "builtin method_set(Type)\n\nmethod_set returns the method set of a type. For types other than\ninterfaces Type can be either T or *T, the methods with a pointer\nreceiver are returned in both cases but are marked as such."
+	r["panics"] = starlark.NewBuiltin("panics", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.PanicsIn
+		var rpcRet rpc2.PanicsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("Panics", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["panics"] = "builtin panics(Id)\n\npanics returns the panics in progress on goroutine Id, oldest first,\nincluding panics that have been recovered by a deferred call that is\nstill running."
 	r["process_pid"] = starlark.NewBuiltin("process_pid", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -1584,6 +2064,76 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["restart"] = "builtin restart(Position, ResetArgs, NewArgs, Rerecord, Rebuild, NewRedirects)\n\nrestart restarts program."
+	r["retained_size"] = starlark.NewBuiltin("retained_size", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.RetainedSizeIn
+		var rpcRet rpc2.RetainedSizeOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.MaxDepth, "MaxDepth")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.MaxObjects, "MaxObjects")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "MaxDepth":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxDepth, "MaxDepth")
+			case "MaxObjects":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxObjects, "MaxObjects")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("RetainedSize", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["retained_size"] = "builtin retained_size(Scope, Expr, MaxDepth, MaxObjects)\n\nretained_size returns the size of the value arg.Expr evaluates to and the\ntotal size of the heap objects reachable from it through pointers,\nslices, strings, maps, channels and interfaces, broken down by type.\nObjects reachable through multiple paths are counted once.\n\nPointers are followed at most arg.MaxDepth times and at most\narg.MaxObjects objects are visited, 0 disables the corresponding limit.\nIf arg.MaxDepth is negative only the shallow size is returned."
+	r["scheduler_info"] = starlark.NewBuiltin("scheduler_info", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SchedulerInfoIn
+		var rpcRet rpc2.SchedulerInfoOut
+		err := env.ctx.Client().CallAPI("SchedulerInfo", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["scheduler_info"] = "builtin scheduler_info()\n\nscheduler_info returns the state of the Go scheduler, read from the\nruntime: the list of Ps with their local run queues, the list of Ms, the\nlength of the global run queue, the GC phase and GOMAXPROCS."
 	r["set_expr"] = starlark.NewBuiltin("set_expr", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -1735,6 +2285,19 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["state"] = "builtin state(NonBlocking)\n\nstate returns the current debugger state."
+	r["timers"] = starlark.NewBuiltin("timers", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.TimersIn
+		var rpcRet rpc2.TimersOut
+		err := env.ctx.Client().CallAPI("Timers", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["timers"] = "builtin timers()\n\ntimers returns the pending timers of the Go runtime, read from the\ntimer heap of each P, sorted by the time at which they fire.\nTimers of time.Timer and time.Ticker are only listed while a goroutine\nis blocked receiving from their channel."
 	r["toggle_breakpoint"] = starlark.NewBuiltin("toggle_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -1774,5 +2337,85 @@ func (env *Env) starlarkPredeclare() (starlark.StringDict, map[string]string) {
 		return env.interfaceToStarlarkValue(&rpcRet), nil
 	})
 	doc["toggle_breakpoint"] = "builtin toggle_breakpoint(Id, Name)\n\ntoggle_breakpoint toggles on or off a breakpoint by Name (if Name is not an\nempty string) or by ID."
+	r["value_memory"] = starlark.NewBuiltin("value_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ValueMemoryIn
+		var rpcRet rpc2.ValueMemoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ValueMemory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["value_memory"] = "builtin value_memory(Scope, Expr)\n\nvalue_memory returns the address and size of the memory storing the value\nof arg.Expr: the backing array of slices, the contents of strings, the\nvalue pointed to by pointers and the memory storing the value itself for\nall other types. The memory can then be read with ExamineMemory."
+	r["write_memory"] = starlark.NewBuiltin("write_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.WriteMemoryIn
+		var rpcRet rpc2.WriteMemoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Address, "Address")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Data, "Data")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Address":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Address, "Address")
+			case "Data":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Data, "Data")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("WriteMemory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(&rpcRet), nil
+	})
+	doc["write_memory"] = "builtin write_memory(Address, Data)\n\nwrite_memory writes arg.Data to the memory of the target process at\narg.Address. Writing the memory of core files and recordings is not\nsupported."
 	return r, doc
 }
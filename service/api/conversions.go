@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/pprof"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -33,6 +34,11 @@ func ConvertLogicalBreakpoint(lbp *proc.LogicalBreakpoint) *Breakpoint {
 		UserData:         lbp.UserData,
 		RootFuncName:     lbp.RootFuncName,
 		TraceFollowCalls: lbp.TraceFollowCalls,
+		AllocType:        lbp.Set.AllocType,
+		AllocSize:        lbp.Set.AllocSize,
+		ReturnsError:     lbp.Set.ReturnsError,
+		ErrorType:        lbp.Set.ErrorType,
+		AllocAddr:        lbp.Set.AllocAddr,
 	}
 
 	b.HitCount = map[string]uint64{}
@@ -297,6 +303,7 @@ func ConvertGoroutine(tgt *proc.Target, g *proc.G) *Goroutine {
 	if g.Unreadable != nil {
 		return &Goroutine{Unreadable: g.Unreadable.Error()}
 	}
+	panics, _ := proc.GoroutinePanics(tgt, g)
 	return &Goroutine{
 		ID:             g.ID,
 		CurrentLoc:     ConvertLocation(g.CurrentLoc),
@@ -307,10 +314,323 @@ func ConvertGoroutine(tgt *proc.Target, g *proc.G) *Goroutine {
 		WaitSince:      g.WaitSince,
 		WaitReason:     g.WaitReason,
 		Labels:         g.Labels(),
+		Panics:         ConvertPanics(panics),
 		Status:         g.Status,
 	}
 }
 
+// ConvertDataRace converts a proc.DataRace into an api.DataRace.
+func ConvertDataRace(r *proc.DataRace) *DataRace {
+	if r == nil {
+		return nil
+	}
+	accs := make([]DataRaceAccess, len(r.Accesses))
+	for i, acc := range r.Accesses {
+		accs[i] = DataRaceAccess{
+			Addr:          acc.Addr,
+			Size:          acc.Size,
+			Write:         acc.Write,
+			Atomic:        acc.Atomic,
+			RaceGoroutine: acc.RaceGoroutine,
+			GoroutineID:   acc.GoroutineID,
+			Stack:         make([]Location, len(acc.Stack)),
+		}
+		for j := range acc.Stack {
+			accs[i].Stack[j] = ConvertLocation(acc.Stack[j])
+		}
+	}
+	return &DataRace{Accesses: accs}
+}
+
+// ConvertPanics converts a slice of proc.Panic to a slice of api.Panic.
+func ConvertPanics(panics []proc.Panic) []Panic {
+	if len(panics) == 0 {
+		return nil
+	}
+	convertLoc := func(loc *proc.Location) *Location {
+		if loc == nil {
+			return nil
+		}
+		r := ConvertLocation(*loc)
+		return &r
+	}
+	r := make([]Panic, len(panics))
+	for i, p := range panics {
+		r[i] = Panic{
+			Addr:         p.Addr,
+			Recovered:    p.Recovered,
+			Repanicked:   p.Repanicked,
+			Goexit:       p.Goexit,
+			Nested:       p.Nested,
+			Aborted:      p.Aborted,
+			PanicFrame:   p.PanicFrame,
+			PanicLoc:     convertLoc(p.PanicLoc),
+			RecoverFrame: p.RecoverFrame,
+			RecoverLoc:   convertLoc(p.RecoverLoc),
+		}
+		if p.Value != nil {
+			r[i].Value = ConvertVar(p.Value)
+		}
+	}
+	return r
+}
+
+// ConvertChanState converts a proc.ChanState to an api.ChanState.
+func ConvertChanState(tgt *proc.Target, cs *proc.ChanState) *ChanState {
+	r := &ChanState{Addr: cs.Addr, Len: cs.Len, Cap: cs.Cap, Closed: cs.Closed}
+	if cs.Queue != nil {
+		r.Queue = ConvertVar(cs.Queue)
+	}
+	convertWaiters := func(ws []proc.ChanWaiter) []ChanWaiter {
+		r := make([]ChanWaiter, len(ws))
+		for i, w := range ws {
+			r[i] = ChanWaiter{Goroutine: ConvertGoroutine(tgt, w.G), Select: w.Select}
+			if w.Value != nil {
+				r[i].Value = ConvertVar(w.Value)
+			}
+			for _, c := range w.SelectCases {
+				r[i].SelectCases = append(r[i].SelectCases, ChanSelectCase{Chan: c.Chan, Send: c.Send})
+			}
+		}
+		return r
+	}
+	r.Recvq = convertWaiters(cs.Recvq)
+	r.Sendq = convertWaiters(cs.Sendq)
+	return r
+}
+
+// ConvertContextChain converts a slice of proc.ContextLayer to a slice of
+// api.ContextLayer.
+func ConvertContextChain(layers []proc.ContextLayer) []ContextLayer {
+	convertVar := func(v *proc.Variable) *Variable {
+		if v == nil {
+			return nil
+		}
+		return ConvertVar(v)
+	}
+	r := make([]ContextLayer, len(layers))
+	for i, layer := range layers {
+		r[i] = ContextLayer{
+			Kind:      layer.Kind,
+			Type:      layer.Type,
+			Addr:      layer.Addr,
+			Deadline:  convertVar(layer.Deadline),
+			Canceled:  layer.Canceled,
+			Err:       convertVar(layer.Err),
+			Cause:     convertVar(layer.Cause),
+			AfterFunc: convertVar(layer.AfterFunc),
+			Key:       convertVar(layer.Key),
+			Value:     convertVar(layer.Value),
+		}
+	}
+	return r
+}
+
+// ConvertHeapTypeStats converts a slice of proc.HeapTypeStats to a slice
+// of api.HeapTypeStats.
+func ConvertHeapTypeStats(stats []proc.HeapTypeStats) []HeapTypeStats {
+	r := make([]HeapTypeStats, len(stats))
+	for i := range stats {
+		r[i] = HeapTypeStats(stats[i])
+	}
+	return r
+}
+
+// ConvertHeapReferences converts a proc.HeapReferences to an
+// api.HeapReferences.
+func ConvertHeapReferences(refs *proc.HeapReferences) *HeapReferences {
+	r := &HeapReferences{Addr: refs.Addr, Size: refs.Size, Type: refs.Type, Truncated: refs.Truncated}
+	r.Chains = make([][]HeapRefNode, len(refs.Chains))
+	for i, chain := range refs.Chains {
+		r.Chains[i] = make([]HeapRefNode, len(chain))
+		for j := range chain {
+			r.Chains[i][j] = HeapRefNode(chain[j])
+		}
+	}
+	return r
+}
+
+// ConvertRetainedSize converts a proc.RetainedSize to an api.RetainedSize.
+func ConvertRetainedSize(r *proc.RetainedSize) *RetainedSize {
+	return &RetainedSize{Shallow: r.Shallow, Retained: r.Retained, Objects: r.Objects, Types: ConvertHeapTypeStats(r.Types), Truncated: r.Truncated}
+}
+
+// ConvertMemoryMapEntry converts a proc.MemoryMapEntry to an
+// api.MemoryMapEntry.
+func ConvertMemoryMapEntry(mme *proc.MemoryMapEntry) *MemoryMapEntry {
+	return &MemoryMapEntry{Addr: mme.Addr, Size: mme.Size, Read: mme.Read, Write: mme.Write, Exec: mme.Exec, Filename: mme.Filename, Offset: mme.Offset}
+}
+
+// ConvertAddrInfo converts a proc.AddrInfo to an api.AddrInfo.
+func ConvertAddrInfo(tgt *proc.Target, info *proc.AddrInfo) *AddrInfo {
+	r := &AddrInfo{
+		Addr:           info.Addr,
+		Image:          info.Image,
+		Function:       ConvertFunction(info.Function),
+		FunctionOffset: info.FunctionOffset,
+		File:           info.File,
+		Line:           info.Line,
+		Variable:       info.Variable,
+		VariableOffset: info.VariableOffset,
+		HeapSpanBase:   info.HeapSpanBase,
+		HeapSpanLimit:  info.HeapSpanLimit,
+		HeapElemSize:   info.HeapElemSize,
+		HeapObject:     info.HeapObject,
+		HeapObjectSize: info.HeapObjectSize,
+		HeapType:       info.HeapType,
+		Frame:          info.Frame,
+		FrameFunction:  ConvertFunction(info.FrameFunction),
+	}
+	if info.Mapping != nil {
+		r.Mapping = ConvertMemoryMapEntry(info.Mapping)
+	}
+	if info.Goroutine != nil {
+		r.Goroutine = ConvertGoroutine(tgt, info.Goroutine)
+	}
+	return r
+}
+
+// ConvertMemoryMappings converts a slice of proc.MemoryMapping to a slice
+// of api.MemoryMapping.
+func ConvertMemoryMappings(maps []proc.MemoryMapping) []MemoryMapping {
+	r := make([]MemoryMapping, len(maps))
+	for i := range maps {
+		r[i] = MemoryMapping{MemoryMapEntry: *ConvertMemoryMapEntry(&maps[i].MemoryMapEntry), Kind: maps[i].Kind.String(), GoroutineID: maps[i].GoroutineID}
+	}
+	return r
+}
+
+// ConvertMemoryMatches converts a slice of proc.MemoryMatch to a slice of
+// api.MemoryMatch.
+func ConvertMemoryMatches(matches []proc.MemoryMatch) []MemoryMatch {
+	r := make([]MemoryMatch, len(matches))
+	for i, m := range matches {
+		r[i] = MemoryMatch{Addr: m.Addr, Symbol: m.Symbol, SymbolOffset: m.SymbolOffset, HeapObject: m.HeapObject, HeapType: m.HeapType}
+		if m.Mapping != nil {
+			r[i].Mapping = ConvertMemoryMapEntry(m.Mapping)
+		}
+	}
+	return r
+}
+
+// ConvertDeadlocks converts a proc.DeadlockReport to an api.Deadlocks.
+func ConvertDeadlocks(tgt *proc.Target, r *proc.DeadlockReport) *Deadlocks {
+	d := &Deadlocks{Cycles: r.Cycles}
+	for _, bg := range r.Goroutines {
+		dg := DeadlockedGoroutine{
+			Goroutine:  ConvertGoroutine(tgt, bg.G),
+			WaitReason: bg.WaitReason,
+			WaitsFor:   bg.WaitsFor,
+		}
+		for _, res := range bg.Resources {
+			dg.Resources = append(dg.Resources, WaitResource{Kind: res.Kind.String(), Addr: res.Addr})
+		}
+		d.Goroutines = append(d.Goroutines, dg)
+	}
+	return d
+}
+
+// ConvertSchedInfo converts a proc.SchedInfo to an api.SchedInfo.
+func ConvertSchedInfo(si *proc.SchedInfo) *SchedInfo {
+	r := &SchedInfo{
+		GOMAXPROCS:     si.GOMAXPROCS,
+		GCPhase:        si.GCPhase,
+		GCWaiting:      si.GCWaiting,
+		GlobalRunQSize: si.GlobalRunQSize,
+		IdlePs:         si.IdlePs,
+		SpinningMs:     si.SpinningMs,
+		Ps:             make([]SchedP, len(si.Ps)),
+		Ms:             make([]SchedM, len(si.Ms)),
+	}
+	for i, p := range si.Ps {
+		r.Ps[i] = SchedP{ID: p.ID, Status: p.Status, M: p.M, CurG: p.CurG, RunQ: p.RunQ, SchedTick: p.SchedTick, SyscallTick: p.SyscallTick}
+	}
+	for i, m := range si.Ms {
+		r.Ms[i] = SchedM{ID: m.ID, ThreadID: m.ThreadID, P: m.P, CurG: m.CurG, LockedG: m.LockedG, Spinning: m.Spinning, Blocked: m.Blocked}
+	}
+	return r
+}
+
+// ConvertFileDescriptors converts a slice of proc.FileDescriptor to a
+// slice of api.FileDescriptor.
+func ConvertFileDescriptors(fds []proc.FileDescriptor) []FileDescriptor {
+	r := make([]FileDescriptor, len(fds))
+	for i, fd := range fds {
+		r[i] = FileDescriptor{
+			FD:         fd.FD,
+			Path:       fd.Path,
+			Name:       fd.Name,
+			Net:        fd.Net,
+			LocalAddr:  fd.LocalAddr,
+			RemoteAddr: fd.RemoteAddr,
+			PollDesc:   fd.PollDesc,
+			Closing:    fd.Closing,
+			ReadG:      fd.ReadG,
+			WriteG:     fd.WriteG,
+		}
+		if fd.Socket != nil {
+			r[i].Socket = &Socket{Proto: fd.Socket.Proto, Inode: fd.Socket.Inode, LocalAddr: fd.Socket.LocalAddr, RemoteAddr: fd.Socket.RemoteAddr, State: fd.Socket.State}
+		}
+		for _, ref := range fd.Refs {
+			r[i].Refs = append(r[i].Refs, FileDescriptorRef(ref))
+		}
+	}
+	return r
+}
+
+// ConvertTimers converts a proc.TimersInfo to an api.Timers.
+func ConvertTimers(bi *proc.BinaryInfo, ti *proc.TimersInfo) *Timers {
+	r := &Timers{Now: ti.Now, Timers: make([]Timer, len(ti.Timers))}
+	for i, tmr := range ti.Timers {
+		r.Timers[i] = Timer{
+			Addr:       tmr.Addr,
+			P:          tmr.P,
+			When:       tmr.When,
+			Period:     tmr.Period,
+			Func:       ConvertFunction(tmr.Func),
+			ArgType:    tmr.ArgType,
+			Goroutines: tmr.Goroutines,
+		}
+		if fn := tmr.AfterFunc; fn != nil {
+			file, line := bi.EntryLineForFunc(fn)
+			r.Timers[i].AfterFunc = &Location{PC: fn.Entry, File: file, Line: line, Function: ConvertFunction(fn)}
+		}
+	}
+	return r
+}
+
+// ConvertMethodSet converts the method set of typ to an api.MethodSet.
+func ConvertMethodSet(bi *proc.BinaryInfo, typ godwarf.Type, methods []proc.Method) *MethodSet {
+	_, isiface := godwarf.ResolveTypedef(typ).(*godwarf.InterfaceType)
+	r := &MethodSet{Type: typ.Common().Name, Interface: isiface, Methods: make([]Method, len(methods))}
+	for i, m := range methods {
+		r.Methods[i] = Method{Name: m.Name, PtrRecv: m.PtrRecv}
+		if fn := m.Fn; fn != nil {
+			file, line := bi.EntryLineForFunc(fn)
+			r.Methods[i].Location = &Location{PC: fn.Entry, File: file, Line: line, Function: ConvertFunction(fn)}
+		}
+	}
+	return r
+}
+
+// ConvertImplementations converts a slice of proc.Implementation to a
+// slice of api.Implementation.
+func ConvertImplementations(impls []proc.Implementation) []Implementation {
+	r := make([]Implementation, len(impls))
+	for i := range impls {
+		r[i] = Implementation{Type: impls[i].Type, Itab: impls[i].Itab}
+	}
+	return r
+}
+
+// ConvertSignalInfo converts a proc.SignalInfo to an api.SignalInfo.
+func ConvertSignalInfo(sig *proc.SignalInfo) *SignalInfo {
+	if sig == nil {
+		return nil
+	}
+	return &SignalInfo{Signo: sig.Signo, Name: sig.Name(), Code: sig.Code, Addr: sig.Addr, PC: sig.PC}
+}
+
 // ConvertGoroutines converts from []*proc.G to []*api.Goroutine.
 func ConvertGoroutines(tgt *proc.Target, gs []*proc.G) []*Goroutine {
 	goroutines := make([]*Goroutine, len(gs))
@@ -320,6 +640,22 @@ func ConvertGoroutines(tgt *proc.Target, gs []*proc.G) []*Goroutine {
 	return goroutines
 }
 
+// PprofFrames converts a stacktrace into a list of pprof frames. Inlined
+// calls are recognized by having the same PC as their caller.
+func PprofFrames(stack []Stackframe) []pprof.Frame {
+	frames := make([]pprof.Frame, len(stack))
+	for i := range stack {
+		frames[i] = pprof.Frame{
+			PC:       stack[i].PC,
+			Function: stack[i].Function.Name(),
+			File:     stack[i].File,
+			Line:     stack[i].Line,
+			Inlined:  i+1 < len(stack) && stack[i+1].PC == stack[i].PC,
+		}
+	}
+	return frames
+}
+
 // ConvertLocation converts from proc.Location to api.Location.
 func ConvertLocation(loc proc.Location) Location {
 	return Location{
@@ -436,6 +772,18 @@ func ConvertImage(image *proc.Image) Image {
 	return Image{Path: image.Path, Address: image.StaticBase, LoadError: lerr}
 }
 
+// ConvertDumpOptions converts api.DumpOptions to proc.DumpOptions.
+func ConvertDumpOptions(opts DumpOptions) proc.DumpOptions {
+	r := proc.DumpOptions{MaxMappingSize: opts.MaxMappingSize}
+	if opts.ExcludeFileBacked {
+		r.Flags |= proc.DumpExcludeFileBacked
+	}
+	if opts.HeapInUse {
+		r.Flags |= proc.DumpHeapInUse
+	}
+	return r
+}
+
 // ConvertDumpState converts proc.DumpState to api.DumpState.
 func ConvertDumpState(dumpState *proc.DumpState) *DumpState {
 	dumpState.Mutex.Lock()
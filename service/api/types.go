@@ -138,6 +138,28 @@ type Breakpoint struct {
 	RootFuncName string
 	// TraceFollowCalls indicates the Depth of tracing
 	TraceFollowCalls int
+
+	// AllocType and AllocSize make this an allocation breakpoint, set on
+	// runtime.mallocgc, that only stops when an object of type AllocType
+	// (T and *T are equivalent) or whose size satisfies AllocSize (for
+	// example ">1MB") is allocated.
+	// Only one allocation breakpoint can exist at a time and it can not be
+	// created if another breakpoint is set on runtime.mallocgc.
+	AllocType string `json:"allocType,omitempty"`
+	AllocSize string `json:"allocSize,omitempty"`
+
+	// ReturnsError is a regular expression, if set the breakpoint is set
+	// on the return instructions of the matching functions that return an
+	// error and only stops when they return a non-nil error. If ErrorType
+	// is also set the error must have that dynamic type.
+	ReturnsError string `json:"returnsError,omitempty"`
+	ErrorType    string `json:"errorType,omitempty"`
+
+	// AllocAddr, if set, makes this a breakpoint on the return
+	// instructions of runtime.mallocgc that only stops when the object
+	// being allocated is at AllocAddr. It is meant to be used while
+	// executing backwards, to find where an object was allocated.
+	AllocAddr uint64 `json:"allocAddr,omitempty"`
 }
 
 // ValidBreakpointName returns an error if
@@ -388,6 +410,523 @@ type Goroutine struct {
 	Unreadable string `json:"unreadable"`
 	// Goroutine's pprof labels
 	Labels map[string]string `json:"labels,omitempty"`
+	// Panics in progress on the goroutine, oldest first
+	Panics []Panic `json:"panics,omitempty"`
+}
+
+// Panic is a panic in progress on a goroutine.
+type Panic struct {
+	Addr uint64 `json:"addr"`
+	// Value is the value passed to panic, nil for the pseudo-panics used by
+	// runtime.Goexit.
+	Value      *Variable `json:"value,omitempty"`
+	Recovered  bool      `json:"recovered"`
+	Repanicked bool      `json:"repanicked"`
+	Goexit     bool      `json:"goexit"`
+	// Nested is true if the panic was started while the deferred calls of
+	// an earlier panic were running.
+	Nested bool `json:"nested"`
+	// Aborted is true if the panic will not resume running deferred calls
+	// because a nested panic was recovered in a frame outside of it.
+	Aborted bool `json:"aborted"`
+	// PanicFrame is the index in the stacktrace of the goroutine of the
+	// frame that called panic, RecoverFrame of the deferred call that
+	// called recover, -1 if unknown.
+	PanicFrame   int       `json:"panicFrame"`
+	PanicLoc     *Location `json:"panicLoc,omitempty"`
+	RecoverFrame int       `json:"recoverFrame"`
+	RecoverLoc   *Location `json:"recoverLoc,omitempty"`
+}
+
+// WaitReasonString returns a description of the reason why the goroutine
+// is waiting.
+func (g *Goroutine) WaitReasonString() string {
+	if g.WaitReason > 0 && g.WaitReason < int64(len(waitReasonStrings)) {
+		return waitReasonStrings[g.WaitReason]
+	}
+	return fmt.Sprintf("unknown wait reason %d", g.WaitReason)
+}
+
+// StateString returns a short description of the state of the goroutine:
+// "running" if it is running on a thread, "runnable" if it could run but
+// is not currently scheduled and the wait reason otherwise.
+func (g *Goroutine) StateString() string {
+	switch {
+	case g.ThreadID != 0:
+		return "running"
+	case g.Status == GoroutineWaiting || g.Status == GoroutineSyscall:
+		if g.WaitReason != 0 {
+			return g.WaitReasonString()
+		}
+		if g.Status == GoroutineSyscall {
+			return "syscall"
+		}
+		return "waiting"
+	default:
+		return "runnable"
+	}
+}
+
+// GoroutineStateLabel is the label used in pprof profiles to record the
+// state of a goroutine, as returned by StateString.
+const GoroutineStateLabel = "goroutine.state"
+
+// PprofLabels returns the labels used to describe the goroutine in a pprof
+// profile: its pprof labels and its state.
+func (g *Goroutine) PprofLabels() map[string]string {
+	labels := make(map[string]string, len(g.Labels)+1)
+	for k, v := range g.Labels {
+		labels[k] = v
+	}
+	labels[GoroutineStateLabel] = g.StateString()
+	return labels
+}
+
+var waitReasonStrings = [...]string{
+	"",
+	"GC assist marking",
+	"IO wait",
+	"chan receive (nil chan)",
+	"chan send (nil chan)",
+	"dumping heap",
+	"garbage collection",
+	"garbage collection scan",
+	"panicwait",
+	"select",
+	"select (no cases)",
+	"GC assist wait",
+	"GC sweep wait",
+	"GC scavenge wait",
+	"chan receive",
+	"chan send",
+	"finalizer wait",
+	"force gc (idle)",
+	"semacquire",
+	"sleep",
+	"sync.Cond.Wait",
+	"timer goroutine (idle)",
+	"trace reader (blocked)",
+	"wait for GC cycle",
+	"GC worker (idle)",
+	"preempted",
+	"debug call",
+	"GC mark termination",
+	"stopping the world",
+	"flushing proc caches",
+	"trace goroutine status",
+	"trace proc status",
+	"page trace flush",
+	"coroutine",
+}
+
+// GoroutineStack is a goroutine along with its stacktrace.
+type GoroutineStack struct {
+	Goroutine
+	Stack []Stackframe `json:"stack"`
+}
+
+// ChanState describes the buffer and the wait queues of a channel.
+type ChanState struct {
+	// Addr is the address of the runtime.hchan structure, 0 for nil
+	// channels.
+	Addr   uint64 `json:"addr"`
+	Len    int64  `json:"len"`
+	Cap    int64  `json:"cap"`
+	Closed bool   `json:"closed"`
+	// Queue contains the buffered elements of the channel in the order in
+	// which they will be received.
+	Queue *Variable `json:"queue"`
+	// Recvq and Sendq are the goroutines waiting to receive from and send to
+	// the channel.
+	Recvq []ChanWaiter `json:"recvq"`
+	Sendq []ChanWaiter `json:"sendq"`
+}
+
+// ChanWaiter is a goroutine waiting on a channel.
+type ChanWaiter struct {
+	Goroutine *Goroutine `json:"goroutine"`
+	// Value is the value a sender is trying to send, nil for receivers.
+	Value *Variable `json:"value,omitempty"`
+	// Select is true if the goroutine is blocked in a select statement,
+	// SelectCases lists the other channels it is waiting on.
+	Select      bool             `json:"select"`
+	SelectCases []ChanSelectCase `json:"selectCases,omitempty"`
+}
+
+// ChanSelectCase is a case of the select statement a goroutine is blocked
+// in.
+type ChanSelectCase struct {
+	// Chan is the address of the runtime.hchan structure of the channel.
+	Chan uint64 `json:"chan"`
+	Send bool   `json:"send"`
+}
+
+// ContextLayer is a context.Context in the chain of parents of a context.
+type ContextLayer struct {
+	// Kind is one of "background", "todo", "cancel", "timer", "afterFunc",
+	// "value", "withoutCancel" and "stop", or an empty string for contexts
+	// not implemented by the context package.
+	Kind string `json:"kind"`
+	// Type is the dynamic type of the context.
+	Type string `json:"type"`
+	Addr uint64 `json:"addr"`
+	// Deadline is the deadline of timer contexts.
+	Deadline *Variable `json:"deadline,omitempty"`
+	// Canceled is true if the context has been canceled, Err and Cause are
+	// the values returned by Err and context.Cause.
+	Canceled bool      `json:"canceled"`
+	Err      *Variable `json:"err,omitempty"`
+	Cause    *Variable `json:"cause,omitempty"`
+	// AfterFunc is the function registered by context.AfterFunc.
+	AfterFunc *Variable `json:"afterFunc,omitempty"`
+	// Key and Value are the key/value pair of value contexts.
+	Key   *Variable `json:"key,omitempty"`
+	Value *Variable `json:"value,omitempty"`
+}
+
+// WaitResource is a channel or semaphore a goroutine is blocked on.
+type WaitResource struct {
+	// Kind is either "chan" or "sema".
+	Kind string `json:"kind"`
+	Addr uint64 `json:"addr"`
+}
+
+// DeadlockedGoroutine is a goroutine that can never be woken up.
+type DeadlockedGoroutine struct {
+	Goroutine *Goroutine `json:"goroutine"`
+	// WaitReason is the wait reason of the goroutine, as reported by the
+	// runtime of the target.
+	WaitReason string         `json:"waitReason"`
+	Resources  []WaitResource `json:"resources"`
+	// WaitsFor lists the goroutines that reference one of the resources
+	// this goroutine is waiting on.
+	WaitsFor []int64 `json:"waitsFor"`
+}
+
+// Deadlocks describes the goroutines of the target that are blocked
+// forever.
+type Deadlocks struct {
+	Goroutines []DeadlockedGoroutine `json:"goroutines"`
+	// Cycles lists groups of goroutines waiting on each other.
+	Cycles [][]int64 `json:"cycles"`
+}
+
+// SchedInfo is the state of the Go scheduler of the target.
+type SchedInfo struct {
+	GOMAXPROCS int `json:"gomaxprocs"`
+	// GCPhase is one of "off", "mark" or "mark termination".
+	GCPhase        string   `json:"gcPhase"`
+	GCWaiting      bool     `json:"gcWaiting"`
+	GlobalRunQSize int      `json:"globalRunQSize"`
+	IdlePs         int      `json:"idlePs"`
+	SpinningMs     int      `json:"spinningMs"`
+	Ps             []SchedP `json:"ps"`
+	Ms             []SchedM `json:"ms"`
+}
+
+// SchedP is a P of the Go scheduler.
+type SchedP struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	// M is the ID of the M the P is attached to, or -1.
+	M int64 `json:"m"`
+	// CurG is the ID of the goroutine running on the P, or 0.
+	CurG int64 `json:"curg"`
+	// RunQ contains the IDs of the goroutines in the local run queue,
+	// starting with the goroutine that will run next.
+	RunQ        []int64 `json:"runq"`
+	SchedTick   uint64  `json:"schedtick"`
+	SyscallTick uint64  `json:"syscalltick"`
+}
+
+// SchedM is an M (OS thread) of the Go scheduler.
+type SchedM struct {
+	ID       int64 `json:"id"`
+	ThreadID int   `json:"threadID"`
+	// P is the ID of the P attached to the M, or -1.
+	P        int   `json:"p"`
+	CurG     int64 `json:"curg"`
+	LockedG  int64 `json:"lockedg"`
+	Spinning bool  `json:"spinning"`
+	Blocked  bool  `json:"blocked"`
+}
+
+// FileDescriptor is a file descriptor of the target process.
+type FileDescriptor struct {
+	FD int `json:"fd"`
+	// Path is the file the descriptor refers to, as reported by the
+	// operating system, only available for live processes.
+	Path   string  `json:"path,omitempty"`
+	Socket *Socket `json:"socket,omitempty"`
+	// Name is the name of the os.File that owns the descriptor.
+	Name string `json:"name,omitempty"`
+	// Net, LocalAddr and RemoteAddr are read from the net.netFD that owns
+	// the descriptor.
+	Net        string `json:"net,omitempty"`
+	LocalAddr  string `json:"localAddr,omitempty"`
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	// PollDesc is the address of the runtime.pollDesc registered with the
+	// netpoller for the descriptor, or 0.
+	PollDesc uint64 `json:"pollDesc,omitempty"`
+	Closing  bool   `json:"closing,omitempty"`
+	// ReadG and WriteG are the goroutines parked in the netpoller waiting
+	// for the descriptor to become readable or writable, or 0.
+	ReadG  int64 `json:"readG,omitempty"`
+	WriteG int64 `json:"writeG,omitempty"`
+	// Refs lists the variables that hold the descriptor.
+	Refs []FileDescriptorRef `json:"refs,omitempty"`
+}
+
+// Socket is a socket as described by the operating system.
+type Socket struct {
+	Proto      string `json:"proto"`
+	Inode      uint64 `json:"inode"`
+	LocalAddr  string `json:"localAddr"`
+	RemoteAddr string `json:"remoteAddr"`
+	State      string `json:"state,omitempty"`
+}
+
+// FileDescriptorRef is a variable that holds a file descriptor.
+type FileDescriptorRef struct {
+	// GoroutineID is the goroutine whose stack contains the variable, 0
+	// for package variables.
+	GoroutineID int64  `json:"goroutineID"`
+	Frame       int    `json:"frame"`
+	Function    string `json:"function,omitempty"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+}
+
+// Timers lists the pending timers of the Go runtime.
+type Timers struct {
+	// Now is an estimate of the current value of the monotonic clock used
+	// by the runtime for timers, or 0 if it is not known. It is never
+	// greater than the real value.
+	Now    int64   `json:"now"`
+	Timers []Timer `json:"timers"`
+}
+
+// Timer is a pending timer of the Go runtime.
+type Timer struct {
+	// Addr is the address of the runtime.timer struct.
+	Addr uint64 `json:"addr"`
+	// P is the ID of the P whose timer heap contains the timer.
+	P int `json:"p"`
+	// When is the value of the monotonic clock of the runtime at which the
+	// timer fires.
+	When int64 `json:"when"`
+	// Period is the period of tickers in nanoseconds, or 0.
+	Period int64 `json:"period"`
+	// Func is the function called by the runtime when the timer fires.
+	Func    *Function `json:"func,omitempty"`
+	ArgType string    `json:"argType"`
+	// AfterFunc is the location of the function passed to time.AfterFunc,
+	// if the timer was created by it.
+	AfterFunc *Location `json:"afterFunc,omitempty"`
+	// Goroutines lists the goroutines waiting for the timer to fire.
+	Goroutines []int64 `json:"goroutines,omitempty"`
+}
+
+// MethodSet is the method set of a type.
+type MethodSet struct {
+	Type      string   `json:"type"`
+	Interface bool     `json:"interface"`
+	Methods   []Method `json:"methods"`
+}
+
+// Method is a method of a type.
+type Method struct {
+	Name string `json:"name"`
+	// PtrRecv is true if the method has a pointer receiver, and is
+	// therefore only in the method set of *T.
+	PtrRecv bool `json:"ptrRecv"`
+	// Location is the entry point of the function implementing the method,
+	// it is nil for the methods of interface types and for methods whose
+	// code was removed by the linker.
+	Location *Location `json:"location,omitempty"`
+}
+
+// Implementation is a type implementing an interface.
+type Implementation struct {
+	Type string `json:"type"`
+	// Itab is true if the runtime has an itab for the type and the
+	// interface, which means that a value of the type was converted to the
+	// interface. Otherwise the type is only a candidate: it has methods
+	// with the names of the methods of the interface but their signatures
+	// were not checked.
+	Itab bool `json:"itab"`
+}
+
+// SignalInfo describes a signal received by the target process.
+type SignalInfo struct {
+	Signo int    `json:"signo"`
+	Name  string `json:"name"`
+	Code  int    `json:"code"`
+	// Addr is the faulting address, for SIGSEGV, SIGBUS, SIGILL and
+	// SIGFPE.
+	Addr uint64 `json:"addr,omitempty"`
+	PC   uint64 `json:"pc,omitempty"`
+}
+
+// CrashReport summarizes the state of a crashed process, it is the output
+// of 'dlv core --report=json'.
+type CrashReport struct {
+	// Kind is one of "panic", "fatal error", "signal" or "unknown".
+	Kind string `json:"kind"`
+	// Message is the panic value or the fatal error message.
+	Message string `json:"message,omitempty"`
+	// Panics are the values of all panics of the crashing goroutine, in
+	// the order they were raised, the last one is the value in Message.
+	Panics []string `json:"panics,omitempty"`
+	// Signal is the signal that terminated the process.
+	Signal *SignalInfo `json:"signal,omitempty"`
+	// GoSignal is the signal that caused the crash, for example the
+	// SIGSEGV that caused a nil pointer dereference panic. It can be
+	// different from Signal because the Go runtime terminates crashing
+	// programs with SIGABRT when GOTRACEBACK=crash.
+	GoSignal   *SignalInfo  `json:"goSignal,omitempty"`
+	Goroutine  *Goroutine   `json:"goroutine,omitempty"`
+	Stacktrace []Stackframe `json:"stacktrace,omitempty"`
+	// GoroutineGroups are all goroutines grouped by user location, sorted
+	// by decreasing size.
+	GoroutineGroups []CrashReportGroup `json:"goroutineGroups"`
+	GoVersion       string             `json:"goVersion"`
+	BuildInfo       []PackageBuildInfo `json:"buildInfo"`
+}
+
+// CrashReportGroup is a group of goroutines stopped at the same user
+// location.
+type CrashReportGroup struct {
+	Location string `json:"location"`
+	Count    int    `json:"count"`
+	// Goroutines contains the IDs of the first goroutines of the group.
+	Goroutines []int64 `json:"goroutines"`
+}
+
+// HeapTypeStats is the number of allocated heap objects of a type and the
+// number of bytes they occupy.
+type HeapTypeStats struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// HeapRefNode is a step in a chain of references leading to a memory
+// location.
+type HeapRefNode struct {
+	// Name is the name of the variable for package variables and local
+	// variables, it is empty for heap objects and other values.
+	Name string `json:"name,omitempty"`
+	// GoroutineID and Frame identify the stack frame of local variables.
+	GoroutineID int64  `json:"goroutineID,omitempty"`
+	Frame       int    `json:"frame,omitempty"`
+	Addr        uint64 `json:"addr"`
+	Type        string `json:"type"`
+	// Path is the path, relative to the variable or object, of the pointer
+	// to the next step of the chain.
+	Path string `json:"path"`
+}
+
+// HeapReferences describes the variables and heap objects that reference
+// a memory location.
+type HeapReferences struct {
+	Addr uint64 `json:"addr"`
+	Size uint64 `json:"size"`
+	// Type is the type of the heap object containing Addr, empty if Addr
+	// isn't in the heap.
+	Type string `json:"type"`
+	// Chains lists a chain of references for each direct referrer, starting
+	// from a variable whenever possible.
+	Chains    [][]HeapRefNode `json:"chains"`
+	Truncated bool            `json:"truncated"`
+}
+
+// RetainedSize is the memory used by a value and by the heap objects
+// reachable from it.
+type RetainedSize struct {
+	// Shallow is the size of the value itself.
+	Shallow int64 `json:"shallow"`
+	// Retained is Shallow plus the size of the heap objects reachable from
+	// the value, each object is counted once.
+	Retained int64           `json:"retained"`
+	Objects  int64           `json:"objects"`
+	Types    []HeapTypeStats `json:"types"`
+	// Truncated is true if the depth or the object budget were exhausted.
+	Truncated bool `json:"truncated"`
+}
+
+// MemoryMapEntry is a memory mapping of the target process.
+type MemoryMapEntry struct {
+	Addr  uint64 `json:"addr"`
+	Size  uint64 `json:"size"`
+	Read  bool   `json:"read"`
+	Write bool   `json:"write"`
+	Exec  bool   `json:"exec"`
+	// Filename is the file mapped at Addr, empty for anonymous mappings.
+	Filename string `json:"filename,omitempty"`
+	Offset   uint64 `json:"offset"`
+}
+
+// AddrInfo describes what an address points to.
+type AddrInfo struct {
+	Addr uint64 `json:"addr"`
+	// Mapping is the memory mapping containing Addr, nil if it is unknown.
+	Mapping *MemoryMapEntry `json:"mapping,omitempty"`
+	// Image is the executable or shared library containing Addr, for text
+	// and data addresses.
+	Image string `json:"image,omitempty"`
+
+	// Function is the function containing Addr.
+	Function       *Function `json:"function,omitempty"`
+	FunctionOffset uint64    `json:"functionOffset"`
+	File           string    `json:"file,omitempty"`
+	Line           int       `json:"line"`
+
+	// Variable is the package variable containing Addr.
+	Variable       string `json:"variable,omitempty"`
+	VariableOffset uint64 `json:"variableOffset"`
+
+	// HeapSpanBase and HeapSpanLimit are the bounds of the heap span
+	// containing Addr, HeapObject is the allocated object containing Addr.
+	HeapSpanBase   uint64 `json:"heapSpanBase"`
+	HeapSpanLimit  uint64 `json:"heapSpanLimit"`
+	HeapElemSize   uint64 `json:"heapElemSize"`
+	HeapObject     uint64 `json:"heapObject"`
+	HeapObjectSize uint64 `json:"heapObjectSize"`
+	HeapType       string `json:"heapType,omitempty"`
+
+	// Goroutine is the goroutine whose stack contains Addr, Frame is the
+	// index of the frame containing Addr or -1.
+	Goroutine     *Goroutine `json:"goroutine,omitempty"`
+	Frame         int        `json:"frame"`
+	FrameFunction *Function  `json:"frameFunction,omitempty"`
+}
+
+// MemoryMapping is a memory mapping of the target process, classified by
+// its contents.
+type MemoryMapping struct {
+	MemoryMapEntry
+	// Kind is one of "heap", "stack", "text", "data", "shared library" or
+	// empty for other mappings.
+	Kind string `json:"kind,omitempty"`
+	// GoroutineID is the goroutine owning the stack, for "stack" mappings.
+	GoroutineID int64 `json:"goroutineID,omitempty"`
+}
+
+// MemoryMatch is an occurrence of a pattern found in the memory of the
+// target process.
+type MemoryMatch struct {
+	Addr uint64 `json:"addr"`
+	// Mapping is the memory mapping containing Addr, nil if the memory map
+	// is not available.
+	Mapping *MemoryMapEntry `json:"mapping,omitempty"`
+	// Symbol is the function or package variable containing Addr.
+	Symbol       string `json:"symbol,omitempty"`
+	SymbolOffset uint64 `json:"symbolOffset"`
+	// HeapObject is the address of the heap object containing Addr, 0 if
+	// Addr isn't inside a heap object.
+	HeapObject uint64 `json:"heapObject"`
+	HeapType   string `json:"heapType,omitempty"`
 }
 
 const (
@@ -438,6 +977,30 @@ type BreakpointInfo struct {
 	Variables  []Variable   `json:"variables,omitempty"`
 	Arguments  []Variable   `json:"arguments,omitempty"`
 	Locals     []Variable   `json:"locals,omitempty"`
+	// DataRace is the data race report that triggered the data race
+	// breakpoint.
+	DataRace *DataRace `json:"dataRace,omitempty"`
+}
+
+// DataRace is a data race reported by the race detector.
+type DataRace struct {
+	// Accesses are the conflicting memory accesses, the access that
+	// triggered the report first.
+	Accesses []DataRaceAccess `json:"accesses"`
+}
+
+// DataRaceAccess is one of the memory accesses of a data race.
+type DataRaceAccess struct {
+	Addr   uint64 `json:"addr"`
+	Size   int    `json:"size"`
+	Write  bool   `json:"write"`
+	Atomic bool   `json:"atomic"`
+	// RaceGoroutine is the goroutine number printed by the race detector.
+	RaceGoroutine int `json:"raceGoroutine"`
+	// GoroutineID is the ID of the goroutine that made the access, 0 if
+	// unknown.
+	GoroutineID int64      `json:"goroutineID"`
+	Stack       []Location `json:"stack"`
 }
 
 // EvalScope is the scope a command should
@@ -636,6 +1199,18 @@ type DumpState struct {
 	Err string
 }
 
+// DumpOptions selects the memory written to a core dump.
+type DumpOptions struct {
+	// ExcludeFileBacked excludes read-only mappings backed by a file.
+	ExcludeFileBacked bool `json:"excludeFileBacked,omitempty"`
+	// HeapInUse only keeps the Go heap spans that are in use and the
+	// goroutine stacks of the Go heap arenas, skipping free spans.
+	HeapInUse bool `json:"heapInUse,omitempty"`
+	// MaxMappingSize, if not 0, is the maximum number of bytes written for
+	// each mapping.
+	MaxMappingSize uint64 `json:"maxMappingSize,omitempty"`
+}
+
 // ListGoroutinesFilter describes a filtering condition for the
 // ListGoroutines API call.
 type ListGoroutinesFilter struct {
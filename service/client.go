@@ -98,6 +98,10 @@ type Client interface {
 	ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// EvalVariable returns a variable in the context of the current thread.
 	EvalVariable(scope api.EvalScope, symbol string, cfg api.LoadConfig) (*api.Variable, error)
+	// ChanState returns the buffer contents and the wait queues of a channel.
+	ChanState(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.ChanState, error)
+	// ContextChain returns the chain of parents of a context.Context.
+	ContextChain(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]api.ContextLayer, error)
 
 	// SetVariable sets the value of a variable
 	SetVariable(scope api.EvalScope, symbol, value string) error
@@ -127,6 +131,46 @@ type Client interface {
 	// Stacktrace returns stacktrace
 	Stacktrace(goroutineID int64, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
 
+	// Panics returns the panics in progress on a goroutine, oldest first.
+	Panics(goroutineID int64) ([]api.Panic, error)
+
+	// ListGoroutineStacks returns all goroutines along with their stacktraces.
+	ListGoroutineStacks(depth int, opts api.StacktraceOptions) ([]api.GoroutineStack, error)
+
+	// GoroutineProfile returns a pprof goroutine profile (gzip compressed)
+	// of all goroutines.
+	GoroutineProfile(depth int) ([]byte, error)
+
+	// SchedulerInfo returns the state of the Go scheduler.
+	SchedulerInfo() (*api.SchedInfo, error)
+
+	// FileDescriptors returns the file descriptors of the target.
+	FileDescriptors() ([]api.FileDescriptor, error)
+
+	// Timers returns the pending timers of the Go runtime.
+	Timers() (*api.Timers, error)
+
+	// MethodSet returns the method set of a type.
+	MethodSet(typename string) (*api.MethodSet, error)
+
+	// Implements returns the types implementing an interface.
+	Implements(typename string) ([]api.Implementation, error)
+
+	// Deadlocks returns the goroutines that are blocked forever.
+	Deadlocks() (*api.Deadlocks, error)
+
+	// HeapHistogram returns the number of heap objects and bytes for each
+	// type matching filter.
+	HeapHistogram(filter string) ([]api.HeapTypeStats, error)
+
+	// HeapReferences returns the chains of references leading to the memory
+	// expr refers to.
+	HeapReferences(scope api.EvalScope, expr string, maxChains int) (*api.HeapReferences, error)
+
+	// RetainedSize returns the size of the value expr evaluates to and of
+	// the heap objects reachable from it.
+	RetainedSize(scope api.EvalScope, expr string, maxDepth, maxObjects int) (*api.RetainedSize, error)
+
 	// Ancestors returns ancestor stacktraces
 	Ancestors(goroutineID int64, numAncestors int, depth int) ([]api.Ancestor, error)
 
@@ -180,11 +224,31 @@ type Client interface {
 	// This function will return an error if it reads less than `length` bytes.
 	ExamineMemory(address uint64, length int) ([]byte, bool, error)
 
+	// ValueMemory returns the address and size of the memory storing the
+	// value of expr.
+	ValueMemory(scope api.EvalScope, expr string) (uint64, uint64, error)
+
+	// WriteMemory writes data to the memory of the target at address.
+	WriteMemory(address uint64, data []byte) error
+
+	// DescribeAddress returns a description of what addr points to.
+	DescribeAddress(addr uint64) (*api.AddrInfo, error)
+
+	// MemoryMap returns the memory mappings of the target process.
+	MemoryMap() ([]api.MemoryMapping, error)
+
+	// FindMemory searches memory between start and end for pattern, if
+	// start and end are both 0 all readable mappings are searched.
+	FindMemory(start, end uint64, pattern []byte, maxMatches int) ([]api.MemoryMatch, bool, error)
+
 	// StopRecording stops a recording if one is in progress.
 	StopRecording() error
 
 	// CoreDumpStart starts creating a core dump to the specified file
 	CoreDumpStart(dest string) (api.DumpState, error)
+	// CoreDumpStartWithOptions starts creating a core dump to the specified
+	// file, writing only the memory selected by opts
+	CoreDumpStartWithOptions(dest string, opts api.DumpOptions) (api.DumpState, error)
 	// CoreDumpWait waits for the core dump to finish, or for the specified amount of milliseconds
 	CoreDumpWait(msec int) api.DumpState
 	// CoreDumpCancel cancels a core dump in progress
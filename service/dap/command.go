@@ -134,7 +134,7 @@ func (s *Session) evaluateConfig(_, _ int, expr string) (string, error) {
 	if updated {
 		// Send invalidated events for areas that are affected by configuration changes.
 		switch name {
-		case "showGlobalVariables", "showRegisters":
+		case "showGlobalVariables", "showRegisters", "showRetainedSize":
 			// Variable data has become invalidated.
 			s.send(&dap.InvalidatedEvent{
 				Event: *newEvent("invalidated"),
@@ -27,6 +27,9 @@ type fullyQualifiedVariable struct {
 	// startIndex is the index of the first child for an array or slice.
 	// This variable represents a chunk of the array, slice or map.
 	startIndex int
+	// True if the children of this variable can not be evaluated, for
+	// example the elements of a channel buffer.
+	noChildEvaluateNames bool
 }
 
 func newHandlesMap[T any]() *handlesMap[T] {
@@ -227,6 +227,9 @@ type launchAttachArgs struct {
 	ShowGlobalVariables bool `cfgName:"showGlobalVariables"`
 	// ShowRegisters indicates if register values should be loaded.
 	ShowRegisters bool `cfgName:"showRegisters"`
+	// ShowRetainedSize indicates if the retained size of variables should be
+	// shown after their type.
+	ShowRetainedSize bool `cfgName:"showRetainedSize"`
 	// GoroutineFilters are the filters used when loading goroutines.
 	GoroutineFilters string `cfgName:"goroutineFilters"`
 	// ShowPprofLabels is an array of keys of pprof labels to show as a
@@ -252,6 +255,7 @@ var defaultArgs = launchAttachArgs{
 	ShowGlobalVariables:          false,
 	HideSystemGoroutines:         false,
 	ShowRegisters:                false,
+	ShowRetainedSize:             false,
 	GoroutineFilters:             "",
 	ShowPprofLabels:              []string{},
 	substitutePathClientToServer: [][2]string{},
@@ -364,6 +368,7 @@ func (s *Session) setLaunchAttachArgs(args LaunchAttachCommonConfig) {
 	}
 	s.args.ShowGlobalVariables = args.ShowGlobalVariables
 	s.args.ShowRegisters = args.ShowRegisters
+	s.args.ShowRetainedSize = args.ShowRetainedSize
 	s.args.HideSystemGoroutines = args.HideSystemGoroutines
 	s.args.GoroutineFilters = args.GoroutineFilters
 	s.args.ShowPprofLabels = args.ShowPprofLabels
@@ -882,6 +887,7 @@ func (s *Session) onInitializeRequest(request *dap.InitializeRequest) {
 	response.Body.ExceptionBreakpointFilters = []dap.ExceptionBreakpointsFilter{
 		{Filter: proc.UnrecoveredPanic, Label: "Unrecovered Panics", Default: true},
 		{Filter: proc.FatalThrow, Label: "Fatal Throws", Default: true},
+		{Filter: proc.DataRaceBreakpoint, Label: "Data Races", Default: true},
 	}
 	s.send(response)
 }
@@ -2318,7 +2324,7 @@ func (s *Session) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListLocals, "Unable to list locals", err.Error())
 		return
 	}
-	locScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Locals%s", suffix), Children: slicePtrVarToSliceVar(append(args, locals...))}, "", true, 0}
+	locScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Locals%s", suffix), Children: slicePtrVarToSliceVar(append(args, locals...))}, "", true, 0, false}
 	scopeLocals := dap.Scope{Name: locScope.Name, VariablesReference: s.variableHandles.create(locScope)}
 	scopes := []dap.Scope{scopeLocals}
 
@@ -2352,7 +2358,7 @@ func (s *Session) onScopesRequest(request *dap.ScopesRequest) {
 		globScope := &fullyQualifiedVariable{&proc.Variable{
 			Name:     fmt.Sprintf("Globals (package %s)", currPkg),
 			Children: slicePtrVarToSliceVar(globals),
-		}, currPkg, true, 0}
+		}, currPkg, true, 0, false}
 		scopeGlobals := dap.Scope{Name: globScope.Name, VariablesReference: s.variableHandles.create(globScope)}
 		scopes = append(scopes, scopeGlobals)
 	}
@@ -2373,7 +2379,7 @@ func (s *Session) onScopesRequest(request *dap.ScopesRequest) {
 				Kind:  reflect.Kind(proc.VariableConstant),
 			}
 		}
-		regsScope := &fullyQualifiedVariable{&proc.Variable{Name: "Registers", Children: regsVar}, "", true, 0}
+		regsScope := &fullyQualifiedVariable{&proc.Variable{Name: "Registers", Children: regsVar}, "", true, 0, false}
 		scopeRegisters := dap.Scope{Name: regsScope.Name, VariablesReference: s.variableHandles.create(regsScope)}
 		scopes = append(scopes, scopeRegisters)
 	}
@@ -2453,7 +2459,7 @@ func (s *Session) maybeLoadResliced(v *fullyQualifiedVariable, start, count int)
 	if err != nil {
 		return nil, err
 	}
-	return &fullyQualifiedVariable{newV, v.fullyQualifiedNameOrExpr, false, start}, nil
+	return &fullyQualifiedVariable{newV, v.fullyQualifiedNameOrExpr, false, start, v.noChildEvaluateNames}, nil
 }
 
 // getIndexedVariableCount returns the number of indexed variables
@@ -2556,7 +2562,10 @@ func (s *Session) childrenToDAPVariables(v *fullyQualifiedVariable) []dap.Variab
 		children = make([]dap.Variable, len(v.Children))
 		for i := range v.Children {
 			idx := v.startIndex + i
-			cfqname := fmt.Sprintf("%s[%d]", v.fullyQualifiedNameOrExpr, idx)
+			cfqname := ""
+			if !v.noChildEvaluateNames {
+				cfqname = fmt.Sprintf("%s[%d]", v.fullyQualifiedNameOrExpr, idx)
+			}
 			cvalue, cvarref := s.convertVariable(&v.Children[i], cfqname)
 			children[i] = dap.Variable{
 				Name:               fmt.Sprintf("[%d]", idx),
@@ -2634,6 +2643,14 @@ func getNamedVariableCount(v *proc.Variable) int {
 		// string value of array/slice of bytes and runes.
 		namedVars += 1
 	}
+	if v.Kind == reflect.Chan && v.Base != 0 {
+		// closed, queue, recvq and sendq
+		namedVars += 4
+	}
+	if isContext(v) {
+		// context chain
+		namedVars += 1
+	}
 
 	return namedVars
 }
@@ -2675,9 +2692,128 @@ func (s *Session) metadataToDAPVariables(v *fullyQualifiedVariable) ([]dap.Varia
 			s.config.log.Debugf("failed to load %q: %v", v.fullyQualifiedNameOrExpr, err)
 		}
 	}
+
+	if v.Kind == reflect.Chan && v.Base != 0 {
+		loadExpr := fmt.Sprintf("*(*%q)(%#x)", api.PrettyTypeName(v.DwarfType), v.Addr)
+		cs, err := s.debugger.ChanState(-1, 0, 0, loadExpr, DefaultLoadConfig)
+		if err == nil {
+			// The queue is not stored contiguously in memory, it can't be
+			// resliced and its elements do not have an evaluate name.
+			queue := s.convertVariableToString(cs.Queue)
+			queueref := 0
+			if len(cs.Queue.Children) > 0 {
+				queueref = s.variableHandles.create(&fullyQualifiedVariable{cs.Queue, "", false /*not a scope*/, 0, true})
+			}
+			children = append(children, dap.Variable{
+				Name:  "closed",
+				Value: strconv.FormatBool(cs.Closed),
+				Type:  "bool",
+			}, dap.Variable{
+				Name:               "queue",
+				Value:              queue,
+				Type:               s.getTypeIfSupported(cs.Queue),
+				VariablesReference: queueref,
+			}, dap.Variable{
+				Name:  "recvq",
+				Value: chanWaitersToString(cs.Recvq),
+			}, dap.Variable{
+				Name:  "sendq",
+				Value: chanWaitersToString(cs.Sendq),
+			})
+		} else {
+			s.config.log.Debugf("failed to load channel state of %q: %v", v.fullyQualifiedNameOrExpr, err)
+		}
+	}
+
+	if isContext(v.Variable) {
+		loadExpr := fmt.Sprintf("*(*%q)(%#x)", api.PrettyTypeName(v.DwarfType), v.Addr)
+		layers, err := s.debugger.ContextChain(-1, 0, 0, loadExpr, DefaultLoadConfig)
+		if err == nil {
+			// The chain is flattened into a single list of variables, named
+			// after the layer they belong to, e.g. "[2] value.key".
+			chain := &proc.Variable{Name: "context chain"}
+			for i, layer := range layers {
+				for _, f := range []struct {
+					name string
+					v    *proc.Variable
+				}{{"deadline", layer.Deadline}, {"err", layer.Err}, {"cause", layer.Cause}, {"afterFunc", layer.AfterFunc}, {"key", layer.Key}, {"value", layer.Value}} {
+					if f.v == nil {
+						continue
+					}
+					c := *f.v
+					c.Name = fmt.Sprintf("[%d] %s.%s", i, contextLayerKind(layer), f.name)
+					chain.Children = append(chain.Children, c)
+				}
+			}
+			chainref := 0
+			if len(chain.Children) > 0 {
+				chainref = s.variableHandles.create(&fullyQualifiedVariable{chain, "", false /*not a scope*/, 0, false})
+			}
+			children = append(children, dap.Variable{
+				Name:               "context chain",
+				Value:              contextChainToString(layers),
+				VariablesReference: chainref,
+				NamedVariables:     len(chain.Children),
+			})
+		} else {
+			s.config.log.Debugf("failed to load context chain of %q: %v", v.fullyQualifiedNameOrExpr, err)
+		}
+	}
 	return children, nil
 }
 
+// chanWaitersToString returns a short description of the goroutines
+// waiting on a channel, for senders the value being sent is also shown.
+func chanWaitersToString(ws []proc.ChanWaiter) string {
+	var buf strings.Builder
+	buf.WriteString("[")
+	for i, w := range ws {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "goroutine %d", w.G.ID)
+		if w.Value != nil {
+			fmt.Fprintf(&buf, " sending %s", api.ConvertVar(w.Value).SinglelineString())
+		}
+		if w.Select {
+			buf.WriteString(" (select)")
+		}
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// isContext returns true if v is a non-nil context.Context.
+func isContext(v *proc.Variable) bool {
+	return v.Kind == reflect.Interface && v.Addr != 0 && v.DwarfType != nil && v.DwarfType.String() == "context.Context" &&
+		len(v.Children) > 0 && v.Children[0].Kind != reflect.Invalid && v.Children[0].Addr != 0
+}
+
+// contextLayerKind returns the kind of a context, or its type for contexts
+// not implemented by the context package.
+func contextLayerKind(layer proc.ContextLayer) string {
+	if layer.Kind == "" {
+		return layer.Type
+	}
+	return layer.Kind
+}
+
+// contextChainToString returns a short description of a chain of contexts,
+// for example "value → timer (canceled) → cancel (canceled) → background".
+func contextChainToString(layers []proc.ContextLayer) string {
+	var buf strings.Builder
+	for i, layer := range layers {
+		if i > 0 {
+			buf.WriteString(" → ")
+		}
+		buf.WriteString(contextLayerKind(layer))
+		if layer.Canceled {
+			buf.WriteString(" (canceled)")
+		}
+	}
+	return buf.String()
+}
+
 func isListOfBytesOrRunes(v *proc.Variable) bool {
 	if len(v.Children) > 0 && (v.Kind == reflect.Array || v.Kind == reflect.Slice) {
 		childKind := v.Children[0].RealType.Common().ReflectKind
@@ -2690,16 +2826,49 @@ func (s *Session) getTypeIfSupported(v *proc.Variable) string {
 	if !s.clientCapabilities.supportsVariableType {
 		return ""
 	}
+	typ := v.TypeString()
 	switch v.Kind {
 	case reflect.Interface:
 		if len(v.Children) > 0 {
 			vapi := api.ConvertVar(v)
 			if vapi.Children[0].Kind != reflect.Invalid {
-				return fmt.Sprintf("%s(%s)", vapi.Type, vapi.Children[0].Type)
+				typ = fmt.Sprintf("%s(%s)", vapi.Type, vapi.Children[0].Type)
 			}
 		}
 	}
-	return v.TypeString()
+	if s.args.ShowRetainedSize {
+		typ += s.retainedSize(v)
+	}
+	return typ
+}
+
+const (
+	// Limits used to compute the retained size of variables, see
+	// showRetainedSize.
+	retainedSizeMaxDepth   = 16
+	retainedSizeMaxObjects = 10000
+)
+
+// retainedSize returns a description of the retained size of v, to be
+// appended to its type, or an empty string if v does not reference any
+// heap object.
+func (s *Session) retainedSize(v *proc.Variable) string {
+	switch v.Kind {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.String, reflect.Interface, reflect.Struct, reflect.Array, reflect.Chan:
+	default:
+		return ""
+	}
+	if v.Unreadable != nil {
+		return ""
+	}
+	r, err := s.debugger.VariableRetainedSize(v, retainedSizeMaxDepth, retainedSizeMaxObjects)
+	if err != nil || r.Objects == 0 {
+		return ""
+	}
+	if r.Truncated {
+		return fmt.Sprintf(" (retained at least %d bytes in %d objects)", r.Retained, r.Objects)
+	}
+	return fmt.Sprintf(" (retained %d bytes in %d objects)", r.Retained, r.Objects)
 }
 
 // convertVariable converts proc.Variable to dap.Variable value and reference
@@ -2746,7 +2915,7 @@ func (s *Session) convertVariableWithOpts(v *proc.Variable, qualifiedNameOrExpr
 		if opts&skipRef != 0 {
 			return 0
 		}
-		return s.variableHandles.create(&fullyQualifiedVariable{v, qualifiedNameOrExpr, false /*not a scope*/, 0})
+		return s.variableHandles.create(&fullyQualifiedVariable{v, qualifiedNameOrExpr, false /*not a scope*/, 0, false})
 	}
 	value = api.ConvertVar(v).SinglelineStringWithShortTypes()
 	if v.Unreadable != nil {
@@ -2950,7 +3119,7 @@ func (s *Session) onEvaluateRequest(request *dap.EvaluateRequest) {
 			}
 			response.Body = dap.EvaluateResponseBody{
 				Result:             strings.TrimRight(retVarsAsStr, ", "),
-				VariablesReference: s.variableHandles.create(&fullyQualifiedVariable{retVarsAsVar, "", false /*not a scope*/, 0}),
+				VariablesReference: s.variableHandles.create(&fullyQualifiedVariable{retVarsAsVar, "", false /*not a scope*/, 0, false}),
 			}
 		}
 	} else { // {expression}
@@ -3575,7 +3744,7 @@ func (s *Session) onExceptionInfoRequest(request *dap.ExceptionInfoRequest) {
 	}
 	// Check if this goroutine ID is stopped at a breakpoint.
 	includeStackTrace := true
-	if bpState != nil && bpState.Breakpoint != nil && bpState.Breakpoint.Logical != nil && (bpState.Breakpoint.Logical.Name == proc.FatalThrow || bpState.Breakpoint.Logical.Name == proc.UnrecoveredPanic) {
+	if bpState != nil && bpState.Breakpoint != nil && bpState.Breakpoint.Logical != nil && (bpState.Breakpoint.Logical.Name == proc.FatalThrow || bpState.Breakpoint.Logical.Name == proc.UnrecoveredPanic || bpState.Breakpoint.Logical.Name == proc.DataRaceBreakpoint) {
 		switch bpState.Breakpoint.Logical.Name {
 		case proc.FatalThrow:
 			body.ExceptionId = "fatal error"
@@ -3595,6 +3764,12 @@ func (s *Session) onExceptionInfoRequest(request *dap.ExceptionInfoRequest) {
 			if err != nil {
 				body.Description = fmt.Sprintf("Error getting panic message: %s", err.Error())
 			}
+		case proc.DataRaceBreakpoint:
+			body.ExceptionId = "data race"
+			body.Description, err = s.dataRaceReason()
+			if err != nil {
+				body.Description = fmt.Sprintf("Error getting data race report: %s", err.Error())
+			}
 		}
 	} else {
 		// If this thread is not stopped on a breakpoint, then a runtime error must have occurred.
@@ -3675,6 +3850,39 @@ func (s *Session) panicReason(goroutineID int64) (string, error) {
 	return s.getExprString("(*msgs).arg.(data)", goroutineID, 0)
 }
 
+// dataRaceReason describes the accesses of the data race that stopped the
+// target, in the same format used by the race detector.
+func (s *Session) dataRaceReason() (string, error) {
+	r := api.ConvertDataRace(s.debugger.DataRace())
+	if r == nil {
+		return "", errors.New("no data race report")
+	}
+	var buf bytes.Buffer
+	for i, acc := range r.Accesses {
+		kind := "read"
+		if acc.Write {
+			kind = "write"
+		}
+		if acc.Atomic {
+			kind = "atomic " + kind
+		}
+		if i > 0 {
+			kind = "previous " + kind
+			fmt.Fprintln(&buf)
+		}
+		kind = strings.ToUpper(kind[:1]) + kind[1:]
+		g := "unknown goroutine"
+		if acc.GoroutineID != 0 {
+			g = fmt.Sprintf("goroutine %d", acc.GoroutineID)
+		}
+		fmt.Fprintf(&buf, "%s at %#x by %s (race detector goroutine %d):\n", kind, acc.Addr, g, acc.RaceGoroutine)
+		for _, loc := range acc.Stack {
+			fmt.Fprintf(&buf, "\t%s() %s:%d\n", loc.Function.Name(), s.toClientPath(loc.File), loc.Line)
+		}
+	}
+	return buf.String(), nil
+}
+
 func (s *Session) getExprString(expr string, goroutineID int64, frame int) (string, error) {
 	exprVar, err := s.debugger.EvalVariableInScope(goroutineID, frame, 0, expr, DefaultLoadConfig)
 	if err != nil {
@@ -3896,6 +4104,10 @@ func (s *Session) runUntilStopAndNotify(command string, allowNextStateChange *sy
 					stopped.Body.Reason = "exception"
 					stopped.Body.Description = "panic"
 					stopped.Body.Text, _ = s.panicReason(int64(stopped.Body.ThreadId))
+				case proc.DataRaceBreakpoint:
+					stopped.Body.Reason = "exception"
+					stopped.Body.Description = "data race"
+					stopped.Body.Text, _ = s.dataRaceReason()
 				}
 				if strings.HasPrefix(bp.Name, functionBpPrefix) {
 					stopped.Body.Reason = "function breakpoint"
@@ -1860,9 +1860,19 @@ func TestScopesAndVariablesRequests2(t *testing.T) {
 					if ref > 0 {
 						client.VariablesRequest(ref)
 						ch1 := client.ExpectVariablesResponse(t)
-						checkVarExact(t, ch1, 0, "qcount", "ch1.qcount", "4 = 0x4", "uint", noChildren)
-						validateEvaluateName(t, client, ch1, 0)
-						validateEvaluateName(t, client, ch1, 10)
+						checkVarExact(t, ch1, 0, "closed", "", "false", "bool", noChildren)
+						queueRef := checkVarExact(t, ch1, 1, "queue", "", "[4]int [1,4,3,2]", "[4]int", hasChildren)
+						checkVarExact(t, ch1, 2, "recvq", "", "[]", "", noChildren)
+						checkVarExact(t, ch1, 3, "sendq", "", "[]", "", noChildren)
+						checkVarExact(t, ch1, 4, "qcount", "ch1.qcount", "4 = 0x4", "uint", noChildren)
+						validateEvaluateName(t, client, ch1, 4)
+						validateEvaluateName(t, client, ch1, 14)
+						if queueRef > 0 {
+							client.VariablesRequest(queueRef)
+							queue := client.ExpectVariablesResponse(t)
+							checkChildren(t, queue, "queue", 4)
+							checkVarExact(t, queue, 3, "[3]", "", "2", "int", noChildren)
+						}
 					}
 					checkVarExact(t, locals, -1, "chnil", "chnil", "chan int nil", "chan int", noChildren)
 					// reflect.Kind == Func
@@ -2717,6 +2727,71 @@ func TestRegistersScopeAndVariables(t *testing.T) {
 	})
 }
 
+// TestRetainedSizeVariables launches the program with showRetainedSize set
+// and checks that the retained size of variables referencing heap objects
+// is shown after their type.
+func TestRetainedSizeVariables(t *testing.T) {
+	runTest(t, "heapprog", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequestWithArgs(map[string]any{
+					"mode": "exec", "program": fixture.Path, "showRetainedSize": true,
+				})
+			},
+			// Breakpoints are set within the program
+			fixture.Source, []int{50},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 50)
+
+					client.VariablesRequest(localsScope)
+					locals := client.ExpectVariablesResponse(t)
+					checkVarRegex(t, locals, -1, "mediums", "mediums", `\[\]\*main\.medium len: 100`, `^\[\]\*main\.medium \(retained \d+ bytes in 100 objects\)$`, hasChildren)
+					checkVarRegex(t, locals, -1, "larges", "larges", `\[3\]\*main\.large`, `^\[3\]\*main\.large \(retained \d+ bytes in 3 objects\)$`, hasChildren)
+					checkVarExact(t, locals, -1, "h", "h", `*main.holder {name: "holder", target: *main.node {next: *main.node nil, n: 0}}`, "*main.holder (retained 24 bytes in 1 objects)", hasChildren)
+				},
+				disconnect: true,
+			}})
+	})
+}
+
+func TestContextChainVariables(t *testing.T) {
+	runTest(t, "ctxchain", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Breakpoints are set within the program
+			fixture.Source, []int{},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 29)
+
+					client.VariablesRequest(localsScope)
+					locals := client.ExpectVariablesResponse(t)
+					checkVarExact(t, locals, -1, "nilctx", "nilctx", "context.Context nil", "context.Context", noChildren)
+					ref := checkVarRegex(t, locals, -1, "wctx", "wctx", `^context\.Context\(context\.withoutCancelCtx\)`, `^context\.Context\(context\.withoutCancelCtx\)$`, hasChildren)
+					if ref > 0 {
+						client.VariablesRequest(ref)
+						wctx := client.ExpectVariablesResponse(t)
+						chainRef := checkVarExact(t, wctx, 0, "context chain", "", "withoutCancel → value → value → timer (canceled) → cancel (canceled) → background", "", hasChildren)
+						if chainRef > 0 {
+							client.VariablesRequest(chainRef)
+							chain := client.ExpectVariablesResponse(t)
+							checkVarExact(t, chain, 0, "[1] value.key", "", "interface {}(int) 42", "interface {}(int)", hasChildren)
+							checkVarExact(t, chain, 3, "[2] value.value", "", `interface {}(string) "alice"`, "interface {}(string)", hasChildren)
+							checkVarRegex(t, chain, 4, `\[3\] timer\.deadline`, "", `^time\.Time\(`, "time.Time", hasChildren)
+							checkVarExact(t, chain, 8, "[4] cancel.cause", "", `error(*errors.errorString) *{s: "shutting down"}`, "error(*errors.errorString)", hasChildren)
+						}
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
 func findPcReg(regs []dap.Variable) int {
 	pcRegNames := []string{"rip", "pc", "eip", "era"}
 	for i, reg := range regs {
@@ -4133,6 +4208,7 @@ func formatConfig(depth int, showGlobals, showRegisters bool, goroutineFilters s
 	formatStr := `stackTraceDepth	%d
 showGlobalVariables	%v
 showRegisters	%v
+showRetainedSize	false
 goroutineFilters	%q
 showPprofLabels	%v
 hideSystemGoroutines	%v
@@ -7769,6 +7845,40 @@ func checkExceptionBreakpoints(t *testing.T, resp *dap.SetExceptionBreakpointsRe
 	}
 }
 
+func TestDataRaceBreakpoint(t *testing.T) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("race detector only supported on linux/amd64 and linux/arm64")
+	}
+	runTestBuildFlags(t, "datarace", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			fixture.Source, []int{17},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 17)
+
+					client.ContinueRequest(1)
+					client.ExpectContinueResponse(t)
+
+					se := client.ExpectStoppedEvent(t)
+					if se.Body.ThreadId != 1 || se.Body.Reason != "exception" || se.Body.Description != "data race" || !strings.HasPrefix(se.Body.Text, "Write at ") {
+						t.Errorf("\ngot  %#v\nwant ThreadId=1 Reason=\"exception\" Description=\"data race\" Text=\"Write at ...\"", se)
+					}
+
+					client.ExceptionInfoRequest(1)
+					eInfo := client.ExpectExceptionInfoResponse(t)
+					if eInfo.Body.ExceptionId != "data race" || !strings.Contains(eInfo.Body.Description, "Previous write at ") || !strings.Contains(eInfo.Body.Description, "main.writer()") {
+						t.Errorf("\ngot  %#v\nwant ExceptionId=\"data race\" Description containing the previous write", eInfo)
+					}
+				},
+				disconnect: true,
+			}},
+		)
+	}, protest.EnableRaceDetector, false)
+}
+
 func TestSetExceptionBreakpoints(t *testing.T) {
 	runTestBuildFlags(t, "panic", func(client *daptest.Client, fixture protest.Fixture) {
 		runDebugSessionWithBPs(t, client, "launch",
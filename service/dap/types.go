@@ -183,6 +183,11 @@ type LaunchAttachCommonConfig struct {
 	// in the variables pane or not.
 	ShowRegisters bool `json:"showRegisters,omitempty"`
 
+	// Boolean value to indicate whether the retained size of variables
+	// (the size of the heap objects reachable from them) should be shown
+	// along with their type. Computing it can be slow for large heaps.
+	ShowRetainedSize bool `json:"showRetainedSize,omitempty"`
+
 	// Boolean value to indicate whether system goroutines
 	// should be hidden from the call stack view.
 	HideSystemGoroutines bool `json:"hideSystemGoroutines,omitempty"`
@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"slices"
@@ -20,7 +21,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/elfwriter"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/locspec"
@@ -112,9 +115,20 @@ type Config struct {
 	// AttachWaitForDuration is the time (in milliseconds) that the debugger
 	// waits for WaitFor.
 	AttachWaitForDuration float64
+	// If AttachSnapshot is set the debugger attaches to a copy of the process
+	// with AttachPid, the process is resumed as soon as the copy is made and
+	// AttachSnapshot is called. Only supported by the native backend, see
+	// native.SnapshotSupported.
+	AttachSnapshot func()
 
 	// CoreFile specifies the path to the core dump to open.
 	CoreFile string
+	// Sysroot is prepended to the paths of the shared libraries used by
+	// CoreFile.
+	Sysroot string
+	// SolibSearchPath is the list of directories where the shared libraries
+	// used by CoreFile are looked up, by file name, after Sysroot.
+	SolibSearchPath []string
 
 	// Backend specifies the debugger backend.
 	Backend string
@@ -203,7 +217,7 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 			d.target, err = gdbserial.Replay(d.config.CoreFile, false, d.config.RrDelOnDetach, d.config.DebugInfoDirectories, d.config.RrOnProcessPid, "")
 		default:
 			d.log.Infof("opening core file %s (executable %s)", d.config.CoreFile, d.processArgs[0])
-			d.target, err = core.OpenCore(d.config.CoreFile, d.processArgs[0], d.config.DebugInfoDirectories)
+			d.target, err = core.OpenCoreWithLibraries(d.config.CoreFile, d.processArgs[0], d.config.DebugInfoDirectories, core.SharedLibraryPaths{Sysroot: d.config.Sysroot, SearchPath: d.config.SolibSearchPath, Warn: sharedLibraryWarning})
 		}
 		if err != nil {
 			err = go11DecodeErrorCheck(err)
@@ -367,19 +381,34 @@ func (d *Debugger) recordingRun(run func() (string, error)) (*proc.TargetGroup,
 func (d *Debugger) Attach(pid int, path string, waitFor *proc.WaitFor) (*proc.TargetGroup, error) {
 	switch d.config.Backend {
 	case "native":
-		return native.Attach(pid, waitFor, d.config.DebugInfoDirectories)
+		return d.attachNative(pid, waitFor)
 	case "lldb":
+		if d.config.AttachSnapshot != nil {
+			return nil, errSnapshotNotSupported
+		}
 		return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, waitFor, d.config.DebugInfoDirectories))
 	case "default":
 		if runtime.GOOS == "darwin" {
+			if d.config.AttachSnapshot != nil {
+				return nil, errSnapshotNotSupported
+			}
 			return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, waitFor, d.config.DebugInfoDirectories))
 		}
-		return native.Attach(pid, waitFor, d.config.DebugInfoDirectories)
+		return d.attachNative(pid, waitFor)
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
 }
 
+var errSnapshotNotSupported = errors.New("the backend can not attach to a snapshot of a process")
+
+func (d *Debugger) attachNative(pid int, waitFor *proc.WaitFor) (*proc.TargetGroup, error) {
+	if d.config.AttachSnapshot != nil {
+		return native.AttachSnapshot(pid, d.config.DebugInfoDirectories, d.config.AttachSnapshot)
+	}
+	return native.Attach(pid, waitFor, d.config.DebugInfoDirectories)
+}
+
 var errMacOSBackendUnavailable = errors.New("debugserver or lldb-server not found: install Xcode's command line tools or lldb-server")
 
 func betterGdbserialLaunchError(p *proc.TargetGroup, err error) (*proc.TargetGroup, error) {
@@ -701,6 +730,21 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint, locExpr string,
 			return nil, ErrNotImplementedWithMultitarget
 		}
 		setbp.PidAddrs = []proc.PidAddr{{Pid: d.target.Selected.Pid(), Addr: requestedBp.Addr}}
+	case requestedBp.AllocType != "" || requestedBp.AllocSize != "":
+		// All allocation breakpoints would be set on the same locations and
+		// a physical breakpoint can only belong to one logical breakpoint.
+		for _, lbp := range d.target.LogicalBreakpoints {
+			if lbp.Set.AllocType != "" || lbp.Set.AllocSize != "" {
+				return nil, fmt.Errorf("allocation breakpoint %d already exists, only one allocation breakpoint can be set at a time", lbp.LogicalID)
+			}
+		}
+		setbp.AllocType = requestedBp.AllocType
+		setbp.AllocSize = requestedBp.AllocSize
+	case requestedBp.ReturnsError != "":
+		setbp.ReturnsError = requestedBp.ReturnsError
+		setbp.ErrorType = requestedBp.ErrorType
+	case requestedBp.AllocAddr != 0:
+		setbp.AllocAddr = requestedBp.AllocAddr
 	case len(requestedBp.File) > 0:
 		fileName := requestedBp.File
 		if runtime.GOOS == "windows" {
@@ -1265,6 +1309,10 @@ func (d *Debugger) collectBreakpointInformation(apiThread *api.Thread, thread pr
 
 	tgt := d.target.TargetForThread(thread.ThreadID())
 
+	if bp.Name == proc.DataRaceBreakpoint {
+		bpi.DataRace = api.ConvertDataRace(tgt.DataRace())
+	}
+
 	// If we're dealing with a stripped binary don't attempt to load more
 	// information, we won't be able to.
 	img := tgt.BinInfo().PCToImage(bp.Addr)
@@ -1291,6 +1339,12 @@ func (d *Debugger) collectBreakpointInformation(apiThread *api.Thread, thread pr
 		}
 	}
 
+	if bp.ReturnsError != "" {
+		if v, err := proc.ReturnedError(tgt, thread, proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}); err == nil {
+			apiThread.ReturnValues = []api.Variable{*api.ConvertVar(v)}
+		}
+	}
+
 	if len(bp.Variables) == 0 && bp.LoadArgs == nil && bp.LoadLocals == nil {
 		// don't try to create goroutine scope if there is nothing to load
 		return nil
@@ -1585,6 +1639,32 @@ func (d *Debugger) EvalVariableInScope(goid int64, frame, deferredCall int, expr
 	return s.EvalExpression(expr, cfg)
 }
 
+// ChanState returns the buffer contents and wait queues of the channel expr
+// evaluates to, in the given scope.
+func (d *Debugger) ChanState(goid int64, frame, deferredCall int, expr string, cfg proc.LoadConfig) (*proc.ChanState, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target.Selected, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	return s.ChanState(expr, cfg)
+}
+
+// ContextChain returns the chain of parents of the context.Context expr
+// evaluates to, in the given scope.
+func (d *Debugger) ContextChain(goid int64, frame, deferredCall int, expr string, cfg proc.LoadConfig) ([]proc.ContextLayer, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target.Selected, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	return s.ContextChain(expr, cfg)
+}
+
 // LoadResliced will attempt to 'reslice' a map, array or slice so that the values
 // up to cfg.MaxArrayValues children are loaded starting from index start.
 func (d *Debugger) LoadResliced(v *proc.Variable, start int, cfg proc.LoadConfig) (*proc.Variable, error) {
@@ -1699,7 +1779,10 @@ func (d *Debugger) GroupGoroutines(gs []*proc.G, group *api.GoroutineGroupingOpt
 	}
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	return d.groupGoroutines(gs, group)
+}
 
+func (d *Debugger) groupGoroutines(gs []*proc.G, group *api.GoroutineGroupingOptions) ([]*proc.G, []api.GoroutineGroup, bool) {
 	groupMembers := map[string][]*proc.G{}
 	totals := map[string]int{}
 
@@ -1773,6 +1856,261 @@ func (d *Debugger) stacktrace(goroutineID int64, depth int, opts api.StacktraceO
 	}
 }
 
+// GoroutinePanics returns the panics in progress on the specified
+// goroutine, oldest first.
+func (d *Debugger) GoroutinePanics(goroutineID int64) ([]proc.Panic, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	g, err := proc.FindGoroutine(d.target.Selected, goroutineID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errors.New("no goroutine selected")
+	}
+	return proc.GoroutinePanics(d.target.Selected, g)
+}
+
+// DataRace returns the data race report that caused the selected target to
+// stop, or nil if it did not stop because of a data race.
+func (d *Debugger) DataRace() *proc.DataRace {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.Selected.DataRace()
+}
+
+// GoroutineStacks returns all goroutines of the selected target along with
+// their stacktraces, up to the specified depth.
+// Goroutines that can not be read are omitted, goroutines whose
+// stacktrace can not be read are returned with a nil stacktrace.
+func (d *Debugger) GoroutineStacks(depth int, opts api.StacktraceOptions) ([]*proc.G, [][]api.Stackframe, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, nil, err
+	}
+
+	gs, _, err := proc.GoroutinesInfo(d.target.Selected, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rgs := make([]*proc.G, 0, len(gs))
+	stacks := make([][]api.Stackframe, 0, len(gs))
+	for _, g := range gs {
+		if g.Unreadable != nil {
+			continue
+		}
+		var stack []api.Stackframe
+		rawlocs, err := proc.GoroutineStacktrace(d.target.Selected, g, depth, proc.StacktraceOptions(opts))
+		if err == nil {
+			stack, _ = d.convertStacktrace(rawlocs, nil)
+		}
+		rgs = append(rgs, g)
+		stacks = append(stacks, stack)
+	}
+	return rgs, stacks, nil
+}
+
+// Deadlocks returns the goroutines that are blocked forever.
+func (d *Debugger) Deadlocks() (*proc.DeadlockReport, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.Deadlocks(d.target.Selected)
+}
+
+// SchedulerInfo returns the state of the Go scheduler of the selected
+// target.
+func (d *Debugger) SchedulerInfo() (*proc.SchedInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.SchedulerInfo(d.target.Selected)
+}
+
+// FileDescriptors returns the file descriptors of the selected target.
+func (d *Debugger) FileDescriptors() ([]proc.FileDescriptor, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.FileDescriptors(d.target.Selected)
+}
+
+// Timers returns the pending timers of the Go runtime of the selected
+// target.
+func (d *Debugger) Timers() (*proc.TimersInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.Timers(d.target.Selected)
+}
+
+// MethodSet returns the method set of typename in the selected target.
+func (d *Debugger) MethodSet(typename string) (godwarf.Type, []proc.Method, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.MethodSet(d.target.Selected, typename)
+}
+
+// Implements returns the types of the selected target that implement the
+// interface typename.
+func (d *Debugger) Implements(typename string) ([]proc.Implementation, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.Implements(d.target.Selected, typename)
+}
+
+// crashReportGroupMembers is the maximum number of goroutine IDs listed
+// for each group of a crash report.
+const crashReportGroupMembers = 10
+
+// CrashReport returns a summary of the state of the target, meant to be
+// used on core files of crashed processes: the cause of the crash, the
+// stacktrace of the crashing goroutine, up to depth frames, all
+// goroutines grouped by user location, and information on how the
+// executable was built.
+func (d *Debugger) CrashReport(depth int) (*api.CrashReport, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	tgt := d.target.Selected
+	c, err := proc.FindCrash(tgt)
+	if err != nil {
+		return nil, err
+	}
+	r := &api.CrashReport{
+		Kind:      c.Kind.String(),
+		Message:   c.Message,
+		Signal:    api.ConvertSignalInfo(c.Signal),
+		GoSignal:  api.ConvertSignalInfo(c.GoSignal),
+		GoVersion: tgt.BinInfo().Producer(),
+	}
+	for _, v := range c.Panics {
+		r.Panics = append(r.Panics, panicValueString(api.ConvertVar(v)))
+	}
+	if len(r.Panics) > 0 {
+		r.Message = r.Panics[len(r.Panics)-1]
+	}
+
+	if c.G != nil {
+		r.Goroutine = api.ConvertGoroutine(tgt, c.G)
+		frames, err := proc.GoroutineStacktrace(tgt, c.G, depth, 0)
+		if err == nil {
+			r.Stacktrace, err = d.convertStacktrace(frames, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gs, _, err := proc.GoroutinesInfo(tgt, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	gs, groups, _ := d.groupGoroutines(gs, &api.GoroutineGroupingOptions{GroupBy: api.GoroutineUserLoc, MaxGroupMembers: crashReportGroupMembers})
+	for _, group := range groups {
+		rg := api.CrashReportGroup{Location: group.Name, Count: group.Total}
+		for _, g := range gs[group.Offset : group.Offset+group.Count] {
+			rg.Goroutines = append(rg.Goroutines, g.ID)
+		}
+		r.GoroutineGroups = append(r.GoroutineGroups, rg)
+	}
+	sort.SliceStable(r.GoroutineGroups, func(i, j int) bool {
+		return r.GoroutineGroups[i].Count > r.GoroutineGroups[j].Count
+	})
+
+	for _, pkg := range tgt.BinInfo().ListPackagesBuildInfo(false) {
+		r.BuildInfo = append(r.BuildInfo, api.PackageBuildInfo{ImportPath: pkg.ImportPath, DirectoryPath: pkg.DirectoryPath})
+	}
+	sort.Slice(r.BuildInfo, func(i, j int) bool {
+		return r.BuildInfo[i].ImportPath < r.BuildInfo[j].ImportPath
+	})
+	return r, nil
+}
+
+// panicValueString formats the value passed to panic, strings are
+// returned unquoted like the Go runtime prints them.
+func panicValueString(v *api.Variable) string {
+	if v.Kind == reflect.Interface && len(v.Children) == 1 {
+		if v.Children[0].Kind == reflect.String {
+			return v.Children[0].Value
+		}
+		return v.Children[0].SinglelineString()
+	}
+	return v.SinglelineString()
+}
+
+// HeapHistogram returns the number of allocated heap objects and bytes
+// per type, only types matching filter are returned.
+func (d *Debugger) HeapHistogram(filter string) ([]proc.HeapTypeStats, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	regex, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+	}
+
+	stats, err := proc.HeapHistogram(d.target.Selected)
+	if err != nil {
+		return nil, err
+	}
+	r := stats[:0]
+	for _, st := range stats {
+		if regex.MatchString(st.Type) {
+			r = append(r, st)
+		}
+	}
+	return r, nil
+}
+
+// HeapReferences returns the chains of references leading to the memory
+// expr refers to, in the given scope.
+func (d *Debugger) HeapReferences(goid int64, frame, deferredCall int, expr string, maxChains int) (*proc.HeapReferences, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target.Selected, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	return s.HeapReferences(expr, maxChains)
+}
+
+// RetainedSize returns the size of the value expr evaluates to and of the
+// heap objects reachable from it, see proc.RetainedSizeOf.
+func (d *Debugger) RetainedSize(goid int64, frame, deferredCall int, expr string, maxDepth, maxObjects int) (*proc.RetainedSize, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target.Selected, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalExpression(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return proc.RetainedSizeOf(d.target.Selected, v, maxDepth, maxObjects)
+}
+
+// VariableRetainedSize returns the size of v and of the heap objects
+// reachable from it, see proc.RetainedSizeOf.
+func (d *Debugger) VariableRetainedSize(v *proc.Variable, maxDepth, maxObjects int) (*proc.RetainedSize, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.RetainedSizeOf(d.target.Selected, v, maxDepth, maxObjects)
+}
+
 // Ancestors returns the stacktraces for the ancestors of a goroutine.
 func (d *Debugger) Ancestors(goroutineID int64, numAncestors, depth int) ([]api.Ancestor, error) {
 	d.targetMutex.Lock()
@@ -2093,6 +2431,57 @@ func (d *Debugger) ExamineMemory(address uint64, length int) ([]byte, error) {
 	return data, nil
 }
 
+// ValueMemory returns the address and size of the memory storing the value
+// of expr, see proc.(*EvalScope).ValueMemory.
+func (d *Debugger) ValueMemory(goid int64, frame, deferredCall int, expr string) (uint64, uint64, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target.Selected, goid, frame, deferredCall)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.ValueMemory(expr)
+}
+
+// WriteMemory writes data to the memory of the target at address.
+func (d *Debugger) WriteMemory(address uint64, data []byte) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return d.target.Selected.WriteMemory(address, data)
+}
+
+// DescribeAddress returns a description of what addr points to, see
+// proc.DescribeAddress.
+func (d *Debugger) DescribeAddress(addr uint64) (*api.AddrInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	info, err := proc.DescribeAddress(d.target.Selected, addr)
+	if err != nil {
+		return nil, err
+	}
+	return api.ConvertAddrInfo(d.target.Selected, info), nil
+}
+
+// MemoryMappings returns the memory map of the target, see
+// proc.MemoryMappings.
+func (d *Debugger) MemoryMappings() ([]proc.MemoryMapping, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.MemoryMappings(d.target.Selected)
+}
+
+// FindMemory searches memory for pattern, see proc.FindMemory.
+func (d *Debugger) FindMemory(start, end uint64, pattern []byte, maxMatches int) ([]proc.MemoryMatch, bool, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return proc.FindMemory(d.target.Selected, start, end, pattern, maxMatches)
+}
+
 func (d *Debugger) GetVersion(out *api.GetVersionOut) error {
 	if d.config.CoreFile != "" {
 		if d.config.Backend == "rr" {
@@ -2156,8 +2545,9 @@ func (d *Debugger) LockTargetGroup() (*proc.TargetGroup, func()) {
 	return d.target, d.targetMutex.Unlock
 }
 
-// DumpStart starts a core dump to dest.
-func (d *Debugger) DumpStart(dest string) error {
+// DumpStart starts a core dump to dest. If dest ends in ".gz" the core
+// dump is compressed with gzip.
+func (d *Debugger) DumpStart(dest string, opts proc.DumpOptions) error {
 	d.targetMutex.Lock()
 	// targetMutex will only be unlocked when the dump is done
 
@@ -2181,6 +2571,15 @@ func (d *Debugger) DumpStart(dest string) error {
 		d.targetMutex.Unlock()
 		return err
 	}
+	var out elfwriter.WriteCloserSeeker = fh
+	if strings.HasSuffix(dest, ".gz") {
+		out, err = elfwriter.NewGzipWriter(fh)
+		if err != nil {
+			fh.Close()
+			d.targetMutex.Unlock()
+			return err
+		}
+	}
 
 	d.dumpState.Dumping = true
 	d.dumpState.AllDone = false
@@ -2193,7 +2592,7 @@ func (d *Debugger) DumpStart(dest string) error {
 	d.dumpState.Err = nil
 	go func() {
 		defer d.targetMutex.Unlock()
-		d.target.Selected.Dump(fh, 0, &d.dumpState)
+		d.target.Selected.Dump(out, opts, &d.dumpState)
 	}()
 
 	return nil
@@ -2346,6 +2745,13 @@ func noDebugErrorWarning(err error) error {
 	return err
 }
 
+// sharedLibraryWarning prints a warning about a shared library of a core
+// file that could not be loaded, without it the frames of C code in that
+// library can not be unwound.
+func sharedLibraryWarning(msg string) {
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+}
+
 func verifyBinaryFormat(exePath string) (string, error) {
 	fullpath, err := filepath.Abs(exePath)
 	if err != nil {
@@ -2432,6 +2838,8 @@ func (d *Debugger) maybePrintUnattendedStopWarning(stopReason proc.StopReason, c
 	switch bp.Name {
 	case proc.FatalThrow, proc.UnrecoveredPanic:
 		fmt.Fprintln(os.Stderr, "\n** execution is paused because your program is panicking **")
+	case proc.DataRaceBreakpoint:
+		fmt.Fprintln(os.Stderr, "\n** execution is paused because the race detector found a data race **")
 	default:
 		fmt.Fprintln(os.Stderr, "\n** execution is paused because a breakpoint is hit **")
 	}
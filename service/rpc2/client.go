@@ -367,6 +367,18 @@ func (c *RPCClient) EvalVariable(scope api.EvalScope, expr string, cfg api.LoadC
 	return out.Variable, err
 }
 
+func (c *RPCClient) ChanState(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.ChanState, error) {
+	var out ChanStateOut
+	err := c.call("ChanState", ChanStateIn{scope, expr, &cfg}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) ContextChain(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]api.ContextLayer, error) {
+	var out ContextChainOut
+	err := c.call("ContextChain", ContextChainIn{scope, expr, &cfg}, &out)
+	return out.Layers, err
+}
+
 func (c *RPCClient) SetVariable(scope api.EvalScope, symbol, value string) error {
 	out := new(SetOut)
 	return c.call("Set", SetIn{scope, symbol, value}, out)
@@ -441,12 +453,84 @@ func (c *RPCClient) ListGoroutinesWithFilter(start, count int, filters []api.Lis
 	return out.Goroutines, out.Groups, out.Nextg, out.TooManyGroups, err
 }
 
+func (c *RPCClient) Panics(goroutineID int64) ([]api.Panic, error) {
+	var out PanicsOut
+	err := c.call("Panics", PanicsIn{goroutineID}, &out)
+	return out.Panics, err
+}
+
 func (c *RPCClient) Stacktrace(goroutineId int64, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	var out StacktraceOut
 	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg}, &out)
 	return out.Locations, err
 }
 
+func (c *RPCClient) ListGoroutineStacks(depth int, opts api.StacktraceOptions) ([]api.GoroutineStack, error) {
+	var out ListGoroutineStacksOut
+	err := c.call("ListGoroutineStacks", ListGoroutineStacksIn{depth, opts}, &out)
+	return out.Goroutines, err
+}
+
+func (c *RPCClient) GoroutineProfile(depth int) ([]byte, error) {
+	var out GoroutineProfileOut
+	err := c.call("GoroutineProfile", GoroutineProfileIn{depth}, &out)
+	return out.Profile, err
+}
+
+func (c *RPCClient) SchedulerInfo() (*api.SchedInfo, error) {
+	var out SchedulerInfoOut
+	err := c.call("SchedulerInfo", SchedulerInfoIn{}, &out)
+	return &out.Sched, err
+}
+
+func (c *RPCClient) FileDescriptors() ([]api.FileDescriptor, error) {
+	var out FileDescriptorsOut
+	err := c.call("FileDescriptors", FileDescriptorsIn{}, &out)
+	return out.FileDescriptors, err
+}
+
+func (c *RPCClient) Timers() (*api.Timers, error) {
+	var out TimersOut
+	err := c.call("Timers", TimersIn{}, &out)
+	return &out.Timers, err
+}
+
+func (c *RPCClient) MethodSet(typename string) (*api.MethodSet, error) {
+	var out MethodSetOut
+	err := c.call("MethodSet", MethodSetIn{typename}, &out)
+	return &out.MethodSet, err
+}
+
+func (c *RPCClient) Implements(typename string) ([]api.Implementation, error) {
+	var out ImplementsOut
+	err := c.call("Implements", ImplementsIn{typename}, &out)
+	return out.Implementations, err
+}
+
+func (c *RPCClient) Deadlocks() (*api.Deadlocks, error) {
+	var out DeadlocksOut
+	err := c.call("Deadlocks", DeadlocksIn{}, &out)
+	return &out.Deadlocks, err
+}
+
+func (c *RPCClient) HeapHistogram(filter string) ([]api.HeapTypeStats, error) {
+	var out HeapHistogramOut
+	err := c.call("HeapHistogram", HeapHistogramIn{filter}, &out)
+	return out.Types, err
+}
+
+func (c *RPCClient) HeapReferences(scope api.EvalScope, expr string, maxChains int) (*api.HeapReferences, error) {
+	var out HeapReferencesOut
+	err := c.call("HeapReferences", HeapReferencesIn{scope, expr, maxChains}, &out)
+	return &out.References, err
+}
+
+func (c *RPCClient) RetainedSize(scope api.EvalScope, expr string, maxDepth, maxObjects int) (*api.RetainedSize, error) {
+	var out RetainedSizeOut
+	err := c.call("RetainedSize", RetainedSizeIn{scope, expr, maxDepth, maxObjects}, &out)
+	return &out.Size, err
+}
+
 func (c *RPCClient) Ancestors(goroutineID int64, numAncestors int, depth int) ([]api.Ancestor, error) {
 	var out AncestorsOut
 	err := c.call("Ancestors", AncestorsIn{goroutineID, numAncestors, depth}, &out)
@@ -558,6 +642,34 @@ func (c *RPCClient) ExamineMemory(address uint64, count int) ([]byte, bool, erro
 	return out.Mem, out.IsLittleEndian, nil
 }
 
+func (c *RPCClient) ValueMemory(scope api.EvalScope, expr string) (uint64, uint64, error) {
+	var out ValueMemoryOut
+	err := c.call("ValueMemory", ValueMemoryIn{scope, expr}, &out)
+	return out.Address, out.Size, err
+}
+
+func (c *RPCClient) WriteMemory(address uint64, data []byte) error {
+	return c.call("WriteMemory", WriteMemoryIn{address, data}, &WriteMemoryOut{})
+}
+
+func (c *RPCClient) DescribeAddress(addr uint64) (*api.AddrInfo, error) {
+	var out DescribeAddressOut
+	err := c.call("DescribeAddress", DescribeAddressIn{addr}, &out)
+	return &out.Info, err
+}
+
+func (c *RPCClient) MemoryMap() ([]api.MemoryMapping, error) {
+	var out MemoryMapOut
+	err := c.call("MemoryMap", MemoryMapIn{}, &out)
+	return out.Mappings, err
+}
+
+func (c *RPCClient) FindMemory(start, end uint64, pattern []byte, maxMatches int) ([]api.MemoryMatch, bool, error) {
+	var out FindMemoryOut
+	err := c.call("FindMemory", FindMemoryIn{start, end, pattern, maxMatches}, &out)
+	return out.Matches, out.Truncated, err
+}
+
 func (c *RPCClient) StopRecording() error {
 	return c.call("StopRecording", StopRecordingIn{}, &StopRecordingOut{})
 }
@@ -568,6 +680,12 @@ func (c *RPCClient) CoreDumpStart(dest string) (api.DumpState, error) {
 	return out.State, err
 }
 
+func (c *RPCClient) CoreDumpStartWithOptions(dest string, opts api.DumpOptions) (api.DumpState, error) {
+	out := &DumpStartOut{}
+	err := c.call("DumpStart", DumpStartIn{Destination: dest, Options: opts}, out)
+	return out.State, err
+}
+
 func (c *RPCClient) CoreDumpWait(msec int) api.DumpState {
 	out := &DumpWaitOut{}
 	_ = c.call("DumpWait", DumpWaitIn{Wait: msec}, out)
@@ -1,6 +1,7 @@
 package rpc2
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"regexp"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/pprof"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
@@ -187,6 +189,26 @@ func (s *RPCServer) GetBreakpoint(arg GetBreakpointIn, out *GetBreakpointOut) er
 	return nil
 }
 
+type PanicsIn struct {
+	Id int64
+}
+
+type PanicsOut struct {
+	Panics []api.Panic
+}
+
+// Panics returns the panics in progress on goroutine Id, oldest first,
+// including panics that have been recovered by a deferred call that is
+// still running.
+func (s *RPCServer) Panics(arg PanicsIn, out *PanicsOut) error {
+	panics, err := s.debugger.GoroutinePanics(arg.Id)
+	if err != nil {
+		return err
+	}
+	out.Panics = api.ConvertPanics(panics)
+	return nil
+}
+
 type StacktraceIn struct {
 	Id     int64
 	Depth  int
@@ -220,6 +242,281 @@ func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 	return err
 }
 
+type ListGoroutineStacksIn struct {
+	Depth int
+	Opts  api.StacktraceOptions
+}
+
+type ListGoroutineStacksOut struct {
+	Goroutines []api.GoroutineStack
+}
+
+// ListGoroutineStacks returns all goroutines along with their stacktraces
+// up to the specified Depth.
+// Local variables and function arguments are never loaded.
+func (s *RPCServer) ListGoroutineStacks(arg ListGoroutineStacksIn, out *ListGoroutineStacksOut) error {
+	gs, stacks, err := s.debugger.GoroutineStacks(arg.Depth, arg.Opts)
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	out.Goroutines = make([]api.GoroutineStack, len(gs))
+	for i := range gs {
+		out.Goroutines[i] = api.GoroutineStack{Goroutine: *api.ConvertGoroutine(tgrp.Selected, gs[i]), Stack: stacks[i]}
+	}
+	return nil
+}
+
+type GoroutineProfileIn struct {
+	Depth int
+}
+
+type GoroutineProfileOut struct {
+	// Profile is a gzip compressed profile.proto message, as read by 'go
+	// tool pprof'.
+	Profile []byte
+}
+
+// GoroutineProfile returns a pprof goroutine profile of the current state
+// of the target, stacktraces are collected up to the specified Depth (if
+// Depth is 0 a default depth is used).
+// Goroutines with identical stacktraces and labels are counted together,
+// the state of each goroutine, or its wait reason, is recorded in the
+// "goroutine.state" label.
+func (s *RPCServer) GoroutineProfile(arg GoroutineProfileIn, out *GoroutineProfileOut) error {
+	const defaultDepth = 128
+	if arg.Depth <= 0 {
+		arg.Depth = defaultDepth
+	}
+	gs, stacks, err := s.debugger.GoroutineStacks(arg.Depth, 0)
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	prof := pprof.New(pprof.ValueType{Type: "goroutine", Unit: "count"}, 1, pprof.ValueType{Type: "goroutine", Unit: "count"})
+	for i := range gs {
+		g := api.ConvertGoroutine(tgrp.Selected, gs[i])
+		prof.Add(api.PprofFrames(stacks[i]), []int64{1}, g.PprofLabels())
+	}
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		return err
+	}
+	out.Profile = buf.Bytes()
+	return nil
+}
+
+type DeadlocksIn struct {
+}
+
+type DeadlocksOut struct {
+	Deadlocks api.Deadlocks
+}
+
+// Deadlocks builds a wait-for graph of the goroutines blocked on channel
+// operations, select statements and semaphores (sync.Mutex,
+// sync.WaitGroup, etc) and returns the goroutines that can never be woken
+// up, along with the cycles of goroutines waiting on each other.
+func (s *RPCServer) Deadlocks(arg DeadlocksIn, out *DeadlocksOut) error {
+	r, err := s.debugger.Deadlocks()
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	out.Deadlocks = *api.ConvertDeadlocks(tgrp.Selected, r)
+	return nil
+}
+
+type SchedulerInfoIn struct {
+}
+
+type SchedulerInfoOut struct {
+	Sched api.SchedInfo
+}
+
+// SchedulerInfo returns the state of the Go scheduler, read from the
+// runtime: the list of Ps with their local run queues, the list of Ms, the
+// length of the global run queue, the GC phase and GOMAXPROCS.
+func (s *RPCServer) SchedulerInfo(arg SchedulerInfoIn, out *SchedulerInfoOut) error {
+	si, err := s.debugger.SchedulerInfo()
+	if err != nil {
+		return err
+	}
+	out.Sched = *api.ConvertSchedInfo(si)
+	return nil
+}
+
+type FileDescriptorsIn struct {
+}
+
+type FileDescriptorsOut struct {
+	FileDescriptors []api.FileDescriptor
+}
+
+// FileDescriptors returns the file descriptors of the target.
+// For live processes on linux the descriptors are read from /proc,
+// otherwise only the descriptors held by os.File, net.Conn and similar
+// variables can be found. Each descriptor is associated to the goroutines
+// parked in the netpoller waiting for it and to the variables that hold
+// it.
+func (s *RPCServer) FileDescriptors(arg FileDescriptorsIn, out *FileDescriptorsOut) error {
+	fds, err := s.debugger.FileDescriptors()
+	if err != nil {
+		return err
+	}
+	out.FileDescriptors = api.ConvertFileDescriptors(fds)
+	return nil
+}
+
+type TimersIn struct {
+}
+
+type TimersOut struct {
+	Timers api.Timers
+}
+
+// Timers returns the pending timers of the Go runtime, read from the
+// timer heap of each P, sorted by the time at which they fire.
+// Timers of time.Timer and time.Ticker are only listed while a goroutine
+// is blocked receiving from their channel.
+func (s *RPCServer) Timers(arg TimersIn, out *TimersOut) error {
+	ti, err := s.debugger.Timers()
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	out.Timers = *api.ConvertTimers(tgrp.Selected.BinInfo(), ti)
+	return nil
+}
+
+type MethodSetIn struct {
+	Type string
+}
+
+type MethodSetOut struct {
+	MethodSet api.MethodSet
+}
+
+// MethodSet returns the method set of a type. For types other than
+// interfaces Type can be either T or *T, the methods with a pointer
+// receiver are returned in both cases but are marked as such.
+func (s *RPCServer) MethodSet(arg MethodSetIn, out *MethodSetOut) error {
+	typ, methods, err := s.debugger.MethodSet(arg.Type)
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	out.MethodSet = *api.ConvertMethodSet(tgrp.Selected.BinInfo(), typ, methods)
+	return nil
+}
+
+type ImplementsIn struct {
+	Type string
+}
+
+type ImplementsOut struct {
+	Implementations []api.Implementation
+}
+
+// Implements returns the types implementing the interface Type: the types
+// for which the runtime has an itab for the interface and the named types
+// whose methods, found in debug info, have the names of all the methods of
+// the interface.
+func (s *RPCServer) Implements(arg ImplementsIn, out *ImplementsOut) error {
+	impls, err := s.debugger.Implements(arg.Type)
+	if err != nil {
+		return err
+	}
+	out.Implementations = api.ConvertImplementations(impls)
+	return nil
+}
+
+type HeapHistogramIn struct {
+	Filter string
+}
+
+type HeapHistogramOut struct {
+	Types []api.HeapTypeStats
+}
+
+// HeapHistogram walks the heap of the target and returns the number of
+// allocated objects and the number of bytes they occupy for each type
+// matching the regular expression Filter, sorted by decreasing size.
+//
+// The type of objects that are not reachable from a typed pointer and do
+// not have a malloc header is unknown, these objects are grouped by size.
+func (s *RPCServer) HeapHistogram(arg HeapHistogramIn, out *HeapHistogramOut) error {
+	stats, err := s.debugger.HeapHistogram(arg.Filter)
+	if err != nil {
+		return err
+	}
+	out.Types = api.ConvertHeapTypeStats(stats)
+	return nil
+}
+
+type HeapReferencesIn struct {
+	Scope     api.EvalScope
+	Expr      string
+	MaxChains int
+}
+
+type HeapReferencesOut struct {
+	References api.HeapReferences
+}
+
+// HeapReferences searches package variables, local variables and heap
+// objects for pointers to the memory arg.Expr refers to and returns, for
+// each of them, the chain of references leading to it from a variable.
+//
+// If arg.Expr evaluates to a pointer, slice, string, map or channel the
+// memory it points to is searched, if it is an integer constant it is
+// interpreted as an address, otherwise references to the memory storing
+// the value of the expression are searched. When the address is inside a
+// heap object references to any part of the object are searched.
+//
+// At most arg.MaxChains chains are returned, 0 means no limit.
+func (s *RPCServer) HeapReferences(arg HeapReferencesIn, out *HeapReferencesOut) error {
+	refs, err := s.debugger.HeapReferences(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.MaxChains)
+	if err != nil {
+		return err
+	}
+	out.References = *api.ConvertHeapReferences(refs)
+	return nil
+}
+
+type RetainedSizeIn struct {
+	Scope      api.EvalScope
+	Expr       string
+	MaxDepth   int
+	MaxObjects int
+}
+
+type RetainedSizeOut struct {
+	Size api.RetainedSize
+}
+
+// RetainedSize returns the size of the value arg.Expr evaluates to and the
+// total size of the heap objects reachable from it through pointers,
+// slices, strings, maps, channels and interfaces, broken down by type.
+// Objects reachable through multiple paths are counted once.
+//
+// Pointers are followed at most arg.MaxDepth times and at most
+// arg.MaxObjects objects are visited, 0 disables the corresponding limit.
+// If arg.MaxDepth is negative only the shallow size is returned.
+func (s *RPCServer) RetainedSize(arg RetainedSizeIn, out *RetainedSizeOut) error {
+	r, err := s.debugger.RetainedSize(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.MaxDepth, arg.MaxObjects)
+	if err != nil {
+		return err
+	}
+	out.Size = *api.ConvertRetainedSize(r)
+	return nil
+}
+
 type AncestorsIn struct {
 	GoroutineID  int64
 	NumAncestors int
@@ -555,6 +852,63 @@ func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	return nil
 }
 
+type ChanStateIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Cfg   *api.LoadConfig
+}
+
+type ChanStateOut struct {
+	State api.ChanState
+}
+
+// ChanState returns the state of the channel arg.Expr evaluates to: its
+// buffered elements in the order in which they will be received, whether
+// it is closed and the goroutines waiting to send to or receive from it,
+// along with the values being sent and the other cases of the select
+// statements they are blocked in.
+func (s *RPCServer) ChanState(arg ChanStateIn, out *ChanStateOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	}
+	cs, err := s.debugger.ChanState(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	tgrp, unlock := s.debugger.LockTargetGroup()
+	defer unlock()
+	out.State = *api.ConvertChanState(tgrp.Selected, cs)
+	return nil
+}
+
+type ContextChainIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Cfg   *api.LoadConfig
+}
+
+type ContextChainOut struct {
+	Layers []api.ContextLayer
+}
+
+// ContextChain returns the chain of parents of the context.Context
+// arg.Expr evaluates to, starting with the context itself. For each
+// context the kind, deadline, cancellation state and cause, and key/value
+// pair are returned.
+func (s *RPCServer) ContextChain(arg ContextChainIn, out *ContextChainOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	}
+	layers, err := s.debugger.ContextChain(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Layers = api.ConvertContextChain(layers)
+	return nil
+}
+
 type SetIn struct {
 	Scope  api.EvalScope
 	Symbol string
@@ -1001,6 +1355,112 @@ func (s *RPCServer) ExamineMemory(arg ExamineMemoryIn, out *ExaminedMemoryOut) e
 	return nil
 }
 
+type ValueMemoryIn struct {
+	Scope api.EvalScope
+	Expr  string
+}
+
+type ValueMemoryOut struct {
+	Address uint64
+	Size    uint64
+}
+
+// ValueMemory returns the address and size of the memory storing the value
+// of arg.Expr: the backing array of slices, the contents of strings, the
+// value pointed to by pointers and the memory storing the value itself for
+// all other types. The memory can then be read with ExamineMemory.
+func (s *RPCServer) ValueMemory(arg ValueMemoryIn, out *ValueMemoryOut) error {
+	var err error
+	out.Address, out.Size, err = s.debugger.ValueMemory(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr)
+	return err
+}
+
+type WriteMemoryIn struct {
+	Address uint64
+	Data    []byte
+}
+
+type WriteMemoryOut struct {
+}
+
+// WriteMemory writes arg.Data to the memory of the target process at
+// arg.Address. Writing the memory of core files and recordings is not
+// supported.
+func (s *RPCServer) WriteMemory(arg WriteMemoryIn, out *WriteMemoryOut) error {
+	return s.debugger.WriteMemory(arg.Address, arg.Data)
+}
+
+type DescribeAddressIn struct {
+	Addr uint64
+}
+
+type DescribeAddressOut struct {
+	Info api.AddrInfo
+}
+
+// DescribeAddress returns a description of what arg.Addr points to: the
+// function, offset and source line for text addresses, the package
+// variable for data addresses, the heap span, object and type for heap
+// addresses and the goroutine and stack frame for stack addresses.
+func (s *RPCServer) DescribeAddress(arg DescribeAddressIn, out *DescribeAddressOut) error {
+	info, err := s.debugger.DescribeAddress(arg.Addr)
+	if err != nil {
+		return err
+	}
+	out.Info = *info
+	return nil
+}
+
+type MemoryMapIn struct {
+}
+
+type MemoryMapOut struct {
+	Mappings []api.MemoryMapping
+}
+
+// MemoryMap returns the memory mappings of the target process, with their
+// address range, permissions, mapped file and offset. Each mapping is
+// classified as Go heap arena, goroutine stack, text or data of a Go
+// module, or shared library. Goroutine stacks are returned as separate
+// mappings, splitting the mapping that contains them.
+func (s *RPCServer) MemoryMap(arg MemoryMapIn, out *MemoryMapOut) error {
+	maps, err := s.debugger.MemoryMappings()
+	if err != nil {
+		return err
+	}
+	out.Mappings = api.ConvertMemoryMappings(maps)
+	return nil
+}
+
+type FindMemoryIn struct {
+	Start, End uint64
+	Pattern    []byte
+	MaxMatches int
+}
+
+type FindMemoryOut struct {
+	Matches   []api.MemoryMatch
+	Truncated bool
+}
+
+// FindMemory searches the memory between arg.Start and arg.End for
+// arg.Pattern. If arg.Start and arg.End are both 0 all the readable
+// mappings of the target process are searched.
+//
+// Each match is annotated with the memory mapping, the function or package
+// variable and the heap object containing it, when known.
+// At most arg.MaxMatches matches are returned, out.Truncated is set if more
+// exist. If arg.MaxMatches is 0 all matches are returned.
+func (s *RPCServer) FindMemory(arg FindMemoryIn, out *FindMemoryOut) error {
+	matches, truncated, err := s.debugger.FindMemory(arg.Start, arg.End, arg.Pattern, arg.MaxMatches)
+	if err != nil {
+		return err
+	}
+	out.Matches = api.ConvertMemoryMatches(matches)
+	out.Truncated = truncated
+	return nil
+}
+
 type StopRecordingIn struct {
 }
 
@@ -1020,15 +1480,18 @@ func (s *RPCServer) StopRecording(arg StopRecordingIn, cb service.RPCCallback) {
 
 type DumpStartIn struct {
 	Destination string
+	Options     api.DumpOptions
 }
 
 type DumpStartOut struct {
 	State api.DumpState
 }
 
-// DumpStart starts a core dump to arg.Destination.
+// DumpStart starts a core dump to arg.Destination, if arg.Destination ends
+// in ".gz" the core dump is compressed with gzip. Arg.Options selects the
+// memory written to the core dump.
 func (s *RPCServer) DumpStart(arg DumpStartIn, out *DumpStartOut) error {
-	err := s.debugger.DumpStart(arg.Destination)
+	err := s.debugger.DumpStart(arg.Destination, api.ConvertDumpOptions(arg.Options))
 	if err != nil {
 		return err
 	}
@@ -14,14 +14,18 @@ func suitableMethods2(s *rpc2.RPCServer, methods map[string]*methodType) {
 	methods["RPCServer.BuildID"] = &methodType{method: reflect.ValueOf(s.BuildID)}
 	methods["RPCServer.CancelDownloads"] = &methodType{method: reflect.ValueOf(s.CancelDownloads)}
 	methods["RPCServer.CancelNext"] = &methodType{method: reflect.ValueOf(s.CancelNext)}
+	methods["RPCServer.ChanState"] = &methodType{method: reflect.ValueOf(s.ChanState)}
 	methods["RPCServer.Checkpoint"] = &methodType{method: reflect.ValueOf(s.Checkpoint)}
 	methods["RPCServer.ClearBreakpoint"] = &methodType{method: reflect.ValueOf(s.ClearBreakpoint)}
 	methods["RPCServer.ClearCheckpoint"] = &methodType{method: reflect.ValueOf(s.ClearCheckpoint)}
 	methods["RPCServer.Command"] = &methodType{method: reflect.ValueOf(s.Command)}
+	methods["RPCServer.ContextChain"] = &methodType{method: reflect.ValueOf(s.ContextChain)}
 	methods["RPCServer.CreateBreakpoint"] = &methodType{method: reflect.ValueOf(s.CreateBreakpoint)}
 	methods["RPCServer.CreateEBPFTracepoint"] = &methodType{method: reflect.ValueOf(s.CreateEBPFTracepoint)}
 	methods["RPCServer.CreateWatchpoint"] = &methodType{method: reflect.ValueOf(s.CreateWatchpoint)}
+	methods["RPCServer.Deadlocks"] = &methodType{method: reflect.ValueOf(s.Deadlocks)}
 	methods["RPCServer.DebugInfoDirectories"] = &methodType{method: reflect.ValueOf(s.DebugInfoDirectories)}
+	methods["RPCServer.DescribeAddress"] = &methodType{method: reflect.ValueOf(s.DescribeAddress)}
 	methods["RPCServer.Detach"] = &methodType{method: reflect.ValueOf(s.Detach)}
 	methods["RPCServer.Disassemble"] = &methodType{method: reflect.ValueOf(s.Disassemble)}
 	methods["RPCServer.DownloadLibraryDebugInfo"] = &methodType{method: reflect.ValueOf(s.DownloadLibraryDebugInfo)}
@@ -30,7 +34,9 @@ func suitableMethods2(s *rpc2.RPCServer, methods map[string]*methodType) {
 	methods["RPCServer.DumpWait"] = &methodType{method: reflect.ValueOf(s.DumpWait)}
 	methods["RPCServer.Eval"] = &methodType{method: reflect.ValueOf(s.Eval)}
 	methods["RPCServer.ExamineMemory"] = &methodType{method: reflect.ValueOf(s.ExamineMemory)}
+	methods["RPCServer.FileDescriptors"] = &methodType{method: reflect.ValueOf(s.FileDescriptors)}
 	methods["RPCServer.FindLocation"] = &methodType{method: reflect.ValueOf(s.FindLocation)}
+	methods["RPCServer.FindMemory"] = &methodType{method: reflect.ValueOf(s.FindMemory)}
 	methods["RPCServer.FollowExec"] = &methodType{method: reflect.ValueOf(s.FollowExec)}
 	methods["RPCServer.FollowExecEnabled"] = &methodType{method: reflect.ValueOf(s.FollowExecEnabled)}
 	methods["RPCServer.FunctionReturnLocations"] = &methodType{method: reflect.ValueOf(s.FunctionReturnLocations)}
@@ -38,7 +44,11 @@ func suitableMethods2(s *rpc2.RPCServer, methods map[string]*methodType) {
 	methods["RPCServer.GetBufferedTracepoints"] = &methodType{method: reflect.ValueOf(s.GetBufferedTracepoints)}
 	methods["RPCServer.GetEvents"] = &methodType{method: reflect.ValueOf(s.GetEvents)}
 	methods["RPCServer.GetThread"] = &methodType{method: reflect.ValueOf(s.GetThread)}
+	methods["RPCServer.GoroutineProfile"] = &methodType{method: reflect.ValueOf(s.GoroutineProfile)}
 	methods["RPCServer.GuessSubstitutePath"] = &methodType{method: reflect.ValueOf(s.GuessSubstitutePath)}
+	methods["RPCServer.HeapHistogram"] = &methodType{method: reflect.ValueOf(s.HeapHistogram)}
+	methods["RPCServer.HeapReferences"] = &methodType{method: reflect.ValueOf(s.HeapReferences)}
+	methods["RPCServer.Implements"] = &methodType{method: reflect.ValueOf(s.Implements)}
 	methods["RPCServer.IsMulticlient"] = &methodType{method: reflect.ValueOf(s.IsMulticlient)}
 	methods["RPCServer.LastModified"] = &methodType{method: reflect.ValueOf(s.LastModified)}
 	methods["RPCServer.ListBreakpoints"] = &methodType{method: reflect.ValueOf(s.ListBreakpoints)}
@@ -46,6 +56,7 @@ func suitableMethods2(s *rpc2.RPCServer, methods map[string]*methodType) {
 	methods["RPCServer.ListDynamicLibraries"] = &methodType{method: reflect.ValueOf(s.ListDynamicLibraries)}
 	methods["RPCServer.ListFunctionArgs"] = &methodType{method: reflect.ValueOf(s.ListFunctionArgs)}
 	methods["RPCServer.ListFunctions"] = &methodType{method: reflect.ValueOf(s.ListFunctions)}
+	methods["RPCServer.ListGoroutineStacks"] = &methodType{method: reflect.ValueOf(s.ListGoroutineStacks)}
 	methods["RPCServer.ListGoroutines"] = &methodType{method: reflect.ValueOf(s.ListGoroutines)}
 	methods["RPCServer.ListLocalVars"] = &methodType{method: reflect.ValueOf(s.ListLocalVars)}
 	methods["RPCServer.ListPackageVars"] = &methodType{method: reflect.ValueOf(s.ListPackageVars)}
@@ -55,14 +66,22 @@ func suitableMethods2(s *rpc2.RPCServer, methods map[string]*methodType) {
 	methods["RPCServer.ListTargets"] = &methodType{method: reflect.ValueOf(s.ListTargets)}
 	methods["RPCServer.ListThreads"] = &methodType{method: reflect.ValueOf(s.ListThreads)}
 	methods["RPCServer.ListTypes"] = &methodType{method: reflect.ValueOf(s.ListTypes)}
+	methods["RPCServer.MemoryMap"] = &methodType{method: reflect.ValueOf(s.MemoryMap)}
+	methods["RPCServer.MethodSet"] = &methodType{method: reflect.ValueOf(s.MethodSet)}
+	methods["RPCServer.Panics"] = &methodType{method: reflect.ValueOf(s.Panics)}
 	methods["RPCServer.ProcessPid"] = &methodType{method: reflect.ValueOf(s.ProcessPid)}
 	methods["RPCServer.Recorded"] = &methodType{method: reflect.ValueOf(s.Recorded)}
 	methods["RPCServer.Restart"] = &methodType{method: reflect.ValueOf(s.Restart)}
+	methods["RPCServer.RetainedSize"] = &methodType{method: reflect.ValueOf(s.RetainedSize)}
+	methods["RPCServer.SchedulerInfo"] = &methodType{method: reflect.ValueOf(s.SchedulerInfo)}
 	methods["RPCServer.Set"] = &methodType{method: reflect.ValueOf(s.Set)}
 	methods["RPCServer.Stacktrace"] = &methodType{method: reflect.ValueOf(s.Stacktrace)}
 	methods["RPCServer.State"] = &methodType{method: reflect.ValueOf(s.State)}
 	methods["RPCServer.StopRecording"] = &methodType{method: reflect.ValueOf(s.StopRecording)}
+	methods["RPCServer.Timers"] = &methodType{method: reflect.ValueOf(s.Timers)}
 	methods["RPCServer.ToggleBreakpoint"] = &methodType{method: reflect.ValueOf(s.ToggleBreakpoint)}
+	methods["RPCServer.ValueMemory"] = &methodType{method: reflect.ValueOf(s.ValueMemory)}
+	methods["RPCServer.WriteMemory"] = &methodType{method: reflect.ValueOf(s.WriteMemory)}
 }
 
 func suitableMethodsCommon(s *RPCServer, methods map[string]*methodType) {